@@ -0,0 +1,239 @@
+package Certificates
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/*
+Локальный удостоверяющий центр (CA) для разработки.
+
+Цепочка доверия в TLS:
+	CA — самоподписанный сертификат с IsCA=true, его добавляют в пул доверенных (RootCAs у клиента, ClientCAs у сервера);
+	leaf (конечный) сертификат — подписан ключом CA. У серверного в SAN (Subject Alternative Name) перечислены имена и IP,
+		по которым к нему обращаются: современные клиенты смотрят только на SAN, CommonName для проверки имени игнорируется.
+	ExtKeyUsage ограничивает назначение: ServerAuth — для сервера, ClientAuth — для клиента при взаимном TLS (mTLS).
+
+Ключи генерируются на кривой P-256 (ECDSA): они короче RSA и быстрее при рукопожатии.
+Файлы хранятся в PEM: сертификат — блок "CERTIFICATE", ключ — "PRIVATE KEY" (PKCS#8).
+*/
+
+const (
+	caCertFile = "ca.pem"
+	caKeyFile  = "ca-key.pem"
+)
+
+type Authority struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+
+	CertPEM []byte
+}
+
+type Usage int
+
+const (
+	ServerUsage Usage = iota
+	ClientUsage
+)
+
+// LeafRequest описывает выпускаемый конечный сертификат
+type LeafRequest struct {
+	CommonName string
+	Hosts      []string // DNS-имена и IP-адреса для SAN
+	Usage      Usage
+	Validity   time.Duration
+}
+
+// CreateCA создаёт новый самоподписанный CA
+func CreateCA(commonName string, validity time.Duration) (*Authority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{"learning local CA"}},
+		NotBefore:             now.Add(-time.Hour), // небольшой запас на расхождение часов
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true, // CA может подписывать только конечные сертификаты
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Authority{
+		Cert:    cert,
+		Key:     key,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}, nil
+}
+
+// Issue выпускает конечный сертификат, подписанный CA, и возвращает сертификат и ключ в PEM
+func (ca *Authority) Issue(req LeafRequest) (certPEM, keyPEM []byte, err error) {
+	if req.CommonName == "" {
+		return nil, nil, errors.New("не указан CommonName")
+	}
+	if req.Validity == 0 {
+		req.Validity = 90 * 24 * time.Hour
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: req.CommonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(req.Validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	switch req.Usage {
+	case ServerUsage:
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	case ClientUsage:
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+
+	hosts := req.Hosts
+	if len(hosts) == 0 && req.Usage == ServerUsage {
+		hosts = []string{req.CommonName}
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM, nil
+}
+
+// CertPool возвращает пул с сертификатом CA — его передают в RootCAs/ClientCAs
+func (ca *Authority) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+
+	return pool
+}
+
+// Save записывает CA в каталог dir (ключ — с правами 0600)
+func (ca *Authority) Save(dir string) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(ca.Key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, caCertFile), ca.CertPEM, 0o644); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, caKeyFile), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600)
+}
+
+// LoadCA читает CA, ранее сохранённый через Save
+func LoadCA(dir string) (*Authority, error) {
+	certPEM, err := os.ReadFile(filepath.Join(dir, caCertFile))
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(dir, caKeyFile))
+	if err != nil {
+		return nil, err
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, fmt.Errorf("%s: нет PEM-блока", caCertFile)
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, fmt.Errorf("%s: нет PEM-блока", caKeyFile)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("ключ CA не поддерживает подпись")
+	}
+
+	return &Authority{Cert: cert, Key: signer, CertPEM: certPEM}, nil
+}
+
+// WritePair сохраняет сертификат и ключ рядом: name.pem и name-key.pem
+func WritePair(dir, name string, certPEM, keyPEM []byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".pem"), certPEM, 0o644); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, name+"-key.pem"), keyPEM, 0o600)
+}
+
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
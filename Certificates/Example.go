@@ -0,0 +1,95 @@
+package Certificates
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/rpc"
+	"path/filepath"
+	"time"
+
+	"learning/HTTP"
+)
+
+// Arith — тот же сервис, что и в примерах net_rpc
+type Arith int
+
+type Args struct {
+	A, B int
+}
+
+func (t *Arith) Multiply(args *Args, reply *int) error {
+	*reply = args.A * args.B
+	return nil
+}
+
+// ExampleMutualTLS выпускает CA и сертификаты в каталог dir, затем поднимает API пользователей и rpc-сервер с обязательным клиентским сертификатом
+func ExampleMutualTLS(dir string) {
+	ca, err := CreateCA("Learning Local CA", 365*24*time.Hour)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ca.Save(dir); err != nil {
+		log.Fatal(err)
+	}
+
+	serverCert, serverKey, err := ca.Issue(LeafRequest{CommonName: "localhost", Hosts: []string{"localhost", "127.0.0.1"}, Usage: ServerUsage})
+	if err != nil {
+		log.Fatal(err)
+	}
+	clientCert, clientKey, err := ca.Issue(LeafRequest{CommonName: "alice", Usage: ClientUsage})
+	if err != nil {
+		log.Fatal(err)
+	}
+	WritePair(dir, "server", serverCert, serverKey)
+	WritePair(dir, "client", clientCert, clientKey)
+
+	serverReloader, err := NewReloader(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	go serverReloader.Watch(context.Background(), 30*time.Second, func(err error) { log.Println("reload:", err) })
+
+	serverTLS := ServerConfig(serverReloader, ca.CertPool())
+
+	// HTTPS с mTLS
+	go func() {
+		log.Println(ListenAndServeTLS(":8443", HTTP.UsersMux(), serverTLS))
+	}()
+
+	// net/rpc с mTLS
+	rpcServer := rpc.NewServer()
+	rpcServer.Register(new(Arith))
+	go func() {
+		log.Println(ServeRPC(":1235", rpcServer, serverTLS))
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	clientReloader, err := NewReloader(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	clientTLS := ClientConfig(ca.CertPool(), clientReloader)
+
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+	resp, err := httpClient.Get("https://localhost:8443/api/v1/users")
+	if err != nil {
+		log.Fatal(err)
+	}
+	resp.Body.Close()
+	fmt.Println("HTTPS:", resp.Status)
+
+	rpcClient, err := DialRPC("localhost:1235", clientTLS)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rpcClient.Close()
+
+	var reply int
+	if err := rpcClient.Call("Arith.Multiply", &Args{A: 6, B: 7}, &reply); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("RPC: 6*7 =", reply)
+}
@@ -0,0 +1,108 @@
+package Certificates
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/rpc"
+	"os"
+	"time"
+)
+
+/*
+Взаимный TLS (mTLS): не только клиент проверяет сервер, но и сервер требует от клиента сертификат,
+подписанный доверенным CA (tls.RequireAndVerifyClientCert + ClientCAs).
+Имя клиента после рукопожатия доступно в r.TLS.PeerCertificates[0].Subject.CommonName.
+
+Одна и та же tls.Config подходит и для http.Server, и для net/rpc — rpc.Server умеет работать с любым net.Listener,
+поэтому достаточно обернуть слушатель через tls.NewListener.
+*/
+
+// LoadPool читает PEM-файл с сертификатами CA в пул
+func LoadPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New(caFile + ": не найдено ни одного сертификата")
+	}
+
+	return pool, nil
+}
+
+// ServerConfig — конфигурация сервера; если clientCAs не nil, клиентский сертификат обязателен
+func ServerConfig(reloader *Reloader, clientCAs *x509.CertPool) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if clientCAs != nil {
+		cfg.ClientCAs = clientCAs
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg
+}
+
+// ClientConfig — конфигурация клиента; reloader с клиентским сертификатом может быть nil, если mTLS не нужен
+func ClientConfig(rootCAs *x509.CertPool, reloader *Reloader) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    rootCAs,
+	}
+
+	if reloader != nil {
+		cfg.GetClientCertificate = reloader.GetClientCertificate
+	}
+
+	return cfg
+}
+
+// ListenAndServeTLS запускает HTTPS-сервер с готовой tls.Config: файлы cert/key не нужны, их отдаёт GetCertificate
+func ListenAndServeTLS(addr string, handler http.Handler, cfg *tls.Config) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		TLSConfig:         cfg,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return server.ListenAndServeTLS("", "")
+}
+
+// ServeRPC принимает TLS-соединения и обслуживает их rpc-сервером
+func ServeRPC(addr string, server *rpc.Server, cfg *tls.Config) error {
+	l, err := tls.Listen("tcp", addr, cfg)
+	if err != nil {
+		return err
+	}
+
+	server.Accept(l) // блокируется, пока слушатель не закроется
+
+	return nil
+}
+
+// DialRPC подключается к rpc-серверу по TLS
+func DialRPC(addr string, cfg *tls.Config) (*rpc.Client, error) {
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}, "tcp", addr, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return rpc.NewClient(conn), nil
+}
+
+// PeerName возвращает CommonName клиентского сертификата или пустую строку
+func PeerName(state *tls.ConnectionState) string {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return ""
+	}
+
+	return state.PeerCertificates[0].Subject.CommonName
+}
@@ -0,0 +1,103 @@
+package Certificates
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+Горячая перезагрузка сертификатов.
+
+tls.Config.GetCertificate вызывается на каждом рукопожатии, поэтому достаточно подменить сертификат в памяти —
+новые соединения получат новый сертификат без перезапуска сервера, а уже открытые доработают со старым.
+Reloader хранит текущую пару и раз в interval сравнивает время изменения файлов.
+*/
+
+type Reloader struct {
+	certFile, keyFile string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload перечитывает пару с диска; при ошибке остаётся прежний сертификат
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = r.latestModTime()
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *Reloader) latestModTime() time.Time {
+	var latest time.Time
+
+	for _, name := range []string{r.certFile, r.keyFile} {
+		if info, err := os.Stat(name); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest
+}
+
+// GetCertificate подходит для tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// GetClientCertificate подходит для tls.Config.GetClientCertificate — клиентский сертификат тоже можно обновлять на лету
+func (r *Reloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// Watch проверяет файлы раз в interval, пока не отменён контекст. onError получает ошибки перезагрузки (может быть nil)
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.RLock()
+		changed := r.latestModTime().After(r.modTime)
+		r.mu.RUnlock()
+
+		if !changed {
+			continue
+		}
+
+		if err := r.Reload(); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package Config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+/*
+App — конфигурация программ этого репозитория: адреса серверов и подключение к Postgres.
+
+Переменные окружения БД те же, что задаёт DataBase/docker-compose.yml (DB_HOST, DB_PORT, ...).
+Файл по умолчанию берётся из APP_CONFIG, пример — Config/config.example.json.
+*/
+
+type App struct {
+	HTTP     HTTPConfig     `json:"http"`
+	Database DatabaseConfig `json:"database"`
+}
+
+type HTTPConfig struct {
+	Addr      string `json:"addr" default:":8383" env:"HTTP_ADDR" flag:"http-addr" usage:"адрес API и примеров серверов" validate:"required"`
+	ProxyAddr string `json:"proxy_addr" default:":8080" env:"PROXY_ADDR" flag:"proxy-addr" usage:"адрес балансировщика" validate:"required"`
+	Secure    bool   `json:"secure" env:"HTTP_SECURE" flag:"http-secure" usage:"cookie только по HTTPS"`
+	CSRFKey   string `json:"csrf_key" env:"CSRF_KEY" usage:"ключ подписи CSRF-токенов" secret:"true"`
+}
+
+type DatabaseConfig struct {
+	Host     string `json:"host" default:"postgres" env:"DB_HOST" flag:"db-host" usage:"хост Postgres" validate:"required"`
+	Port     int    `json:"port" default:"5432" env:"DB_PORT" flag:"db-port" usage:"порт Postgres" validate:"min=1,max=65535"`
+	Name     string `json:"name" default:"go_learning_db" env:"DB_NAME" flag:"db-name" usage:"имя базы" validate:"required"`
+	User     string `json:"user" default:"go_user" env:"DB_USER" flag:"db-user" usage:"пользователь" validate:"required"`
+	Password string `json:"password" default:"go_password" env:"DB_PASSWORD" usage:"пароль (только env или файл, чтобы не светить в ps)" secret:"true"`
+	SSLMode  string `json:"sslmode" default:"disable" env:"DB_SSLMODE" flag:"db-sslmode" usage:"sslmode" validate:"oneof=disable|require|verify-ca|verify-full"`
+}
+
+// DSN — строка подключения для lib/pq в формате key=value
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		quote(c.Host), c.Port, quote(c.User), quote(c.Password), quote(c.Name), quote(c.SSLMode))
+}
+
+// String не показывает пароль, поэтому конфигурацию можно смело логировать
+func (c DatabaseConfig) String() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, mask),
+		Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		Path:     c.Name,
+		RawQuery: "sslmode=" + c.SSLMode,
+	}
+
+	return u.Redacted()
+}
+
+func (a *App) String() string {
+	return Dump(a)
+}
+
+func (a *App) Validate() error {
+	if a.HTTP.Addr == a.HTTP.ProxyAddr {
+		return errors.New("http.addr и http.proxy_addr совпадают")
+	}
+	if a.HTTP.CSRFKey != "" && len(a.HTTP.CSRFKey) < 32 {
+		return errors.New("http.csrf_key: нужно не меньше 32 байт")
+	}
+
+	return nil
+}
+
+// quote экранирует значение для DSN lib/pq: пробелы, кавычки и обратные слэши
+func quote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " '\\") {
+		return s
+	}
+
+	out := []byte{'\''}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' || s[i] == '\\' {
+			out = append(out, '\\')
+		}
+		out = append(out, s[i])
+	}
+
+	return string(append(out, '\''))
+}
+
+var (
+	appOnce  sync.Once
+	appStore *Store[App]
+)
+
+// Current — конфигурация по умолчанию: default + файл из APP_CONFIG + окружение, без флагов.
+// Ей пользуются примеры серверов, чтобы не держать адреса и пароли в коде
+func Current() *App {
+	appOnce.Do(func() {
+		if appStore != nil {
+			return
+		}
+
+		store, err := NewStore[App](Options{File: os.Getenv("APP_CONFIG")})
+		if err != nil {
+			log.Fatal(err)
+		}
+		appStore = store
+	})
+
+	return appStore.Get()
+}
+
+// Init загружает конфигурацию с флагами командной строки и делает её текущей; вызывать в начале main
+func Init(args []string) (*Store[App], error) {
+	store, err := NewStore[App](Options{File: os.Getenv("APP_CONFIG"), Args: args})
+	if err != nil {
+		return nil, err
+	}
+
+	appOnce.Do(func() {})
+	appStore = store
+
+	return store, nil
+}
@@ -0,0 +1,51 @@
+package Config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const mask = "******"
+
+// Dump выводит конфигурацию построчно "путь = значение"; поля с secret:"true" маскируются
+func Dump(cfg any) string {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Sprint(cfg)
+	}
+
+	var sb strings.Builder
+	for _, f := range collect(rv, "") {
+		fmt.Fprintf(&sb, "%s = %s\n", f.path, display(f))
+	}
+
+	return sb.String()
+}
+
+func display(f field) string {
+	if f.tag.Get("secret") == "true" {
+		return Mask(fmt.Sprint(f.value.Interface()))
+	}
+
+	if f.value.Kind() == reflect.Slice {
+		return fmt.Sprint(f.value.Interface())
+	}
+	if f.value.Kind() == reflect.String {
+		return fmt.Sprintf("%q", f.value.String())
+	}
+
+	return fmt.Sprint(f.value.Interface())
+}
+
+// Mask скрывает секрет; по пустой строке видно, что секрет не задан
+func Mask(secret string) string {
+	if secret == "" {
+		return `""`
+	}
+
+	return mask
+}
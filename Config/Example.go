@@ -0,0 +1,34 @@
+package Config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ExampleConfig: go run . -config Config/config.example.json -db-host localhost
+// и затем kill -HUP <pid> после правки файла
+func ExampleConfig() {
+	store, err := Init(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cfg := store.Get()
+	fmt.Print(cfg) // пароль и ключ CSRF замаскированы
+	fmt.Println("db:", cfg.Database)
+
+	store.OnReload(func(old, new *App) {
+		if old.Database != new.Database {
+			fmt.Println("подключение к БД изменилось:", new.Database)
+		}
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store.WatchSIGHUP(ctx)
+}
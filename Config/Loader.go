@@ -0,0 +1,432 @@
+package Config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Типизированная конфигурация: значения берутся из тегов структуры и нескольких источников.
+
+Приоритет (каждый следующий перекрывает предыдущий):
+	1. default:"..."  — значение по умолчанию;
+	2. файл JSON      — ключи по тегу json, вложенные структуры — вложенные объекты;
+	3. env:"DB_HOST"  — переменная окружения;
+	4. flag:"db-host" — флаг командной строки.
+
+В файле и в default можно ссылаться на переменные окружения: "${CSRF_KEY}" раскрывается через os.ExpandEnv
+(как в ExpandEnvExample из packages/os), так что секреты не обязательно хранить в файле. Раскрываются уже разобранные
+строковые значения, а не текст файла: кавычка или \ в переменной не сломает JSON и не добавит ключей.
+Сам символ $ в значении пишется как $$: "pa$$word" -> pa$word (иначе $word раскрылся бы в пустую строку).
+Незаданная переменная раскрывается в пустую строку, и эта пустая строка перекрывает default. Поэтому поле с default и тегом env
+(как пароль БД с env:"DB_PASSWORD") в файл со ссылкой на ту же переменную не пишется: env и так его задаст.
+
+Остальные теги:
+	usage:"..."          — описание для -help;
+	secret:"true"        — значение маскируется в Dump и String;
+	validate:"required,min=1,max=65535,oneof=disable|require" — проверки после загрузки.
+Если структура реализует Validator, после тегов вызывается и её Validate().
+
+Поддерживаемые типы полей: string, bool, int*, uint*, float*, time.Duration, []string (через запятую), вложенные структуры.
+*/
+
+type Options struct {
+	File string   // путь к JSON-файлу; пусто — без файла (но его можно задать флагом -config)
+	Args []string // аргументы для флагов, обычно os.Args[1:]; nil — флаги не разбираются
+
+	Name   string    // имя набора флагов для -help
+	Output io.Writer // куда писать -help, по умолчанию os.Stderr
+}
+
+type Validator interface {
+	Validate() error
+}
+
+// field — конечное поле структуры с путём вида "db.host"
+type field struct {
+	path  string
+	value reflect.Value
+	tag   reflect.StructTag
+}
+
+// Load заполняет dst (указатель на структуру) из всех источников по порядку и проверяет результат
+func Load(dst any, opts Options) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: нужен указатель на структуру, получен %T", dst)
+	}
+
+	fields := collect(rv.Elem(), "")
+
+	// Флаги разбираются первыми (в том числе -config), но применяются последними
+	flagValues, file, err := parseFlags(fields, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if def, ok := f.tag.Lookup("default"); ok {
+			if err := setValue(f.value, expandEnv(def)); err != nil {
+				return fmt.Errorf("config: %s: default %q: %w", f.path, def, err)
+			}
+		}
+	}
+
+	if file != "" {
+		if err := applyFile(fields, file); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range fields {
+		name := f.tag.Get("env")
+		if name == "" {
+			continue
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			if err := setValue(f.value, v); err != nil {
+				return fmt.Errorf("config: %s: переменная %s=%q: %w", f.path, name, v, err)
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if v, ok := flagValues[f.path]; ok {
+			if err := setValue(f.value, v); err != nil {
+				return fmt.Errorf("config: %s: флаг -%s=%q: %w", f.path, f.tag.Get("flag"), v, err)
+			}
+		}
+	}
+
+	return validate(dst, fields)
+}
+
+func collect(v reflect.Value, prefix string) []field {
+	var out []field
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := jsonName(sf)
+		if name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		fv := v.Field(i)
+		if sf.Type.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Time{}) {
+			out = append(out, collect(fv, name)...)
+			continue
+		}
+
+		out = append(out, field{path: name, value: fv, tag: sf.Tag})
+	}
+
+	return out
+}
+
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		return strings.ToLower(sf.Name)
+	}
+
+	return name
+}
+
+func parseFlags(fields []field, opts Options) (map[string]string, string, error) {
+	values := make(map[string]string)
+	file := opts.File
+
+	if opts.Args == nil {
+		return values, file, nil
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = os.Args[0]
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	if opts.Output != nil {
+		fs.SetOutput(opts.Output)
+	}
+
+	fs.StringVar(&file, "config", file, "путь к JSON-файлу конфигурации")
+
+	for _, f := range fields {
+		flagName := f.tag.Get("flag")
+		if flagName == "" {
+			continue
+		}
+
+		usage := f.tag.Get("usage")
+		if env := f.tag.Get("env"); env != "" {
+			usage += " (env " + env + ")"
+		}
+		if def, ok := f.tag.Lookup("default"); ok && f.tag.Get("secret") != "true" {
+			usage += " (по умолчанию " + def + ")"
+		}
+
+		path := f.path
+		if f.value.Kind() == reflect.Bool {
+			fs.BoolFunc(flagName, usage, func(v string) error { values[path] = v; return nil })
+		} else {
+			fs.Func(flagName, usage, func(v string) error { values[path] = v; return nil })
+		}
+	}
+
+	if err := fs.Parse(opts.Args); err != nil {
+		return nil, "", err
+	}
+
+	return values, file, nil
+}
+
+func applyFile(fields []field, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	flat := make(map[string]any)
+	flatten(doc, "", flat)
+
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.path] = true
+
+		raw, ok := flat[f.path]
+		if !ok {
+			continue
+		}
+		if err := setValue(f.value, fileString(raw)); err != nil {
+			return fmt.Errorf("config: %s: %s: %w", path, f.path, err)
+		}
+	}
+
+	// Опечатка в ключе иначе молча оставила бы значение по умолчанию
+	var unknown []string
+	for key := range flat {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("config: %s: неизвестные ключи %s", path, strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+func flatten(doc map[string]any, prefix string, out map[string]any) {
+	for k, v := range doc {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		if nested, ok := v.(map[string]any); ok {
+			flatten(nested, key, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// fileString приводит значение из JSON к строке, которую понимает setValue; ссылки на переменные в строках раскрываются
+func fileString(v any) string {
+	switch x := v.(type) {
+	case string:
+		return expandEnv(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case []any:
+		parts := make([]string, len(x))
+		for i := range x {
+			parts[i] = fileString(x[i])
+		}
+		return strings.Join(parts, ",")
+	case nil:
+		return ""
+	}
+
+	return fmt.Sprint(v)
+}
+
+// expandEnv — os.ExpandEnv, в котором $$ означает сам символ $
+func expandEnv(s string) string {
+	return os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		return os.Getenv(name)
+	})
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("тип %s не поддерживается", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+
+	default:
+		return fmt.Errorf("тип %s не поддерживается", v.Type())
+	}
+
+	return nil
+}
+
+func validate(dst any, fields []field) error {
+	var errs []error
+
+	for _, f := range fields {
+		rules := f.tag.Get("validate")
+		if rules == "" {
+			continue
+		}
+
+		for _, rule := range strings.Split(rules, ",") {
+			if err := checkRule(f.value, rule); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.path, err))
+			}
+		}
+	}
+
+	if v, ok := dst.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config: некорректная конфигурация:\n%w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func checkRule(v reflect.Value, rule string) error {
+	name, arg, _ := strings.Cut(rule, "=")
+
+	switch name {
+	case "required":
+		if v.IsZero() {
+			return errors.New("обязательное значение не задано")
+		}
+
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Errorf("правило %s: %w", rule, err)
+		}
+
+		n, ok := number(v)
+		if !ok {
+			return fmt.Errorf("правило %s неприменимо к %s", rule, v.Type())
+		}
+		if name == "min" && n < limit {
+			return fmt.Errorf("%v меньше %s", v.Interface(), arg)
+		}
+		if name == "max" && n > limit {
+			return fmt.Errorf("%v больше %s", v.Interface(), arg)
+		}
+
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, allowed := range strings.Split(arg, "|") {
+			if s == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q не из списка %s", s, arg)
+
+	default:
+		return fmt.Errorf("неизвестное правило %q", rule)
+	}
+
+	return nil
+}
+
+// number — числовое значение поля; для строк и срезов — длина
+func number(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String, reflect.Slice:
+		return float64(v.Len()), true
+	}
+
+	return 0, false
+}
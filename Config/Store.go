@@ -0,0 +1,90 @@
+package Config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+/*
+Store хранит текущую конфигурацию и умеет перечитывать её без перезапуска процесса.
+
+	kill -HUP <pid>  — перечитать файл и переменные окружения (флаги остаются те же, что при запуске).
+
+Новая конфигурация подменяет старую атомарно и только если прошла проверку;
+при ошибке продолжает работать прежняя, а ошибка пишется в лог — опечатка в файле не роняет сервер.
+Get() всегда возвращает целую согласованную версию: читатели не увидят наполовину обновлённую структуру.
+*/
+
+type Store[T any] struct {
+	opts    Options
+	current atomic.Pointer[T]
+
+	mu        sync.Mutex
+	listeners []func(old, new *T)
+}
+
+func NewStore[T any](opts Options) (*Store[T], error) {
+	s := &Store[T]{opts: opts}
+
+	cfg := new(T)
+	if err := Load(cfg, opts); err != nil {
+		return nil, err
+	}
+	s.current.Store(cfg)
+
+	return s, nil
+}
+
+func (s *Store[T]) Get() *T {
+	return s.current.Load()
+}
+
+// OnReload регистрирует функцию, которая вызывается после успешной перезагрузки
+func (s *Store[T]) OnReload(fn func(old, new *T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, fn)
+}
+
+func (s *Store[T]) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg := new(T)
+	if err := Load(cfg, s.opts); err != nil {
+		return err
+	}
+
+	old := s.current.Swap(cfg)
+	for _, fn := range s.listeners {
+		fn(old, cfg)
+	}
+
+	return nil
+}
+
+// WatchSIGHUP перечитывает конфигурацию по SIGHUP, пока не отменён ctx
+func (s *Store[T]) WatchSIGHUP(ctx context.Context) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			if err := s.Reload(); err != nil {
+				log.Println("config: перезагрузка отклонена, остаётся прежняя конфигурация:", err)
+				continue
+			}
+			log.Println("config: конфигурация перезагружена")
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package Backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Migrations"
+)
+
+/*
+Выгрузка и восстановление базы заказов на уровне приложения — архив tar.gz, который читается без Postgres.
+
+	f, _ := os.Create("orders.tar.gz")
+	manifest, err := Backup.Export(ctx, db, f, Backup.ExportOptions{Driver: "postgres", Format: Backup.CSV})
+
+	f, _ := os.Open("orders.tar.gz")
+	result, err := Backup.Import(ctx, db, f, Backup.ImportOptions{Driver: "sqlite", Tables: []string{"orders", "order_items"}})
+
+Зачем, если есть pg_dump: архив не зависит от СУБД (выгрузили из Postgres — загрузили в SQLite для тестов),
+файлы внутри — JSONL или CSV, их можно открыть в Excel, отфильтровать jq или отдать аналитикам; можно выгрузить
+только часть таблиц или только изменения с даты (Since). Чего нет: схемы, индексов, прав — схему создают миграции,
+а версия схемы записана в манифесте, и Import откажется загружать архив в базу с другой версией.
+
+Внутри архива:
+	manifest.json       — первым файлом: формат, версия схемы, таблицы, колонки и их типы, число строк, SHA-256 каждого файла;
+	users.jsonl, ...    — по файлу на таблицу, в порядке Tables: сначала те, на которые ссылаются.
+
+Значения по типам колонок (Kind): числа — числами, время — RFC 3339 в UTC, булевы — true/false, JSON — как есть.
+NULL в JSONL — null, в CSV — \N (как в COPY у Postgres): пустая строка и NULL в CSV иначе неразличимы.
+Чтобы текст \N не превратился в NULL, обратная косая черта в значениях CSV удваивается: \N в файле — NULL, \\N — текст \N.
+
+Экспорт идёт в одной транзакции — в Postgres REPEATABLE READ, так что все таблицы — снимок одного момента,
+и order_items не сошлётся на заказ, который появился после выгрузки orders. Таблица сначала пишется во временный файл:
+размер файла в заголовке tar и контрольная сумма в манифесте известны только после последней строки, а держать
+таблицу в памяти нельзя.
+*/
+
+// Table — таблица в архиве; Since — колонка времени, по которой выбираются изменения с даты
+type Table struct {
+	Name  string
+	Since string
+}
+
+// Tables — все таблицы базы заказов в порядке зависимостей: таблица идёт после тех, на которые ссылается.
+// schema_migrations в архив не входит — её ведут миграции, в манифесте только номер версии
+var Tables = []Table{
+	{Name: "users", Since: "created_at"},
+	{Name: "categories", Since: "created_at"},
+	{Name: "products", Since: "updated_at"},
+	{Name: "orders", Since: "updated_at"},
+	{Name: "order_items", Since: "created_at"},
+	{Name: "order_status_history", Since: "changed_at"},
+	{Name: "outbox", Since: "created_at"},
+}
+
+type Format string
+
+const (
+	JSONL Format = "jsonl"
+	CSV   Format = "csv"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case JSONL, CSV:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("backup: формат %q — ожидается jsonl или csv", s)
+}
+
+// ManifestVersion — версия формата архива; Import не читает архивы другой версии
+const ManifestVersion = 1
+
+const manifestFile = "manifest.json"
+
+var (
+	ErrManifest      = errors.New("backup: архив повреждён или не того формата")
+	ErrChecksum      = errors.New("backup: контрольная сумма не совпала")
+	ErrSchemaVersion = errors.New("backup: версия схемы архива не совпадает с базой")
+)
+
+type Manifest struct {
+	Version       int         `json:"version"`
+	SchemaVersion int64       `json:"schema_version"` // последняя применённая миграция в базе, из которой выгружали
+	Driver        string      `json:"driver"`
+	Format        Format      `json:"format"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Since         *time.Time  `json:"since,omitempty"` // выгружены только строки, изменённые с этого момента
+	Tables        []TableFile `json:"tables"`
+}
+
+type TableFile struct {
+	Name    string   `json:"name"`
+	File    string   `json:"file"`
+	Columns []Column `json:"columns"`
+	Rows    int64    `json:"rows"`
+	SHA256  string   `json:"sha256"`
+}
+
+type Column struct {
+	Name string `json:"name"`
+	Kind Kind   `json:"kind"`
+}
+
+func (m *Manifest) table(file string) *TableFile {
+	for i := range m.Tables {
+		if m.Tables[i].File == file {
+			return &m.Tables[i]
+		}
+	}
+
+	return nil
+}
+
+// selectTables — таблицы из Tables с именами names в порядке зависимостей; nil — все
+func selectTables(names []string) ([]Table, error) {
+	if len(names) == 0 {
+		return Tables, nil
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(Tables, func(t Table) bool { return t.Name == name }) {
+			return nil, fmt.Errorf("backup: неизвестная таблица %q", name)
+		}
+	}
+
+	var out []Table
+	for _, t := range Tables {
+		if slices.Contains(names, t.Name) {
+			out = append(out, t)
+		}
+	}
+
+	return out, nil
+}
+
+// schemaVersion — последняя применённая миграция
+func schemaVersion(ctx context.Context, db *sql.DB, d Dialect.Dialect) (int64, error) {
+	m, err := Migrations.New(db, d)
+	if err != nil {
+		return 0, err
+	}
+
+	return m.Version(ctx)
+}
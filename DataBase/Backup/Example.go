@@ -0,0 +1,106 @@
+package Backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"learning/DataBase/Fake"
+)
+
+/*
+С настоящей базой — через dbctl:
+
+	go run ./tools/dbctl export orders.tar.gz                          — все таблицы, JSONL
+	go run ./tools/dbctl export -format csv -since 2024-05-01 delta.tar.gz
+	go run ./tools/dbctl import -clean orders.tar.gz                   — заменить данные архивом
+	go run ./tools/dbctl import -tables products delta.tar.gz          — догрузить изменения одной таблицы
+*/
+
+// expectSchema — Migrator.Version: в базе применена миграция 8
+func expectSchema(mock *Fake.Mock) {
+	mock.ExpectExec(`^CREATE TABLE IF NOT EXISTS schema_migrations`)
+	mock.ExpectQuery(`^SELECT version, name, checksum, applied_at FROM schema_migrations$`).
+		WillReturnRows(Fake.NewRows("version", "name", "checksum", "applied_at").
+			Add(int64(8), "create_report_indexes", "", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+// ExampleExport — пользователи и категории в CSV; типы колонок, как их называет lib/pq
+func ExampleExport() {
+	db, mock := Fake.New(nil)
+	defer db.Close()
+
+	expectSchema(mock)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT \* FROM users ORDER BY id$`).
+		WillReturnRows(Fake.NewRows("id", "email", "name", "created_at").Types("INT4", "VARCHAR", "VARCHAR", "TIMESTAMP").
+			Add(int64(1), "anna@example.com", "Анна", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)).
+			Add(int64(2), "boris@example.com", nil, time.Date(2024, 5, 2, 11, 30, 0, 0, time.UTC)))
+	mock.ExpectQuery(`^SELECT \* FROM categories ORDER BY id$`).
+		WillReturnRows(Fake.NewRows("id", "name", "created_at").Types("INT4", "VARCHAR", "TIMESTAMP").
+			Add(int64(1), "Смартфоны", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)))
+	mock.ExpectCommit()
+
+	var archive bytes.Buffer
+	manifest, err := Export(context.Background(), db, &archive, ExportOptions{
+		Format: CSV,
+		Tables: []string{"categories", "users"}, // в архиве всё равно users первой — порядок Tables
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("схема", manifest.SchemaVersion, "формат", manifest.Format)
+	for _, tf := range manifest.Tables {
+		fmt.Println(tf.File, tf.Rows, "строк", tf.SHA256[:12])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// ExampleImport — выгрузка из одной базы и загрузка в другую: upsert с явными id, затем setval
+func ExampleImport() {
+	src, srcMock := Fake.New(nil)
+	defer src.Close()
+
+	expectSchema(srcMock)
+	srcMock.ExpectBegin()
+	srcMock.ExpectQuery(`^SELECT \* FROM products ORDER BY id$`).
+		WillReturnRows(Fake.NewRows("id", "name", "price", "is_active", "updated_at").Types("INT4", "VARCHAR", "NUMERIC", "BOOL", "TIMESTAMP").
+			Add(int64(1), "iPhone 15", []byte("999.99"), true, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)).
+			Add(int64(7), "Galaxy S24", []byte("899.00"), false, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)))
+	srcMock.ExpectCommit()
+
+	var archive bytes.Buffer
+	if _, err := Export(context.Background(), src, &archive, ExportOptions{Tables: []string{"products"}}); err != nil {
+		log.Fatal(err)
+	}
+
+	dst, mock := Fake.New(nil)
+	defer dst.Close()
+
+	expectSchema(mock)
+	mock.ExpectBegin()
+	upsert := `^INSERT INTO products \(id, name, price, is_active, updated_at\) VALUES \(\$1, \$2, \$3, \$4, \$5\) ` +
+		`ON CONFLICT \(id\) DO UPDATE SET name = excluded.name, price = excluded.price, is_active = excluded.is_active, updated_at = excluded.updated_at$`
+	mock.ExpectExec(upsert).WithArgs(int64(1), "iPhone 15", "999.99", true, Fake.AnyArg())
+	mock.ExpectExec(upsert).WithArgs(int64(7), "Galaxy S24", "899.00", false, Fake.AnyArg())
+	mock.ExpectExec(`^SELECT setval\(pg_get_serial_sequence\('products', 'id'\), \(SELECT MAX\(id\) FROM products\)\)$`)
+	mock.ExpectCommit()
+
+	result, err := Import(context.Background(), dst, &archive, ImportOptions{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, l := range result.Loaded {
+		fmt.Printf("%s: загружено %d, пропущено %d\n", l.Table, l.Rows, l.Skipped)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		log.Fatal(err)
+	}
+}
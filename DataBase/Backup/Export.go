@@ -0,0 +1,306 @@
+package Backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Query"
+	"learning/DataBase/Transactions"
+)
+
+type ExportOptions struct {
+	Driver string    // "postgres" (по умолчанию) или "sqlite"
+	Format Format    // JSONL по умолчанию
+	Tables []string  // какие таблицы выгрузить; nil — все Tables. Порядок в архиве всё равно по зависимостям
+	Since  time.Time // только строки, у которых колонка Table.Since не раньше Since; нулевое — все
+	Now    time.Time // время создания в манифесте; по умолчанию текущее
+}
+
+// Export пишет в w архив tar.gz с таблицами и манифестом и возвращает манифест
+func Export(ctx context.Context, db *sql.DB, w io.Writer, opts ExportOptions) (*Manifest, error) {
+	driver := opts.Driver
+	if driver == "" {
+		driver = "postgres"
+	}
+	d, err := Dialect.For(driver)
+	if err != nil {
+		return nil, fmt.Errorf("backup: %w", err)
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = JSONL
+	}
+	if _, err := ParseFormat(string(format)); err != nil {
+		return nil, err
+	}
+
+	tables, err := selectTables(opts.Tables)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := schemaVersion(ctx, db, d)
+	if err != nil {
+		return nil, fmt.Errorf("backup: версия схемы: %w", err)
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	manifest := &Manifest{Version: ManifestVersion, SchemaVersion: version, Driver: d.Name(), Format: format, CreatedAt: now.UTC()}
+	if !opts.Since.IsZero() {
+		since := opts.Since.UTC()
+		manifest.Since = &since
+	}
+
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	// снимок всех таблиц одного момента; SQLite уровней изоляции не различает — его транзакция и так снимок
+	txOpts := Transactions.Options{MaxRetries: -1}
+	if d == Dialect.Postgres {
+		txOpts.Isolation, txOpts.ReadOnly = sql.LevelRepeatableRead, true
+	}
+	err = Transactions.New(db).WithTx(ctx, txOpts, func(ctx context.Context, tx *sql.Tx) error {
+		for _, t := range tables {
+			f, err := os.CreateTemp("", "backup-"+t.Name+"-*")
+			if err != nil {
+				return err
+			}
+			files = append(files, f)
+
+			tf, err := exportTable(ctx, tx, d, t, opts.Since, format, f)
+			if err != nil {
+				return fmt.Errorf("backup: %s: %w", t.Name, err)
+			}
+			manifest.Tables = append(manifest.Tables, *tf)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeArchive(w, manifest, files); err != nil {
+		return nil, fmt.Errorf("backup: запись архива: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// exportTable выгружает таблицу в out, попутно считая строки и SHA-256
+func exportTable(ctx context.Context, tx *sql.Tx, d Dialect.Dialect, t Table, since time.Time, format Format, out io.Writer) (*TableFile, error) {
+	q := Query.Select().From(t.Name).OrderBy("id")
+	if !since.IsZero() {
+		q = q.Where(Query.Ge(t.Since, d.Time(since.UTC())))
+	}
+	query, args, err := q.ToSQL(d)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	tf := &TableFile{Name: t.Name, File: t.Name + "." + string(format)}
+	for _, ct := range types {
+		tf.Columns = append(tf.Columns, Column{Name: ct.Name(), Kind: kindOf(ct.DatabaseTypeName())})
+	}
+
+	hash := sha256.New()
+	enc := newEncoder(format, io.MultiWriter(out, hash), tf.Columns)
+	if err := enc.header(); err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(tf.Columns))
+	ptrs := make([]any, len(values))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		if err := enc.row(values); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", tf.Rows+1, err)
+		}
+		tf.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := enc.flush(); err != nil {
+		return nil, err
+	}
+	tf.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	return tf, nil
+}
+
+// encoder пишет строки таблицы в JSONL или CSV
+type encoder struct {
+	format  Format
+	w       io.Writer
+	csv     *csv.Writer
+	columns []Column
+	line    []byte
+	record  []string
+}
+
+func newEncoder(format Format, w io.Writer, columns []Column) *encoder {
+	e := &encoder{format: format, w: w, columns: columns}
+	if format == CSV {
+		e.csv = csv.NewWriter(w)
+		e.record = make([]string, len(columns))
+	}
+
+	return e
+}
+
+// header — строка заголовка CSV; у JSONL имена колонок в каждой строке
+func (e *encoder) header() error {
+	if e.csv == nil {
+		return nil
+	}
+
+	for i, c := range e.columns {
+		e.record[i] = c.Name
+	}
+
+	return e.csv.Write(e.record)
+}
+
+func (e *encoder) row(values []any) error {
+	if e.csv == nil {
+		e.line = append(e.line[:0], '{')
+	}
+
+	for i, c := range e.columns {
+		s, ok, err := text(c.Kind, values[i])
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.Name, err)
+		}
+
+		if e.csv != nil {
+			e.record[i] = csvValue(s, ok)
+			continue
+		}
+
+		if i > 0 {
+			e.line = append(e.line, ',')
+		}
+		name, _ := json.Marshal(c.Name)
+		e.line = append(e.line, name...)
+		e.line = append(e.line, ':')
+		e.line = appendJSON(e.line, c.Kind, s, ok)
+	}
+
+	if e.csv != nil {
+		return e.csv.Write(e.record)
+	}
+
+	e.line = append(e.line, '}', '\n')
+	_, err := e.w.Write(e.line)
+	return err
+}
+
+func (e *encoder) flush() error {
+	if e.csv == nil {
+		return nil
+	}
+
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+// csvNull — NULL в CSV, как в COPY у Postgres
+const csvNull = `\N`
+
+// csvValue — значение для CSV: NULL — \N, в остальных \ удваивается, как в COPY, — иначе текст \N прочитался бы как NULL
+func csvValue(s string, ok bool) string {
+	if !ok {
+		return csvNull
+	}
+
+	return strings.ReplaceAll(s, `\`, `\\`)
+}
+
+// fromCSV — обратное csvValue
+func fromCSV(s string) (string, bool) {
+	if s == csvNull {
+		return "", false
+	}
+
+	return strings.ReplaceAll(s, `\\`, `\`), true
+}
+
+// writeArchive — manifest.json первым, чтобы Import проверил версию до чтения данных, затем файлы таблиц
+func writeArchive(w io.Writer, manifest *Manifest, files []*os.File) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	hdr := &tar.Header{Name: manifestFile, Mode: 0o644, Size: int64(len(data)), ModTime: manifest.CreatedAt}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	for i, f := range files {
+		size, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		hdr := &tar.Header{Name: manifest.Tables[i].File, Mode: 0o644, Size: size, ModTime: manifest.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, f); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
@@ -0,0 +1,367 @@
+package Backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Query"
+	"learning/DataBase/Scanner"
+	"learning/DataBase/Transactions"
+)
+
+type ImportOptions struct {
+	Driver string    // "postgres" (по умолчанию) или "sqlite"
+	Tables []string  // только эти таблицы из архива; nil — все
+	Since  time.Time // только строки, у которых колонка Table.Since не раньше Since; нулевое — все
+	Clean  bool      // перед загрузкой удалить все строки выбранных таблиц (как pg_restore --clean)
+}
+
+// Loaded — сколько строк таблицы загружено и сколько отброшено фильтром Since
+type Loaded struct {
+	Table   string
+	Rows    int64
+	Skipped int64
+}
+
+type Result struct {
+	Manifest *Manifest
+	Loaded   []Loaded // в порядке загрузки
+}
+
+/*
+Import загружает архив Export в базу одной транзакцией: ошибка в любой строке или несовпавшая контрольная сумма
+любого файла — и база остаётся как была.
+
+Перед загрузкой проверяется манифест: версия формата и версия схемы — та же миграция, что применена в базе
+(иначе колонки могут не совпасть). Контрольная сумма файла сверяется, когда файл дочитан, — до commit.
+
+Строки вставляются с явными id через INSERT ... ON CONFLICT (id) DO UPDATE: загрузить тот же архив повторно
+или догрузить изменения из архива с Since — безопасно, существующие строки обновятся. После загрузки таблицы
+автоинкремент сдвигается за MAX(id) (Dialect.ResetSequence), чтобы следующий обычный INSERT не занял чужой id.
+
+Clean сначала удаляет строки выбранных таблиц, в обратном порядке зависимостей. Внешние ключи с ON DELETE CASCADE
+удалят и строки зависимых таблиц, даже если их нет в Tables: Clean для одной orders очистит и order_items.
+С архивом или фильтром Since Clean запрещён — удалили бы всё, а загрузили только изменения.
+*/
+func Import(ctx context.Context, db *sql.DB, r io.Reader, opts ImportOptions) (*Result, error) {
+	driver := opts.Driver
+	if driver == "" {
+		driver = "postgres"
+	}
+	d, err := Dialect.For(driver)
+	if err != nil {
+		return nil, fmt.Errorf("backup: %w", err)
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	manifest, err := readManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := schemaVersion(ctx, db, d)
+	if err != nil {
+		return nil, fmt.Errorf("backup: версия схемы: %w", err)
+	}
+	if manifest.SchemaVersion != version {
+		return nil, fmt.Errorf("%w: архив — %d, база — %d (dbctl up или status)", ErrSchemaVersion, manifest.SchemaVersion, version)
+	}
+
+	selected, err := importTables(manifest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Manifest: manifest}
+	err = Transactions.New(db).WithTx(ctx, Transactions.Options{MaxRetries: -1}, func(ctx context.Context, tx *sql.Tx) error {
+		if opts.Clean {
+			for _, name := range slices.Backward(selected) {
+				if _, err := tx.ExecContext(ctx, "DELETE FROM "+name); err != nil {
+					return fmt.Errorf("backup: очистка %s: %w", name, err)
+				}
+			}
+		}
+
+		seen := make(map[string]bool, len(manifest.Tables))
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrManifest, err)
+			}
+
+			tf := manifest.table(hdr.Name)
+			if tf == nil || seen[tf.File] {
+				return fmt.Errorf("%w: файл %s не описан в манифесте", ErrManifest, hdr.Name)
+			}
+			seen[tf.File] = true
+
+			hash := sha256.New()
+			src := io.TeeReader(tr, hash)
+
+			if slices.Contains(selected, tf.Name) {
+				loaded, err := importTable(ctx, tx, d, manifest.Format, tf, src, opts.Since)
+				if err != nil {
+					return fmt.Errorf("backup: %s: %w", tf.File, err)
+				}
+				result.Loaded = append(result.Loaded, *loaded)
+			}
+
+			// непрочитанный остаток (и файлы невыбранных таблиц) — тоже в контрольную сумму
+			if _, err := io.Copy(io.Discard, src); err != nil {
+				return fmt.Errorf("%w: %w", ErrManifest, err)
+			}
+			if sum := hex.EncodeToString(hash.Sum(nil)); sum != tf.SHA256 {
+				return fmt.Errorf("%w: %s: %s, в манифесте %s", ErrChecksum, tf.File, sum, tf.SHA256)
+			}
+		}
+
+		for _, tf := range manifest.Tables {
+			if !seen[tf.File] {
+				return fmt.Errorf("%w: нет файла %s", ErrManifest, tf.File)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// readManifest — первый файл архива
+func readManifest(tr *tar.Reader) (*Manifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+	if hdr.Name != manifestFile {
+		return nil, fmt.Errorf("%w: первым файлом ожидается %s, а не %s", ErrManifest, manifestFile, hdr.Name)
+	}
+
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrManifest, manifestFile, err)
+	}
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("%w: версия формата %d, поддерживается %d", ErrManifest, m.Version, ManifestVersion)
+	}
+	if _, err := ParseFormat(string(m.Format)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+
+	return &m, nil
+}
+
+// importTables — имена таблиц для загрузки в порядке манифеста (он уже по зависимостям)
+func importTables(m *Manifest, opts ImportOptions) ([]string, error) {
+	if opts.Clean && (m.Since != nil || !opts.Since.IsZero()) {
+		return nil, fmt.Errorf("backup: Clean с частичной выгрузкой (since) удалил бы строки, которых нет в архиве")
+	}
+
+	var names []string
+	for _, tf := range m.Tables {
+		names = append(names, tf.Name)
+	}
+	for _, name := range opts.Tables {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("backup: таблицы %q нет в архиве", name)
+		}
+	}
+	if len(opts.Tables) > 0 {
+		names = slices.DeleteFunc(names, func(name string) bool { return !slices.Contains(opts.Tables, name) })
+	}
+
+	if !opts.Since.IsZero() {
+		for _, name := range names {
+			if sinceColumn(name) == "" {
+				return nil, fmt.Errorf("backup: у таблицы %s нет колонки для фильтра since", name)
+			}
+		}
+	}
+
+	return names, nil
+}
+
+func sinceColumn(table string) string {
+	for _, t := range Tables {
+		if t.Name == table {
+			return t.Since
+		}
+	}
+
+	return ""
+}
+
+// importTable читает файл таблицы и вставляет строки подготовленным upsert'ом
+func importTable(ctx context.Context, tx *sql.Tx, d Dialect.Dialect, format Format, tf *TableFile, src io.Reader, since time.Time) (*Loaded, error) {
+	names := make([]string, len(tf.Columns))
+	update := make([]string, 0, len(tf.Columns))
+	sinceAt := -1
+	for i, c := range tf.Columns {
+		names[i] = c.Name
+		if c.Name != "id" {
+			update = append(update, c.Name)
+		}
+		if c.Name == sinceColumn(tf.Name) {
+			sinceAt = i
+		}
+	}
+	if !slices.Contains(names, "id") {
+		return nil, fmt.Errorf("нет колонки id")
+	}
+	if !since.IsZero() && sinceAt < 0 {
+		return nil, fmt.Errorf("нет колонки %s для фильтра since", sinceColumn(tf.Name))
+	}
+
+	q, _, err := Query.Insert(tf.Name).Columns(names...).Values(make([]any, len(names))...).
+		Suffix(Query.Raw(d.Upsert([]string{"id"}, update...))).ToSQL(d)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := tx.PrepareContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	loaded := &Loaded{Table: tf.Name}
+	var read int64
+	args := make([]any, len(names))
+
+	err = decode(format, src, tf.Columns, func(values []string, ok []bool) error {
+		read++
+		for i, c := range tf.Columns {
+			v, err := param(d, c.Kind, values[i], ok[i])
+			if err != nil {
+				return fmt.Errorf("строка %d, %s: %w", read, c.Name, err)
+			}
+			args[i] = v
+		}
+
+		if !since.IsZero() && !after(values[sinceAt], ok[sinceAt], since) {
+			loaded.Skipped++
+			return nil
+		}
+
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("строка %d: %w", read, err)
+		}
+		loaded.Rows++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if read != tf.Rows {
+		return nil, fmt.Errorf("%w: строк %d, в манифесте %d", ErrManifest, read, tf.Rows)
+	}
+
+	if reset := d.ResetSequence(tf.Name); reset != "" {
+		if _, err := tx.ExecContext(ctx, reset); err != nil {
+			return nil, fmt.Errorf("сдвиг автоинкремента: %w", err)
+		}
+	}
+
+	return loaded, nil
+}
+
+// after — значение колонки Since не раньше since; NULL — нет
+func after(s string, ok bool, since time.Time) bool {
+	if !ok {
+		return false
+	}
+
+	var t time.Time
+	if err := Scanner.Time(&t).Scan(s); err != nil {
+		return false
+	}
+
+	return !t.Before(since)
+}
+
+// decode читает строки JSONL или CSV и отдаёт fn значения колонок текстом; ok[i] == false — NULL
+func decode(format Format, src io.Reader, columns []Column, fn func(values []string, ok []bool) error) error {
+	values := make([]string, len(columns))
+	ok := make([]bool, len(columns))
+
+	if format == CSV {
+		cr := csv.NewReader(src)
+		cr.ReuseRecord = true
+		cr.FieldsPerRecord = len(columns)
+
+		header, err := cr.Read()
+		if err != nil {
+			return fmt.Errorf("%w: заголовок CSV: %w", ErrManifest, err)
+		}
+		for i, c := range columns {
+			if header[i] != c.Name {
+				return fmt.Errorf("%w: колонка %d в CSV — %s, в манифесте %s", ErrManifest, i+1, header[i], c.Name)
+			}
+		}
+
+		for {
+			record, err := cr.Read()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			for i, s := range record {
+				values[i], ok[i] = fromCSV(s)
+			}
+			if err := fn(values, ok); err != nil {
+				return err
+			}
+		}
+	}
+
+	dec := json.NewDecoder(src)
+	for {
+		var row map[string]json.RawMessage
+		err := dec.Decode(&row)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for i, c := range columns {
+			raw, present := row[c.Name]
+			if !present {
+				return fmt.Errorf("%w: в строке нет колонки %s", ErrManifest, c.Name)
+			}
+			var err error
+			if values[i], ok[i], err = fromJSON(raw); err != nil {
+				return fmt.Errorf("%s: %w", c.Name, err)
+			}
+		}
+		if err := fn(values, ok); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package Backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Scanner"
+)
+
+// Kind — тип колонки в архиве: от него зависит, как значение записано в файл и как передаётся в базу при загрузке
+type Kind string
+
+const (
+	KindInt    Kind = "int"
+	KindNumber Kind = "number" // DECIMAL: в файле — точная десятичная запись, без округления float64
+	KindText   Kind = "text"
+	KindTime   Kind = "time"
+	KindBool   Kind = "bool"
+	KindJSON   Kind = "json"
+)
+
+// kindOf — Kind по DatabaseTypeName драйвера: "INT4", "NUMERIC", "JSONB" у lib/pq, объявленный тип колонки в SQLite
+func kindOf(dbType string) Kind {
+	t := strings.ToUpper(dbType)
+
+	switch {
+	case strings.Contains(t, "INT") || strings.Contains(t, "SERIAL"):
+		return KindInt
+	case strings.Contains(t, "NUMERIC") || strings.Contains(t, "DECIMAL") || strings.Contains(t, "REAL") ||
+		strings.Contains(t, "FLOAT") || strings.Contains(t, "DOUBLE"):
+		return KindNumber
+	case strings.Contains(t, "TIME") || strings.Contains(t, "DATE"):
+		return KindTime
+	case strings.Contains(t, "BOOL"):
+		return KindBool
+	case strings.Contains(t, "JSON"):
+		return KindJSON
+	}
+
+	return KindText
+}
+
+// text — значение из базы в виде текста для файла; ok == false — NULL
+func text(kind Kind, v any) (s string, ok bool, err error) {
+	if v == nil {
+		return "", false, nil
+	}
+	if b, isBytes := v.([]byte); isBytes {
+		v = string(b)
+	}
+
+	switch kind {
+	case KindTime:
+		var t time.Time
+		if err := Scanner.Time(&t).Scan(v); err != nil {
+			return "", false, err
+		}
+		return t.UTC().Format(time.RFC3339Nano), true, nil
+	case KindBool:
+		switch b := v.(type) {
+		case bool:
+			return strconv.FormatBool(b), true, nil
+		case int64:
+			return strconv.FormatBool(b != 0), true, nil // SQLite: 0/1
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			return strconv.FormatBool(parsed), err == nil, err
+		}
+	case KindNumber:
+		if f, isFloat := v.(float64); isFloat {
+			return strconv.FormatFloat(f, 'f', -1, 64), true, nil
+		}
+	}
+
+	switch x := v.(type) {
+	case string:
+		return x, true, nil
+	case int64:
+		return strconv.FormatInt(x, 10), true, nil
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true, nil
+	case time.Time:
+		return x.UTC().Format(time.RFC3339Nano), true, nil
+	}
+
+	return fmt.Sprint(v), true, nil
+}
+
+// appendJSON — значение для строки JSONL: числа, булевы и JSON без кавычек, остальное — строкой
+func appendJSON(dst []byte, kind Kind, s string, ok bool) []byte {
+	if !ok {
+		return append(dst, "null"...)
+	}
+
+	switch kind {
+	case KindInt, KindNumber, KindBool:
+		return append(dst, s...)
+	case KindJSON:
+		if json.Valid([]byte(s)) {
+			return append(dst, s...)
+		}
+	}
+
+	quoted, _ := json.Marshal(s)
+	return append(dst, quoted...)
+}
+
+// fromJSON — обратно к тексту: строка без кавычек, число или JSON как есть
+func fromJSON(raw json.RawMessage) (s string, ok bool, err error) {
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+		return "", false, nil
+	case raw[0] == '"':
+		err := json.Unmarshal(raw, &s)
+		return s, err == nil, err
+	}
+
+	return string(raw), true, nil
+}
+
+// param — текст из файла в значение параметра для базы d
+func param(d Dialect.Dialect, kind Kind, s string, ok bool) (any, error) {
+	if !ok {
+		return nil, nil
+	}
+
+	switch kind {
+	case KindInt:
+		return strconv.ParseInt(s, 10, 64)
+	case KindBool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, err
+		}
+		return d.Bool(b), nil
+	case KindTime:
+		var t time.Time
+		if err := Scanner.Time(&t).Scan(s); err != nil {
+			return nil, err
+		}
+		return d.Time(t.UTC()), nil
+	}
+
+	// KindNumber — строкой: "999.99" ляжет в DECIMAL точно; KindJSON — строкой, а не []byte (lib/pq отправил бы bytea)
+	return s, nil
+}
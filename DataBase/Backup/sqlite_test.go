@@ -0,0 +1,258 @@
+//go:build sqlite
+
+package Backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"learning/DataBase/Connection"
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Migrations"
+)
+
+// openSQLite — новая база SQLite во временном каталоге со всеми миграциями
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DATABASE_URL", filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)")
+
+	db, err := Connection.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := Migrations.New(db, Dialect.SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Log = log.New(io.Discard, "", 0)
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// seed — строки, на которых легко потерять NULL: пустой age, description NULL, пустая строка, текст \N и обратная косая черта
+func seed(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	for _, q := range []string{
+		"INSERT INTO users (id, username, email, age) VALUES (1, 'daniil', 'daniil@example.com', 30), (2, 'anna', 'anna@example.com', NULL)",
+		"INSERT INTO categories (id, name) VALUES (1, 'Смартфоны')",
+		`INSERT INTO products (id, name, description, price, category_id) VALUES
+			(1, 'iPhone 15', NULL, 999.99, 1),
+			(2, 'Чехол', '\N', 49.99, NULL),
+			(3, 'Плёнка', '', 10.00, NULL),
+			(4, 'Кабель', 'C:\new\\dir, "2 м"', 15.50, 1)`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(q, err)
+		}
+	}
+}
+
+type product struct {
+	ID          int
+	Name        string
+	Description sql.NullString
+	Price       float64
+	CategoryID  sql.NullInt64
+}
+
+func products(t *testing.T, db *sql.DB) []product {
+	t.Helper()
+
+	rows, err := db.Query("SELECT id, name, description, price, category_id FROM products ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var out []product
+	for rows.Next() {
+		var p product
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.CategoryID); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return out
+}
+
+func export(t *testing.T, db *sql.DB, opts ExportOptions) *bytes.Buffer {
+	t.Helper()
+
+	opts.Driver = "sqlite"
+	var archive bytes.Buffer
+	if _, err := Export(context.Background(), db, &archive, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	return &archive
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, format := range []Format{JSONL, CSV} {
+		t.Run(string(format), func(t *testing.T) {
+			ctx := context.Background()
+			src := openSQLite(t)
+			seed(t, src)
+			archive := export(t, src, ExportOptions{Format: format})
+
+			dst := openSQLite(t)
+			result, err := Import(ctx, dst, archive, ImportOptions{Driver: "sqlite"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Manifest.Format != format || len(result.Loaded) != len(Tables) {
+				t.Fatalf("формат %s, загружено таблиц %d, ожидалось %s и %d", result.Manifest.Format, len(result.Loaded), format, len(Tables))
+			}
+
+			want, got := products(t, src), products(t, dst)
+			if len(got) != len(want) {
+				t.Fatalf("товаров %d, ожидалось %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("товар %d: %+v, ожидалось %+v", want[i].ID, got[i], want[i])
+				}
+			}
+			// сами по себе: NULL остался NULL, а текст \N и пустая строка — текстом
+			if got[0].Description.Valid || got[1].Description != (sql.NullString{String: `\N`, Valid: true}) ||
+				got[2].Description != (sql.NullString{String: "", Valid: true}) || got[1].CategoryID.Valid {
+				t.Fatalf("NULL и текст перепутаны: %+v", got[:3])
+			}
+
+			var age sql.NullInt64
+			if err := dst.QueryRow("SELECT age FROM users WHERE id = 2").Scan(&age); err != nil {
+				t.Fatal(err)
+			}
+			if age.Valid {
+				t.Fatalf("users.age у anna: %d, ожидался NULL", age.Int64)
+			}
+
+			// автоинкремент сдвинут за загруженные id
+			var id int
+			if err := dst.QueryRow("INSERT INTO products (name, price) VALUES ('Новый', 1) RETURNING id").Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			if id != 5 {
+				t.Fatalf("id нового товара %d, ожидалось 5", id)
+			}
+		})
+	}
+}
+
+// tamper переписывает файл name внутри архива, манифест остаётся прежним
+func tamper(t *testing.T, archive *bytes.Buffer, name string, edit func([]byte) []byte) *bytes.Buffer {
+	t.Helper()
+
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name == name {
+			data = edit(data)
+			hdr.Size = int64(len(data))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &out
+}
+
+func TestImportChecksum(t *testing.T) {
+	ctx := context.Background()
+	src := openSQLite(t)
+	seed(t, src)
+	archive := tamper(t, export(t, src, ExportOptions{Format: CSV}), "products.csv", func(data []byte) []byte {
+		return bytes.Replace(data, []byte("999.99"), []byte("9.99"), 1)
+	})
+
+	dst := openSQLite(t)
+	_, err := Import(ctx, dst, archive, ImportOptions{Driver: "sqlite"})
+	if !errors.Is(err, ErrChecksum) {
+		t.Fatalf("изменённый products.csv: %v, ожидалась ErrChecksum", err)
+	}
+
+	// всё в одной транзакции: users, загруженные до products, тоже откатились
+	if got := products(t, dst); len(got) != 0 {
+		t.Fatalf("после ErrChecksum в базе %d товаров, ожидалось 0", len(got))
+	}
+	var users int
+	if err := dst.QueryRow("SELECT COUNT(*) FROM users").Scan(&users); err != nil {
+		t.Fatal(err)
+	}
+	if users != 0 {
+		t.Fatalf("после ErrChecksum в базе %d пользователей, ожидалось 0", users)
+	}
+}
+
+// Clean удалил бы все строки, а архив с Since или фильтр Since загрузили бы только изменения
+func TestImportCleanWithSince(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	seed(t, db)
+	since := time.Now().Add(-time.Hour)
+
+	for name, c := range map[string]struct {
+		export ExportOptions
+		since  time.Time
+	}{
+		"архив с since": {export: ExportOptions{Since: since}},
+		"фильтр since":  {since: since},
+	} {
+		archive := export(t, db, c.export)
+		if _, err := Import(ctx, db, archive, ImportOptions{Driver: "sqlite", Clean: true, Since: c.since}); err == nil {
+			t.Fatalf("%s: Clean принят", name)
+		}
+		if got := products(t, db); len(got) != 4 {
+			t.Fatalf("%s: после отказа в базе %d товаров, ожидалось 4", name, len(got))
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package Connection
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+
+	_ "github.com/lib/pq"
+
+	_ "learning/DataBase/Instrument" // регистрирует "logged-postgres"
+)
+
+/*
+Параметры подключения к Postgres для модуля DataBase.
+
+DataBase — отдельный модуль, пакет learning/Config из корня ему недоступен, поэтому здесь читаются те же переменные окружения,
+что описаны в Config.DatabaseConfig и задаются в docker-compose.yml: DB_HOST, DB_PORT, DB_NAME, DB_USER, DB_PASSWORD, DB_SSLMODE.
+Значения по умолчанию тоже совпадают, а DATABASE_URL, если задан, используется целиком.
+
+DB_DRIVER выбирает драйвер для Open: по умолчанию "postgres", "logged-postgres" — тот же lib/pq с логированием запросов (Instrument).
+"sqlite" — файл SQLite из DATABASE_URL, драйвер подключается тегом сборки (sqlite.go); SQL под выбранный драйвер —
+через Dialect.For(Connection.Driver()). Локальный файл вместо Postgres из docker-compose:
+
+	go run -tags sqlite ./tools/dbctl up -driver sqlite -dsn "test.db?_pragma=foreign_keys(1)"
+	DB_DRIVER=sqlite DATABASE_URL="test.db?_pragma=foreign_keys(1)" go run -tags sqlite databaseTransaction.go
+
+foreign_keys(1) — SQLite по умолчанию внешние ключи не проверяет.
+Тесты с тегом sqlite (go test -tags sqlite ./...) открывают базу так же, но в своём файле во временном каталоге.
+
+Шаблон раскрывается через os.Expand — как os.ExpandEnv, только с подстановкой значения по умолчанию для незаданных переменных.
+Значения берутся в кавычки, как в Config.DatabaseConfig.DSN: пароль с пробелом или ' иначе разорвал бы строку подключения.
+*/
+
+const dsnTemplate = "host=${DB_HOST} port=${DB_PORT} user=${DB_USER} password=${DB_PASSWORD} dbname=${DB_NAME} sslmode=${DB_SSLMODE}"
+
+var defaults = map[string]string{
+	"DB_HOST":     "postgres",
+	"DB_PORT":     "5432",
+	"DB_NAME":     "go_learning_db",
+	"DB_USER":     "go_user",
+	"DB_PASSWORD": "go_password",
+	"DB_SSLMODE":  "disable",
+}
+
+func DSN() string {
+	if url := os.Getenv("DATABASE_URL"); url != "" {
+		return url
+	}
+
+	return os.Expand(dsnTemplate, func(key string) string {
+		if v, ok := os.LookupEnv(key); ok {
+			return quote(v)
+		}
+		return quote(defaults[key])
+	})
+}
+
+// quote — значение для строки подключения lib/pq: пустое или с пробелом, ' или \ — в одинарных кавычках с экранированием
+func quote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " '\\") {
+		return s
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' || s[i] == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(s[i])
+	}
+	sb.WriteByte('\'')
+
+	return sb.String()
+}
+
+// Driver — имя драйвера из DB_DRIVER, по умолчанию "postgres"
+func Driver() string {
+	if driver := os.Getenv("DB_DRIVER"); driver != "" {
+		return driver
+	}
+
+	return "postgres"
+}
+
+// Open — sql.Open(Driver(), DSN()); как и sql.Open, реального соединения не устанавливает
+func Open() (*sql.DB, error) {
+	return sql.Open(Driver(), DSN())
+}
@@ -0,0 +1,66 @@
+package Connection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"learning/DataBase/Instrument"
+)
+
+// ExampleLoggedPostgres — обычная работа с базой, но через "logged-postgres": каждый запрос в логе, в конце — статистика
+func ExampleLoggedPostgres() {
+	Instrument.Default.SlowThreshold = 50 * time.Millisecond
+	Instrument.Default.OnEvent = func(ctx context.Context, e Instrument.Event) {
+		if e.Slow {
+			// сюда подключается трассировка или алерт; ctx — тот, что передан в QueryContext/ExecContext
+			fmt.Println("медленный запрос:", Instrument.Normalize(e.Query))
+		}
+	}
+
+	db, err := sql.Open("logged-postgres", DSN())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var id int
+	err = db.QueryRowContext(ctx,
+		"INSERT INTO orders (user_id, total_amount, status) VALUES ($1, $2, $3) RETURNING id",
+		2, 1200.25, "pending",
+	).Scan(&id)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for range 3 {
+		var status string
+		if err := db.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1", id).Scan(&status); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Искусственно медленный запрос
+	if _, err := db.ExecContext(ctx, "SELECT pg_sleep(0.1)"); err != nil {
+		log.Fatal(err)
+	}
+
+	// Ошибка тоже попадает в лог и в счётчик ошибок
+	var missing string
+	if err := db.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1", -1).Scan(&missing); !errors.Is(err, sql.ErrNoRows) {
+		log.Fatal(err)
+	}
+
+	if _, err := db.ExecContext(ctx, "DELETE FROM orders WHERE id = $1", id); err != nil {
+		log.Fatal(err)
+	}
+
+	Instrument.Default.Metrics.WriteTo(os.Stdout)
+}
@@ -0,0 +1,6 @@
+//go:build sqlite
+
+package Connection
+
+// Драйвер SQLite без cgo; подключается только с -tags sqlite, чтобы обычная сборка не тянула лишнюю зависимость.
+import _ "modernc.org/sqlite"
@@ -0,0 +1,213 @@
+package Dialect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Различия Postgres и SQLite, которые касаются кода доступа к данным, собранные в одном месте.
+
+	d, err := Dialect.For(Connection.Driver())
+
+	db.QueryRowContext(ctx, d.Rebind("SELECT ... WHERE id = $1"+d.ForUpdate()), id)
+
+	Query.Insert("products").Columns("id", "name", "price").Values(1, "iPhone 15", 999.99).
+		Suffix(Query.Raw(d.Upsert([]string{"id"}, "name", "price"))).ToSQL(d)
+
+	if errors.Is(d.Classify(err), Dialect.ErrUniqueViolation) { ... }
+
+Что отличается:
+	- плейсхолдеры: $1 в Postgres, ?1 в SQLite. Запросы пишутся с $n, Rebind переводит их в стиль диалекта;
+	  ?1 — нумерованный плейсхолдер SQLite: $1, встреченный в запросе дважды, остаётся одним параметром;
+	- кавычки для имён: Quote("order") -> "order" (двойные кавычки понимают оба);
+	- upsert: оба понимают INSERT ... ON CONFLICT (cols) DO UPDATE SET col = excluded.col (SQLite с 3.24);
+	- RETURNING: Postgres всегда, SQLite с 3.35 (modernc.org/sqlite и свежий mattn/go-sqlite3 — да);
+	- вставка с явными id (фикстуры, восстановление из архива): в Postgres после неё нужен setval — ResetSequence;
+	- блокировка строк: FOR UPDATE есть только в Postgres; в SQLite пишет одна транзакция на всю базу,
+	  поэтому ForUpdate() там пустой, а конфликт писателей приходит ошибкой SQLITE_BUSY — она классифицируется
+	  как ErrSerialization, и Transactions.WithTx повторяет транзакцию так же, как при 40001 в Postgres;
+	- типы: BOOLEAN и TIMESTAMP в Postgres; в SQLite булево — 0/1, время — текст. Time(t) приводит время
+	  к тексту в UTC, который сравнивается с CURRENT_TIMESTAMP как строка (available_at <= ? в Outbox);
+	  параметру без колонки рядом тип задаётся явно: CAST($1 AS TimeType()) — TIMESTAMP / TEXT (границы периодов в Reports);
+	- текущее время в запросе: NOW() / STRFTIME(...) с миллисекундами (CURRENT_TIMESTAMP в SQLite — до секунды);
+	- ошибки: коды SQLSTATE Postgres и коды SQLite сводятся к ErrUniqueViolation, ErrForeignKeyViolation, ... (Errors.go).
+
+Чего здесь нет: DDL. Схема для каждого диалекта — свои файлы миграций (0001_create_users.up.sqlite.sql),
+там различия типов видны явно, а не спрятаны за функциями.
+*/
+
+type Dialect interface {
+	Name() string // "postgres", "sqlite"
+
+	Placeholder(n int) string // n-й параметр, с 1
+	Rebind(query string) string
+	Quote(ident string) string
+
+	// Upsert — окончание INSERT при конфликте по колонкам conflict: обновить update, без update — ничего не делать
+	Upsert(conflict []string, update ...string) string
+	Returning() bool
+	ForUpdate() string // " FOR UPDATE" или "", если диалект не блокирует строки
+	// ResetSequence — запрос, который сдвигает автоинкремент id таблицы за MAX(id) после вставки с явными id; "" — не нужен
+	ResetSequence(table string) string
+
+	Now() string          // SQL-выражение текущего времени
+	Time(t time.Time) any // значение времени для параметра
+	Bool(b bool) any      // значение булева для параметра
+	BoolType() string
+	TimeType() string
+
+	// Classify оборачивает ошибку драйвера в ErrUniqueViolation / ErrForeignKeyViolation / ... ; исходная остаётся в цепочке
+	Classify(err error) error
+}
+
+var (
+	Postgres Dialect = postgres{}
+	SQLite   Dialect = sqlite{}
+)
+
+// For — диалект по имени драйвера database/sql
+func For(driver string) (Dialect, error) {
+	switch driver {
+	case "postgres", "pgx", "logged-postgres":
+		return Postgres, nil
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	}
+
+	return nil, fmt.Errorf("dialect: неизвестный драйвер %q", driver)
+}
+
+type postgres struct{}
+
+func (postgres) Name() string               { return "postgres" }
+func (postgres) Placeholder(n int) string   { return "$" + strconv.Itoa(n) }
+func (postgres) Rebind(query string) string { return query }
+func (postgres) Quote(ident string) string  { return quote(ident) }
+func (postgres) Returning() bool            { return true }
+func (postgres) ForUpdate() string          { return " FOR UPDATE" }
+func (postgres) Now() string                { return "NOW()" }
+func (postgres) Time(t time.Time) any       { return t }
+func (postgres) Bool(b bool) any            { return b }
+func (postgres) BoolType() string           { return "BOOLEAN" }
+func (postgres) TimeType() string           { return "TIMESTAMP" }
+func (postgres) Classify(err error) error   { return classifyPostgres(err) }
+
+func (postgres) Upsert(conflict []string, update ...string) string {
+	return upsert(conflict, update)
+}
+
+// ResetSequence — setval по MAX(id); у пустой таблицы MAX — NULL, и setval(seq, NULL) ничего не меняет
+func (postgres) ResetSequence(table string) string {
+	return fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), (SELECT MAX(id) FROM %[1]s))", table)
+}
+
+// TimeLayout — как SQLite хранит время: тот же вид, что у CURRENT_TIMESTAMP, плюс миллисекунды
+const TimeLayout = "2006-01-02 15:04:05.000"
+
+type sqlite struct{}
+
+func (sqlite) Name() string               { return "sqlite" }
+func (sqlite) Placeholder(n int) string   { return "?" + strconv.Itoa(n) }
+func (sqlite) Rebind(query string) string { return rebind(query, SQLite) }
+func (sqlite) Quote(ident string) string  { return quote(ident) }
+func (sqlite) Returning() bool            { return true }
+func (sqlite) ForUpdate() string          { return "" }
+func (sqlite) Now() string                { return "STRFTIME('%Y-%m-%d %H:%M:%f', 'now')" }
+func (sqlite) Time(t time.Time) any       { return t.UTC().Format(TimeLayout) }
+func (sqlite) BoolType() string           { return "INTEGER" }
+func (sqlite) TimeType() string           { return "TEXT" }
+func (sqlite) Classify(err error) error   { return classifySQLite(err) }
+
+func (sqlite) Bool(b bool) any {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (sqlite) Upsert(conflict []string, update ...string) string {
+	return upsert(conflict, update)
+}
+
+// ResetSequence — не нужен: AUTOINCREMENT сам запоминает в sqlite_sequence максимальный вставленный id
+func (sqlite) ResetSequence(string) string { return "" }
+
+// quote — "name", кавычки внутри удваиваются; "o.user_id" -> "o"."user_id"
+func quote(ident string) string {
+	parts := strings.Split(ident, ".")
+	for i, p := range parts {
+		parts[i] = `"` + strings.ReplaceAll(p, `"`, `""`) + `"`
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func upsert(conflict, update []string) string {
+	var sb strings.Builder
+
+	sb.WriteString("ON CONFLICT (")
+	sb.WriteString(strings.Join(conflict, ", "))
+	sb.WriteString(")")
+
+	if len(update) == 0 {
+		sb.WriteString(" DO NOTHING")
+		return sb.String()
+	}
+
+	sb.WriteString(" DO UPDATE SET ")
+	for i, col := range update {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(col + " = excluded." + col)
+	}
+
+	return sb.String()
+}
+
+// rebind заменяет $n на плейсхолдеры d вне строк, идентификаторов в кавычках и комментариев
+func rebind(query string, d Dialect) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				sb.WriteString(query[i:])
+				return sb.String()
+			}
+			sb.WriteString(query[i : i+end+2])
+			i += end + 1
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				sb.WriteString(query[i:])
+				return sb.String()
+			}
+			sb.WriteString(query[i : i+end])
+			i += end - 1
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			j := i + 1
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			n, _ := strconv.Atoi(query[i+1 : j])
+			sb.WriteString(d.Placeholder(n))
+			i = j - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,145 @@
+package Dialect
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+/*
+Классы ошибок, одинаковые для обоих диалектов. Classify оборачивает ими ошибку драйвера:
+
+	errors.Is(err, Dialect.ErrUniqueViolation) // и при 23505 в Postgres, и при "UNIQUE constraint failed" в SQLite
+	var pqErr *pq.Error; errors.As(err, &pqErr)  // исходная ошибка по-прежнему доступна
+
+Postgres: *pq.Error или любая ошибка с методом SQLState() (pgx). SQLite: ошибка с методом Code() int
+(modernc.org/sqlite отдаёт расширенный код), иначе — по тексту, который одинаков у всех драйверов SQLite.
+Сами драйверы SQLite пакет не импортирует — модуль от них не зависит.
+*/
+
+var (
+	ErrUniqueViolation     = errors.New("dialect: нарушена уникальность")
+	ErrForeignKeyViolation = errors.New("dialect: нарушен внешний ключ")
+	ErrCheckViolation      = errors.New("dialect: нарушено ограничение CHECK")
+	ErrNotNullViolation    = errors.New("dialect: NULL в NOT NULL колонке")
+	ErrInvalidValue        = errors.New("dialect: значение не подходит к типу колонки")
+
+	// ErrSerialization — транзакцию стоит повторить целиком: 40001 / 40P01 в Postgres, SQLITE_BUSY / SQLITE_LOCKED в SQLite
+	ErrSerialization = errors.New("dialect: конфликт транзакций")
+)
+
+var postgresCodes = map[string]error{
+	"23505": ErrUniqueViolation,     // unique_violation
+	"23503": ErrForeignKeyViolation, // foreign_key_violation
+	"23514": ErrCheckViolation,      // check_violation
+	"23502": ErrNotNullViolation,    // not_null_violation
+	"22P02": ErrInvalidValue,        // invalid_text_representation
+	"40001": ErrSerialization,       // serialization_failure
+	"40P01": ErrSerialization,       // deadlock_detected
+}
+
+// Коды SQLite: основной код в младшем байте, расширенный — целиком
+const (
+	sqliteBusy             = 5
+	sqliteLocked           = 6
+	sqliteMismatch         = 20
+	sqliteConstraintCheck  = 275
+	sqliteConstraintFK     = 787
+	sqliteConstraintNull   = 1299
+	sqliteConstraintPK     = 1555
+	sqliteConstraintUnique = 2067
+)
+
+var sqliteCodes = map[int]error{
+	sqliteBusy:             ErrSerialization,
+	sqliteLocked:           ErrSerialization,
+	sqliteMismatch:         ErrInvalidValue,
+	sqliteConstraintCheck:  ErrCheckViolation,
+	sqliteConstraintFK:     ErrForeignKeyViolation,
+	sqliteConstraintNull:   ErrNotNullViolation,
+	sqliteConstraintPK:     ErrUniqueViolation,
+	sqliteConstraintUnique: ErrUniqueViolation,
+}
+
+// по тексту: у mattn/go-sqlite3 код — поле, а не метод
+var sqliteMessages = []struct {
+	text string
+	err  error
+}{
+	{"UNIQUE constraint failed", ErrUniqueViolation},
+	{"FOREIGN KEY constraint failed", ErrForeignKeyViolation},
+	{"CHECK constraint failed", ErrCheckViolation},
+	{"NOT NULL constraint failed", ErrNotNullViolation},
+	{"database is locked", ErrSerialization},
+	{"database table is locked", ErrSerialization},
+	{"datatype mismatch", ErrInvalidValue},
+}
+
+// Classify — классификация без знания диалекта: пробует и Postgres, и SQLite (Transactions.Retryable)
+func Classify(err error) error {
+	if c := classifyPostgres(err); c != err {
+		return c
+	}
+
+	return classifySQLite(err)
+}
+
+func classifyPostgres(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var code string
+	var pqErr *pq.Error
+	var coded interface{ SQLState() string }
+	switch {
+	case errors.As(err, &pqErr):
+		code = string(pqErr.Code)
+	case errors.As(err, &coded):
+		code = coded.SQLState()
+	default:
+		return err
+	}
+
+	if class, ok := postgresCodes[code]; ok {
+		return wrap(class, err)
+	}
+
+	return err
+}
+
+func classifySQLite(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		code := coded.Code()
+		if class, ok := sqliteCodes[code]; ok {
+			return wrap(class, err)
+		}
+		if class, ok := sqliteCodes[code&0xff]; ok {
+			return wrap(class, err)
+		}
+	}
+
+	msg := err.Error()
+	for _, m := range sqliteMessages {
+		if strings.Contains(msg, m.text) {
+			return wrap(m.err, err)
+		}
+	}
+
+	return err
+}
+
+func wrap(class, err error) error {
+	if errors.Is(err, class) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", class, err)
+}
@@ -0,0 +1,35 @@
+package Dialect
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ExampleRebind — один запрос для обоих диалектов
+func ExampleRebind() {
+	q := "SELECT id FROM orders WHERE (CAST($1 AS INTEGER) IS NULL OR user_id = $1) AND status <> '$2' LIMIT $2"
+
+	for _, d := range []Dialect{Postgres, SQLite} {
+		fmt.Println(d.Name()+":", d.Rebind(q))
+	}
+
+	fmt.Println(SQLite.Rebind("INSERT INTO products (id, name, price) VALUES ($1, $2, $3) ") +
+		SQLite.Upsert([]string{"id"}, "name", "price"))
+	fmt.Println(Postgres.Quote("order"), SQLite.Time(time.Date(2025, 3, 1, 15, 4, 5, 0, time.FixedZone("MSK", 3*3600))))
+}
+
+// ExampleClassify — ошибки разных драйверов сводятся к одним классам
+func ExampleClassify() {
+	pgErr := &pq.Error{Code: "23505", Message: `duplicate key value violates unique constraint "users_email_key"`}
+	sqliteErr := errors.New("constraint failed: UNIQUE constraint failed: users.email (2067)")
+
+	for _, err := range []error{Postgres.Classify(pgErr), SQLite.Classify(sqliteErr)} {
+		fmt.Println(errors.Is(err, ErrUniqueViolation), err)
+	}
+
+	busy := SQLite.Classify(errors.New("database is locked (5) (SQLITE_BUSY)"))
+	fmt.Println("повторить транзакцию:", errors.Is(busy, ErrSerialization))
+}
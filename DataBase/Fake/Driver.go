@@ -0,0 +1,211 @@
+package Fake
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// Реализация driver.Connector / Conn / Stmt / Tx поверх Mock: каждый вызов ищет ожидание и отвечает по нему
+
+type connector struct {
+	mock *Mock
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{mock: c.mock}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return fakeDriver{c.mock}
+}
+
+type fakeDriver struct {
+	mock *Mock
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return &conn{mock: d.mock}, nil
+}
+
+type conn struct {
+	mock   *Mock
+	closed bool
+}
+
+var errClosed = errors.New("fake: соединение закрыто")
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if c.closed {
+		return nil, errClosed
+	}
+
+	e, err := c.mock.match(kindPrepare, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	if e != nil {
+		if err := e.respond(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+	e, err := c.mock.match(kindBegin, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.respond(ctx); err != nil {
+		return nil, err
+	}
+
+	return &tx{conn: c}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.closed {
+		return nil, driver.ErrBadConn
+	}
+
+	e, err := c.mock.match(kindExec, query, values(args))
+	if err != nil {
+		return nil, err
+	}
+	if err := e.respond(ctx); err != nil {
+		return nil, err
+	}
+
+	return result{lastInsertID: e.lastInsertID, rowsAffected: e.rowsAffected}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.closed {
+		return nil, driver.ErrBadConn
+	}
+
+	e, err := c.mock.match(kindQuery, query, values(args))
+	if err != nil {
+		return nil, err
+	}
+	if err := e.respond(ctx); err != nil {
+		return nil, err
+	}
+
+	rows := e.rows
+	if rows == nil {
+		rows = NewRows()
+	}
+
+	return &cursor{rows: rows}, nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput -1 — database/sql не проверяет число аргументов, это делает WithArgs
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), named(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+type tx struct {
+	conn *conn
+}
+
+func (t *tx) Commit() error {
+	e, err := t.conn.mock.match(kindCommit, "", nil)
+	if err != nil {
+		return err
+	}
+
+	return e.respond(context.Background())
+}
+
+func (t *tx) Rollback() error {
+	e, err := t.conn.mock.match(kindRollback, "", nil)
+	if err != nil {
+		return err
+	}
+
+	return e.respond(context.Background())
+}
+
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r result) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+// respond выдерживает задержку (прерываемую ctx) и возвращает заданную ошибку
+func (e *Expectation) respond(ctx context.Context) error {
+	if e.delay > 0 {
+		timer := time.NewTimer(e.delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return e.err
+}
+
+func values(args []driver.NamedValue) []any {
+	out := make([]any, len(args))
+	for i, a := range args {
+		out[i] = a.Value
+	}
+
+	return out
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	out := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		out[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+
+	return out
+}
@@ -0,0 +1,110 @@
+package Fake
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"learning/DataBase/Orders"
+)
+
+/*
+Примеры написаны как обычные функции (t = nil, проверка через ExpectationsWereMet), в go test то же самое выглядит так:
+
+	func TestTransition(t *testing.T) {
+		db, mock := Fake.New(t)
+		expectPayment(mock, time.Now())
+		...
+	}
+*/
+
+func orderRows() *Rows {
+	return NewRows("id", "user_id", "total_amount", "status", "created_at", "updated_at")
+}
+
+// expectPayment — сценарий Orders.SQL.Transition(7, paid): транзакция, SELECT ... FOR UPDATE, UPDATE, запись в историю
+func expectPayment(mock *Mock, now time.Time) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM orders WHERE id = \$1 FOR UPDATE$`).WithArgs(7).
+		WillReturnRows(orderRows().Add(7, 2, 1200.25, "pending", now, now))
+	mock.ExpectQuery(`^UPDATE orders SET`).WithArgs(7, 2, 1200.25, "paid").
+		WillReturnRows(orderRows().Add(7, 2, 1200.25, "paid", now, now.Add(time.Second)))
+	mock.ExpectQuery(`^INSERT INTO order_status_history`).WithArgs(7, "pending", "paid", "example", "оплата").
+		WillReturnRows(NewRows("id", "changed_at").Add(1, now.Add(time.Second)))
+	mock.ExpectCommit()
+}
+
+// ExampleOrderTransition — репозиторий заказов на Postgres без Postgres
+func ExampleOrderTransition() {
+	ctx := context.Background()
+	db, mock := New(nil)
+	defer db.Close()
+
+	repo, err := Orders.NewPostgres(ctx, db) // Prepare без ожиданий проходит молча
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer repo.Close()
+
+	expectPayment(mock, time.Now())
+
+	o, err := repo.Transition(ctx, 7, Orders.StatusPaid, Orders.Meta{Actor: "example", Reason: "оплата"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("заказ", o.ID, "->", o.Status)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// ExampleSerializationRetry — первая попытка получает serialization_failure, Transactions откатывает и повторяет транзакцию
+func ExampleSerializationRetry() {
+	ctx := context.Background()
+	db, mock := New(nil)
+	defer db.Close()
+
+	repo, err := Orders.NewPostgres(ctx, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer repo.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE$`).WithArgs(7).
+		WillReturnError(&pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"})
+	mock.ExpectRollback()
+	expectPayment(mock, time.Now())
+
+	if _, err := repo.Transition(ctx, 7, Orders.StatusPaid, Orders.Meta{Actor: "example", Reason: "оплата"}); err != nil {
+		log.Fatal(err)
+	}
+
+	// Delete никто не ожидал: вызов получает ErrUnexpected, а ExpectationsWereMet его перечислит
+	err = repo.Delete(ctx, 7)
+	fmt.Println("ожидаемо:", err)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// ExampleTimeout — медленный ответ и таймаут context
+func ExampleTimeout() {
+	db, mock := New(nil)
+	defer db.Close()
+
+	mock.ExpectExec(`^DELETE FROM orders WHERE created_at < \$1$`).WithArgs(AnyArg()).
+		WillDelayFor(time.Second).
+		WillReturnResult(0, 120)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := db.ExecContext(ctx, "DELETE FROM orders WHERE created_at < $1", time.Now().AddDate(-1, 0, 0))
+	fmt.Println("ожидаемо:", err)
+}
@@ -0,0 +1,149 @@
+package Fake
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type kind int
+
+const (
+	kindQuery kind = iota
+	kindExec
+	kindPrepare
+	kindBegin
+	kindCommit
+	kindRollback
+)
+
+func (k kind) String() string {
+	return [...]string{"Query", "Exec", "Prepare", "Begin", "Commit", "Rollback"}[k]
+}
+
+// Expectation — один ожидаемый вызов и ответ на него
+type Expectation struct {
+	kind      kind
+	pattern   *regexp.Regexp
+	args      []any // проверяются, только если checkArgs (был вызван WithArgs)
+	checkArgs bool
+
+	rows         *Rows
+	lastInsertID int64
+	rowsAffected int64
+	err          error
+	delay        time.Duration
+
+	done bool
+}
+
+// Matcher — своё правило для аргумента
+type Matcher interface {
+	Match(v driver.Value) bool
+}
+
+type anyArg struct{}
+
+func (anyArg) Match(driver.Value) bool { return true }
+func (anyArg) String() string          { return "<any>" }
+
+// AnyArg — подходит любое значение (время создания, сгенерированный токен, ...)
+func AnyArg() Matcher {
+	return anyArg{}
+}
+
+// WithArgs — ожидаемые аргументы по порядку; значение может быть Matcher
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	e.args = make([]any, len(args))
+	for i, a := range args {
+		if _, ok := a.(Matcher); ok {
+			e.args[i] = a
+			continue
+		}
+		// приводим к тем же типам, что получит драйвер: int -> int64, float32 -> float64 и т.д.
+		v, err := driver.DefaultParameterConverter.ConvertValue(a)
+		if err != nil {
+			panic(fmt.Sprintf("fake: WithArgs(%v): %v", a, err))
+		}
+		e.args[i] = v
+	}
+	e.checkArgs = true
+
+	return e
+}
+
+func (e *Expectation) WillReturnRows(rows *Rows) *Expectation {
+	e.rows = rows
+	return e
+}
+
+// WillReturnResult — ответ на Exec: sql.Result.LastInsertId и RowsAffected
+func (e *Expectation) WillReturnResult(lastInsertID, rowsAffected int64) *Expectation {
+	e.lastInsertID, e.rowsAffected = lastInsertID, rowsAffected
+	return e
+}
+
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) WillDelayFor(d time.Duration) *Expectation {
+	e.delay = d
+	return e
+}
+
+func (e *Expectation) matches(kind kind, query string, args []any) bool {
+	if e.kind != kind {
+		return false
+	}
+	if e.pattern != nil && !e.pattern.MatchString(query) {
+		return false
+	}
+	if !e.checkArgs {
+		return true
+	}
+	if len(args) != len(e.args) {
+		return false
+	}
+
+	for i, want := range e.args {
+		if m, ok := want.(Matcher); ok {
+			if !m.Match(args[i]) {
+				return false
+			}
+			continue
+		}
+		if !equal(want, args[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equal(want, got any) bool {
+	if w, ok := want.(time.Time); ok {
+		g, ok := got.(time.Time)
+		return ok && w.Equal(g)
+	}
+
+	return reflect.DeepEqual(want, got)
+}
+
+func (e *Expectation) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(e.kind.String())
+	if e.pattern != nil {
+		fmt.Fprintf(&sb, " %q", e.pattern.String())
+	}
+	if e.checkArgs {
+		fmt.Fprintf(&sb, " с аргументами %v", e.args)
+	}
+
+	return sb.String()
+}
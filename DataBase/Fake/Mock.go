@@ -0,0 +1,206 @@
+package Fake
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+/*
+Поддельный database/sql драйвер для тестов: без Postgres, без сети, с заранее расписанными ответами.
+
+	db, mock := Fake.New(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT .* FROM orders WHERE id = \$1 FOR UPDATE`).WithArgs(7).
+		WillReturnRows(Fake.NewRows("id", "status").Add(7, "pending"))
+	mock.ExpectExec(`UPDATE orders SET status`).WithArgs("paid", 7).WillReturnResult(0, 1)
+	mock.ExpectCommit()
+
+	err := payOrder(ctx, db, 7) // тестируемый код работает с обычным *sql.DB
+
+Ожидания:
+	- запрос сравнивается регулярным выражением с текстом, в котором пробелы и переводы строк схлопнуты в один пробел;
+	  для точного совпадения — regexp.QuoteMeta или ExpectExactQuery / ExpectExactExec;
+	- WithArgs — аргументы по порядку (int и int64 считаются одним и тем же, как и в database/sql);
+	  Fake.AnyArg() — любой аргумент на этой позиции; без WithArgs аргументы не проверяются;
+	- WillReturnRows / WillReturnResult(lastInsertID, rowsAffected) / WillReturnError;
+	- WillDelayFor — ответ с задержкой, чтобы проверить таймауты context: отменённый ctx прерывает ожидание;
+	- ExpectBegin, ExpectCommit, ExpectRollback — транзакции; неожиданный Begin или Commit — ошибка;
+	- ExpectPrepare — только если нужно проверить сам Prepare или вернуть из него ошибку. Без ожидания Prepare
+	  проходит молча: подготовка — деталь реализации (Orders.NewPostgres готовит запросы заранее),
+	  а выполнение подготовленного запроса сверяется с ExpectExec / ExpectQuery как обычный запрос.
+
+Ожидания выполняются по порядку; MatchInAnyOrder() разрешает любой порядок. Каждое ожидание срабатывает один раз.
+
+Если передан t (*testing.T), в t.Cleanup проверяется, что все ожидания выполнены и не было неожиданных вызовов, —
+даже если тестируемый код проглотил ошибку. Без t — вручную через ExpectationsWereMet.
+
+Драйвер не разбирает SQL и не хранит данные: для сценариев с настоящими таблицами остаётся Postgres из docker-compose
+(или Orders.NewMemory, если нужен только репозиторий).
+
+Пакет лежит в модуле DataBase; корневой модуль (packages/database_sql) импортировать его не может — модули разные.
+*/
+
+// T — то, что нужно от *testing.T
+type T interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}
+
+var ErrUnexpected = errors.New("fake: неожиданный вызов")
+
+type Mock struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	anyOrder     bool
+	unexpected   []string // неожиданные вызовы — их тоже показываем в конце теста
+}
+
+// New — *sql.DB на поддельном драйвере и Mock для ожиданий; t может быть nil
+func New(t T) (*sql.DB, *Mock) {
+	m := &Mock{}
+	db := sql.OpenDB(&connector{mock: m})
+
+	if t != nil {
+		t.Cleanup(func() {
+			t.Helper()
+			db.Close()
+			if err := m.ExpectationsWereMet(); err != nil {
+				t.Errorf("%v", err)
+			}
+		})
+	}
+
+	return db, m
+}
+
+// MatchInAnyOrder — ожидания сопоставляются в любом порядке (например, когда запросы идут из нескольких горутин)
+func (m *Mock) MatchInAnyOrder() *Mock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.anyOrder = true
+
+	return m
+}
+
+func (m *Mock) ExpectQuery(pattern string) *Expectation {
+	return m.expect(kindQuery, regexp.MustCompile(pattern))
+}
+
+func (m *Mock) ExpectExactQuery(query string) *Expectation {
+	return m.ExpectQuery("^" + regexp.QuoteMeta(normalize(query)) + "$")
+}
+
+func (m *Mock) ExpectExec(pattern string) *Expectation {
+	return m.expect(kindExec, regexp.MustCompile(pattern))
+}
+
+func (m *Mock) ExpectExactExec(query string) *Expectation {
+	return m.ExpectExec("^" + regexp.QuoteMeta(normalize(query)) + "$")
+}
+
+func (m *Mock) ExpectPrepare(pattern string) *Expectation {
+	return m.expect(kindPrepare, regexp.MustCompile(pattern))
+}
+
+func (m *Mock) ExpectBegin() *Expectation    { return m.expect(kindBegin, nil) }
+func (m *Mock) ExpectCommit() *Expectation   { return m.expect(kindCommit, nil) }
+func (m *Mock) ExpectRollback() *Expectation { return m.expect(kindRollback, nil) }
+
+func (m *Mock) expect(kind kind, pattern *regexp.Regexp) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &Expectation{kind: kind, pattern: pattern}
+	m.expectations = append(m.expectations, e)
+
+	return e
+}
+
+// ExpectationsWereMet — nil, если все ожидания выполнены и неожиданных вызовов не было
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var problems []string
+	for _, e := range m.expectations {
+		if !e.done {
+			problems = append(problems, "не выполнено: "+e.String())
+		}
+	}
+	for _, u := range m.unexpected {
+		problems = append(problems, "неожиданный вызов: "+u)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return errors.New("fake:\n\t" + strings.Join(problems, "\n\t"))
+}
+
+// match находит и помечает выполненным ожидание для вызова; Prepare без ожидания — (nil, nil)
+func (m *Mock) match(kind kind, query string, args []any) (*Expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	query = normalize(query)
+
+	// По порядку подходит только первое невыполненное ожидание, в любом порядке — любое
+	candidates := m.expectations
+	if !m.anyOrder {
+		candidates = nil
+		if next := m.next(); next != nil {
+			candidates = []*Expectation{next}
+		}
+	}
+
+	for _, e := range candidates {
+		if !e.done && e.matches(kind, query, args) {
+			e.done = true
+			return e, nil
+		}
+	}
+
+	// Prepare без ожидания — не ошибка
+	if kind == kindPrepare {
+		return nil, nil
+	}
+
+	call := describe(kind, query, args)
+	m.unexpected = append(m.unexpected, call)
+
+	if next := m.next(); next != nil {
+		return nil, fmt.Errorf("%w %s, ожидалось %s", ErrUnexpected, call, next)
+	}
+
+	return nil, fmt.Errorf("%w %s, ожиданий больше нет", ErrUnexpected, call)
+}
+
+func (m *Mock) next() *Expectation {
+	for _, e := range m.expectations {
+		if !e.done {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func normalize(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+func describe(kind kind, query string, args []any) string {
+	if query == "" {
+		return kind.String()
+	}
+
+	return fmt.Sprintf("%s %q %v", kind, query, args)
+}
@@ -0,0 +1,112 @@
+package Fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"learning/DataBase/Orders"
+)
+
+// recorder — T, который запоминает ошибки вместо того, чтобы валить тест: проверяем, что Fake.New(t) их сообщает
+type recorder struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Cleanup(fn func()) { r.cleanups = append(r.cleanups, fn) }
+
+func (r *recorder) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestUnmetExpectationFailsTest(t *testing.T) {
+	rec := &recorder{}
+	db, mock := New(rec)
+
+	mock.ExpectExec(`^DELETE FROM orders`).WillReturnResult(0, 1)
+	mock.ExpectExec(`^DELETE FROM order_items`).WillReturnResult(0, 1)
+
+	if _, err := db.Exec("DELETE FROM orders WHERE id = $1", 7); err != nil {
+		t.Fatal(err)
+	}
+	rec.finish()
+
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "не выполнено") || !strings.Contains(rec.errors[0], "order_items") {
+		t.Fatalf("ошибки в конце теста: %q, ожидалось невыполненное DELETE FROM order_items", rec.errors)
+	}
+}
+
+func TestOutOfOrderCall(t *testing.T) {
+	rec := &recorder{}
+	db, mock := New(rec)
+
+	mock.ExpectExec(`^INSERT INTO orders`).WillReturnResult(1, 1)
+	mock.ExpectExec(`^INSERT INTO order_items`).WillReturnResult(1, 1)
+
+	// Позиции раньше заказа: вызов получает ErrUnexpected, даже если код проглотит ошибку — её покажет Cleanup
+	_, err := db.Exec("INSERT INTO order_items (order_id) VALUES ($1)", 1)
+	if !errors.Is(err, ErrUnexpected) || !strings.Contains(err.Error(), "INSERT INTO orders") {
+		t.Fatalf("вызов не по порядку: %v, ожидалась ErrUnexpected с ожидаемым INSERT INTO orders", err)
+	}
+	rec.finish()
+
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "неожиданный вызов") {
+		t.Fatalf("ошибки в конце теста: %q", rec.errors)
+	}
+
+	// MatchInAnyOrder тот же порядок принимает
+	db, mock = New(t)
+	mock.MatchInAnyOrder()
+	mock.ExpectExec(`^INSERT INTO orders`).WillReturnResult(1, 1)
+	mock.ExpectExec(`^INSERT INTO order_items`).WillReturnResult(1, 1)
+	for _, q := range []string{"INSERT INTO order_items (order_id) VALUES ($1)", "INSERT INTO orders (user_id) VALUES ($1)"} {
+		if _, err := db.Exec(q, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Orders.SQL на Fake: переход, который не пропустил guard, откатывает транзакцию и ничего не пишет
+func TestOrdersTransitionRollsBackOnGuard(t *testing.T) {
+	ctx := context.Background()
+	db, mock := New(t)
+
+	repo, err := Orders.NewPostgres(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM orders WHERE id = \$1 FOR UPDATE$`).WithArgs(7).
+		WillReturnRows(orderRows().Add(7, 2, 0, "pending", now, now))
+	mock.ExpectRollback()
+	expectPayment(mock, now)
+
+	_, err = repo.Transition(ctx, 7, Orders.StatusPaid, Orders.Meta{Actor: "example", Reason: "оплата"})
+	var transitionErr *Orders.TransitionError
+	if !errors.As(err, &transitionErr) {
+		t.Fatalf("оплата пустого заказа: %v, ожидалась *Orders.TransitionError", err)
+	}
+
+	o, err := repo.Transition(ctx, 7, Orders.StatusPaid, Orders.Meta{Actor: "example", Reason: "оплата"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != Orders.StatusPaid || !o.UpdatedAt.After(now) {
+		t.Fatalf("после оплаты %+v", o)
+	}
+}
@@ -0,0 +1,96 @@
+package Fake
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+)
+
+// Rows — результат запроса: колонки и строки
+//
+//	Fake.NewRows("id", "status").Add(1, "paid").Add(2, "pending")
+type Rows struct {
+	columns []string
+	types   []string // DatabaseTypeName колонок; nil — пустые
+	rows    [][]driver.Value
+	errAt   map[int]error // ошибка вместо строки с этим номером — как обрыв соединения посреди чтения
+}
+
+func NewRows(columns ...string) *Rows {
+	return &Rows{columns: columns}
+}
+
+// Add — строка; значения приводятся к типам драйвера (int -> int64), nil — NULL
+func (r *Rows) Add(values ...any) *Rows {
+	if len(values) != len(r.columns) {
+		panic(fmt.Sprintf("fake: строка из %d значений, а колонок %d", len(values), len(r.columns)))
+	}
+
+	row := make([]driver.Value, len(values))
+	for i, v := range values {
+		dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+		if err != nil {
+			panic(fmt.Sprintf("fake: значение %v: %v", v, err))
+		}
+		row[i] = dv
+	}
+	r.rows = append(r.rows, row)
+
+	return r
+}
+
+// Types — типы колонок, как их назвал бы драйвер ("INT4", "NUMERIC", "TIMESTAMP"): rows.ColumnTypes()[i].DatabaseTypeName()
+func (r *Rows) Types(types ...string) *Rows {
+	if len(types) != len(r.columns) {
+		panic(fmt.Sprintf("fake: %d типов, а колонок %d", len(types), len(r.columns)))
+	}
+	r.types = types
+
+	return r
+}
+
+// RowError — при чтении строки с номером n (с 0) rows.Next вернёт false, а rows.Err — err
+func (r *Rows) RowError(n int, err error) *Rows {
+	if r.errAt == nil {
+		r.errAt = make(map[int]error)
+	}
+	r.errAt[n] = err
+
+	return r
+}
+
+// cursor — чтение одного экземпляра результата; Rows можно вернуть несколько раз, у каждого вызова свой курсор
+type cursor struct {
+	rows *Rows
+	pos  int
+}
+
+func (c *cursor) Columns() []string {
+	return c.rows.columns
+}
+
+func (c *cursor) ColumnTypeDatabaseTypeName(i int) string {
+	if c.rows.types == nil {
+		return ""
+	}
+
+	return c.rows.types[i]
+}
+
+func (c *cursor) Close() error {
+	return nil
+}
+
+func (c *cursor) Next(dest []driver.Value) error {
+	if err, ok := c.rows.errAt[c.pos]; ok {
+		return err
+	}
+	if c.pos >= len(c.rows.rows) {
+		return io.EOF
+	}
+
+	copy(dest, c.rows.rows[c.pos])
+	c.pos++
+
+	return nil
+}
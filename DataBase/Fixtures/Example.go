@@ -0,0 +1,80 @@
+package Fixtures
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"learning/DataBase/Fake"
+)
+
+/*
+В тесте с настоящей базой (docker-compose или SQLite-файл после dbctl up):
+
+	func TestReport(t *testing.T) {
+		set, err := Fixtures.Shop()
+		...
+		if err := Fixtures.Load(ctx, db, set, Fixtures.Options{Now: fixedNow}); err != nil {
+			t.Fatal(err)
+		}
+		order, _ := repo.Get(ctx, int(Fixtures.ID("dmitry_sport")))
+		...
+	}
+*/
+
+// ExamplePlan — что сделает Load с небольшим набором из JSON
+func ExamplePlan() {
+	set, err := Parse("orders.json", []byte(`{
+		"order_items": {
+			"first_iphone": {"order_id": "{{ orders.first }}", "product_id": "{{ products.iphone15 }}", "quantity": 2, "price": 999.99}
+		},
+		"products": {"iphone15": {"id": 1, "name": "iPhone 15", "price": 999.99}},
+		"orders":   {"first": {"user_id": 1, "total_amount": 1999.98, "status": "pending", "created_at": "{{ now-1h }}"}}
+	}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	stmts, err := Plan(set, Options{Now: now})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, st := range stmts {
+		fmt.Println(st.SQL, st.Args)
+	}
+	fmt.Println("id заказа first:", ID("first"))
+}
+
+// ExampleLoad — загрузка фикстур магазина одной транзакцией (на Fake-драйвере, чтобы было видно запросы)
+func ExampleLoad() {
+	set, err := Shop()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmts, err := Plan(set, Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, mock := Fake.New(nil)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	for _, st := range stmts {
+		mock.ExpectExactExec(st.SQL).WillReturnResult(0, 1)
+	}
+	mock.ExpectCommit()
+
+	if err := Load(context.Background(), db, set, Options{}); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("запросов:", len(stmts))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,222 @@
+package Fixtures
+
+import (
+	"embed"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+/*
+Фикстуры — тестовые данные для схемы заказов, описанные файлом, а не набранные руками в psql перед запуском примеров.
+
+	# data/shop.yaml
+	products:
+	  iphone15:
+	    name: iPhone 15
+	    price: 999.99
+	    category_id: "{{ categories.electronics }}"
+	order_items:
+	  first_iphone:
+	    order_id: "{{ orders.first }}"
+	    product_id: "{{ products.iphone15 }}"
+	    quantity: 2
+	    price: 999.99
+	    created_at: "{{ now-1h }}"
+
+Файл — таблицы, в таблице — записи под именами (label), в записи — колонки. JSON устроен так же: {"products": {"iphone15": {...}}}.
+YAML поддерживается в том подмножестве, которого хватает фикстурам: вложенные mapping'и и скаляры
+(строки в кавычках и без, числа, true/false, null, комментарии #); списков и якорей нет.
+
+Шаблоны {{ ... }}:
+	- {{ products.iphone15 }} — id записи iphone15 из таблицы products. По ссылкам определяется порядок вставки таблиц:
+	  products раньше order_items, сколько бы файлов ни было и в каком бы порядке ни шли таблицы;
+	- {{ now }}, {{ now-1h }}, {{ now+30m }}, {{ now-7d }} — время относительно момента загрузки (Options.Now);
+	- шаблон внутри строки ("заказ {{ orders.first }}") подставляется текстом.
+Шаблон-значение целиком в YAML берётся в кавычки: без них { начинает flow-mapping.
+
+id записей детерминированные: если колонка id не задана явно, это ID(label) — crc32 от имени записи.
+Один и тот же файл всегда даёт одни и те же id, тест может сослаться на Fixtures.ID("iphone15") без запроса к базе.
+Явный id (как в data/shop.yaml — чтобы совпасть с init.sql и примерами, где user_id = 1) имеет приоритет.
+
+Загрузка (Load) — одна транзакция: таблицы из фикстур очищаются и заполняются заново; упало на середине — база как была.
+*/
+
+//go:embed data/*.yaml
+var Files embed.FS
+
+// Shop — фикстуры магазина из data/: категории, пользователи, товары, заказы
+func Shop() (*Set, error) {
+	return ParseFS(Files, "data/*.yaml")
+}
+
+// Set — записи из одного или нескольких файлов; порядок таблиц, записей и колонок — как в файлах
+type Set struct {
+	Tables []*Table
+}
+
+type Table struct {
+	Name string
+	Rows []*Row
+}
+
+type Row struct {
+	Label   string
+	Columns []string       // в порядке файла
+	Values  map[string]any // string, int64, float64, bool, nil; строки могут содержать шаблоны
+	source  string         // файл — для сообщений об ошибках
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ID — детерминированный id записи с именем label, если id не задан явно
+func ID(label string) int64 {
+	// до миллиона: после загрузки последовательность id сдвигается за максимум, и места для обычных INSERT остаётся много
+	return int64(crc32.ChecksumIEEE([]byte(label))%1_000_000) + 1
+}
+
+// Table возвращает таблицу по имени или nil
+func (s *Set) Table(name string) *Table {
+	for _, t := range s.Tables {
+		if t.Name == name {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// Merge добавляет записи из other; одинаковая запись (таблица + label) в двух файлах — ошибка
+func (s *Set) Merge(other *Set) error {
+	for _, ot := range other.Tables {
+		t := s.Table(ot.Name)
+		if t == nil {
+			t = &Table{Name: ot.Name}
+			s.Tables = append(s.Tables, t)
+		}
+
+		for _, r := range ot.Rows {
+			if dup := t.row(r.Label); dup != nil {
+				return fmt.Errorf("fixtures: %s.%s описана дважды: %s и %s", t.Name, r.Label, dup.source, r.source)
+			}
+			t.Rows = append(t.Rows, r)
+		}
+	}
+
+	return nil
+}
+
+func (t *Table) row(label string) *Row {
+	for _, r := range t.Rows {
+		if r.Label == label {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// Parse разбирает один файл; формат — по расширению: .json, .yaml, .yml
+func Parse(name string, data []byte) (*Set, error) {
+	var (
+		doc *mapping
+		err error
+	)
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		doc, err = parseJSON(data)
+	case ".yaml", ".yml":
+		doc, err = parseYAML(data)
+	default:
+		return nil, fmt.Errorf("fixtures: %s: неизвестный формат, нужен .json, .yaml или .yml", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: %s: %w", name, err)
+	}
+
+	set, err := fromDocument(name, doc)
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: %s: %w", name, err)
+	}
+
+	return set, nil
+}
+
+// ParseFS читает файлы по шаблонам (fs.Glob) в алфавитном порядке и объединяет их
+func ParseFS(fsys fs.FS, patterns ...string) (*Set, error) {
+	var names []string
+	for _, p := range patterns {
+		matches, err := fs.Glob(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("fixtures: %w", err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("fixtures: по шаблону %q файлов нет", p)
+		}
+		names = append(names, matches...)
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	set := &Set{}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("fixtures: %w", err)
+		}
+
+		part, err := Parse(name, data)
+		if err != nil {
+			return nil, err
+		}
+		if err := set.Merge(part); err != nil {
+			return nil, err
+		}
+	}
+
+	return set, nil
+}
+
+// fromDocument проверяет форму документа: таблица -> запись -> колонка -> скаляр
+func fromDocument(source string, doc *mapping) (*Set, error) {
+	set := &Set{}
+
+	for _, table := range doc.keys {
+		if !identRe.MatchString(table) {
+			return nil, fmt.Errorf("имя таблицы %q", table)
+		}
+		rows, ok := doc.values[table].(*mapping)
+		if !ok {
+			return nil, fmt.Errorf("%s: ожидались записи вида label: {колонка: значение}", table)
+		}
+
+		t := &Table{Name: table}
+		for _, label := range rows.keys {
+			cols, ok := rows.values[label].(*mapping)
+			if !ok {
+				return nil, fmt.Errorf("%s.%s: ожидались колонки", table, label)
+			}
+
+			r := &Row{Label: label, Columns: cols.keys, Values: make(map[string]any, len(cols.keys)), source: source}
+			for _, col := range cols.keys {
+				if !identRe.MatchString(col) {
+					return nil, fmt.Errorf("%s.%s: имя колонки %q", table, label, col)
+				}
+				v := cols.values[col]
+				if _, nested := v.(*mapping); nested {
+					return nil, fmt.Errorf("%s.%s.%s: значение должно быть скаляром", table, label, col)
+				}
+				r.Values[col] = v
+			}
+			t.Rows = append(t.Rows, r)
+		}
+		set.Tables = append(set.Tables, t)
+	}
+
+	return set, nil
+}
@@ -0,0 +1,199 @@
+package Fixtures
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Query"
+	"learning/DataBase/Transactions"
+)
+
+type Options struct {
+	Driver string    // "postgres" (по умолчанию) или "sqlite": плейсхолдеры, очистка таблиц, сдвиг последовательностей
+	Now    time.Time // момент для {{ now }}; по умолчанию текущее время (UTC, до секунды), для повторяемых тестов — фиксированное
+}
+
+// Statement — один запрос загрузки
+type Statement struct {
+	SQL  string
+	Args []any
+}
+
+/*
+Load очищает таблицы из set и заполняет их записями — в одной транзакции.
+
+Порядок: таблицы сортируются по ссылкам ({{ products.iphone15 }} в order_items — products раньше), при равенстве — как в файлах;
+очищаются в обратном порядке. Записи внутри таблицы вставляются по порядку файла.
+
+Postgres: TRUNCATE ... RESTART IDENTITY CASCADE — CASCADE очищает и таблицы, которые ссылаются на загружаемые
+(order_status_history при загрузке orders), иначе TRUNCATE отказался бы. После вставки последовательности id
+сдвигаются за максимальный id, чтобы обычный INSERT не наткнулся на id из фикстур.
+SQLite: DELETE FROM; AUTOINCREMENT сам помнит максимальный вставленный id.
+*/
+func Load(ctx context.Context, db *sql.DB, set *Set, opts Options) error {
+	stmts, err := Plan(set, opts)
+	if err != nil {
+		return err
+	}
+
+	return Transactions.New(db).WithTx(ctx, Transactions.Options{}, func(ctx context.Context, tx *sql.Tx) error {
+		for _, st := range stmts {
+			if _, err := tx.ExecContext(ctx, st.SQL, st.Args...); err != nil {
+				return fmt.Errorf("fixtures: %s: %w", st.SQL, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Plan — запросы, которые выполнит Load, без обращения к базе: проверить фикстуры или посмотреть, что будет сделано
+func Plan(set *Set, opts Options) ([]Statement, error) {
+	driver := opts.Driver
+	if driver == "" {
+		driver = "postgres"
+	}
+
+	dialect, err := Dialect.For(driver)
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: %w", err)
+	}
+	postgres := dialect == Dialect.Postgres
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now().UTC().Truncate(time.Second)
+	}
+
+	tables, err := order(set)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, nil
+	}
+
+	var stmts []Statement
+
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.Name
+	}
+	if postgres {
+		stmts = append(stmts, Statement{SQL: "TRUNCATE " + strings.Join(names, ", ") + " RESTART IDENTITY CASCADE"})
+	} else {
+		for _, name := range slices.Backward(names) {
+			stmts = append(stmts, Statement{SQL: "DELETE FROM " + name})
+		}
+	}
+
+	rs := &resolver{set: set, now: now}
+	for _, t := range tables {
+		if err := checkIDs(t); err != nil {
+			return nil, err
+		}
+
+		for _, r := range t.Rows {
+			values := make(map[string]any, len(r.Columns)+1)
+			for _, col := range r.Columns {
+				v, err := rs.value(r.Values[col])
+				if err != nil {
+					return nil, fmt.Errorf("fixtures: %s.%s.%s (%s): %w", t.Name, r.Label, col, r.source, err)
+				}
+				switch tv := v.(type) {
+				case time.Time:
+					v = dialect.Time(tv)
+				case bool:
+					v = dialect.Bool(tv)
+				}
+				values[col] = v
+			}
+			if _, ok := values["id"]; !ok {
+				values["id"] = ID(r.Label)
+			}
+
+			q, args, err := Query.Insert(t.Name).SetMap(values).ToSQL(dialect)
+			if err != nil {
+				return nil, fmt.Errorf("fixtures: %s.%s: %w", t.Name, r.Label, err)
+			}
+			stmts = append(stmts, Statement{SQL: q, Args: args})
+		}
+
+		if q := dialect.ResetSequence(t.Name); q != "" {
+			stmts = append(stmts, Statement{SQL: q})
+		}
+	}
+
+	return stmts, nil
+}
+
+// checkIDs — два label с одинаковым crc32 (или одинаковые явные id) дали бы ошибку уникальности посреди загрузки
+func checkIDs(t *Table) error {
+	seen := make(map[int64]string, len(t.Rows))
+	for _, r := range t.Rows {
+		id, err := r.id()
+		if err != nil {
+			return fmt.Errorf("fixtures: %s.%w", t.Name, err)
+		}
+		if other, dup := seen[id]; dup {
+			return fmt.Errorf("fixtures: у %s.%s и %s.%s одинаковый id %d — задайте id явно", t.Name, other, t.Name, r.Label, id)
+		}
+		seen[id] = r.Label
+	}
+
+	return nil
+}
+
+// order сортирует таблицы так, чтобы таблица шла после тех, на которые ссылается (алгоритм Кана с порядком файлов)
+func order(set *Set) ([]*Table, error) {
+	deps := make(map[string][]string, len(set.Tables))
+	for _, t := range set.Tables {
+		for _, r := range t.Rows {
+			for _, ref := range r.refs() {
+				if ref.table != t.Name && set.Table(ref.table) != nil && !slices.Contains(deps[t.Name], ref.table) {
+					deps[t.Name] = append(deps[t.Name], ref.table)
+				}
+			}
+		}
+	}
+
+	var (
+		out  []*Table
+		done = make(map[string]bool, len(set.Tables))
+	)
+	for len(out) < len(set.Tables) {
+		progress := false
+		for _, t := range set.Tables {
+			if done[t.Name] {
+				continue
+			}
+			ready := true
+			for _, d := range deps[t.Name] {
+				ready = ready && done[d]
+			}
+			if ready {
+				out = append(out, t)
+				done[t.Name] = true
+				progress = true
+				break // снова с начала: так сохраняется порядок файлов среди готовых таблиц
+			}
+		}
+
+		if !progress {
+			var rest []string
+			for _, t := range set.Tables {
+				if !done[t.Name] {
+					rest = append(rest, t.Name)
+				}
+			}
+			return nil, fmt.Errorf("fixtures: циклические ссылки между таблицами %s", strings.Join(rest, ", "))
+		}
+	}
+
+	return out, nil
+}
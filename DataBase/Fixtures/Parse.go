@@ -0,0 +1,284 @@
+package Fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// mapping — объект JSON / mapping YAML с сохранённым порядком ключей (map в Go его теряет)
+type mapping struct {
+	keys   []string
+	values map[string]any // скаляр или *mapping
+}
+
+func newMapping() *mapping {
+	return &mapping{values: make(map[string]any)}
+}
+
+func (m *mapping) set(key string, v any) error {
+	if _, dup := m.values[key]; dup {
+		return fmt.Errorf("ключ %q повторяется", key)
+	}
+	m.keys = append(m.keys, key)
+	m.values[key] = v
+
+	return nil
+}
+
+// parseJSON читает документ по токенам, чтобы сохранить порядок ключей
+func parseJSON(data []byte) (*mapping, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	v, err := jsonValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, errors.New("лишние данные после документа")
+	}
+
+	m, ok := v.(*mapping)
+	if !ok {
+		return nil, errors.New("документ должен быть объектом")
+	}
+
+	return m, nil
+}
+
+func jsonValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t != '{' {
+			return nil, errors.New("массивы не поддерживаются")
+		}
+		m := newMapping()
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := jsonValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			if err := m.set(key.(string), v); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := dec.Token(); err != nil { // закрывающая }
+			return nil, err
+		}
+		return m, nil
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n, nil
+		}
+		return t.Float64()
+	default: // string, bool, nil
+		return t, nil
+	}
+}
+
+type yamlLine struct {
+	no     int
+	indent int
+	text   string
+}
+
+// parseYAML — подмножество YAML: вложенные mapping'и через отступы и скаляры
+func parseYAML(data []byte) (*mapping, error) {
+	var lines []yamlLine
+
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		text := strings.TrimLeft(raw, " ")
+		indent := len(raw) - len(text)
+
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("строка %d: отступ табуляцией", i+1)
+		}
+		text = strings.TrimRight(stripComment(text), " \t")
+		if text == "" || text == "---" {
+			continue
+		}
+		if text == "-" || strings.HasPrefix(text, "- ") {
+			return nil, fmt.Errorf("строка %d: списки не поддерживаются", i+1)
+		}
+
+		lines = append(lines, yamlLine{no: i + 1, indent: indent, text: text})
+	}
+
+	if len(lines) == 0 {
+		return newMapping(), nil
+	}
+
+	p := &yamlParser{lines: lines}
+	m, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(lines) {
+		return nil, fmt.Errorf("строка %d: неожиданный отступ", lines[p.pos].no)
+	}
+
+	return m, nil
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// block читает ключи с отступом indent; вложенный блок — строки с большим отступом после "key:"
+func (p *yamlParser) block(indent int) (*mapping, error) {
+	m := newMapping()
+
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("строка %d: неожиданный отступ", l.no)
+		}
+
+		key, rest, err := splitKey(l.text)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", l.no, err)
+		}
+		p.pos++
+
+		var v any
+		switch {
+		case rest != "":
+			if v, err = yamlScalar(rest); err != nil {
+				return nil, fmt.Errorf("строка %d: %w", l.no, err)
+			}
+		case p.pos < len(p.lines) && p.lines[p.pos].indent > indent:
+			if v, err = p.block(p.lines[p.pos].indent); err != nil {
+				return nil, err
+			}
+		default:
+			v = nil // "key:" без значения — null
+		}
+
+		if err := m.set(key, v); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", l.no, err)
+		}
+	}
+
+	return m, nil
+}
+
+// splitKey делит "key: value" на ключ и значение; ключ может быть в кавычках
+func splitKey(text string) (key, rest string, err error) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end < 0 {
+			return "", "", errors.New("незакрытая кавычка в ключе")
+		}
+		k, err := yamlScalar(text[:end+1])
+		if err != nil {
+			return "", "", err
+		}
+		after := text[end+1:]
+		if !strings.HasPrefix(after, ":") {
+			return "", "", errors.New("после ключа ожидалось ':'")
+		}
+		return k.(string), strings.TrimSpace(after[1:]), nil
+	}
+
+	if i := strings.Index(text, ": "); i >= 0 {
+		return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+2:]), nil
+	}
+	if strings.HasSuffix(text, ":") {
+		return strings.TrimSpace(text[:len(text)-1]), "", nil
+	}
+
+	return "", "", fmt.Errorf("ожидалось 'ключ: значение', а не %q", text)
+}
+
+func yamlScalar(s string) (any, error) {
+	switch s[0] {
+	case '"':
+		if closingQuote(s) != len(s)-1 {
+			return nil, fmt.Errorf("лишнее после строки %s", s)
+		}
+		return strconv.Unquote(s)
+	case '\'':
+		if closingQuote(s) != len(s)-1 {
+			return nil, fmt.Errorf("лишнее после строки %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case '{', '[', '&', '*', '|', '>', '!':
+		return nil, fmt.Errorf("%q: flow-коллекции, якоря, теги и многострочные строки не поддерживаются (шаблон {{ }} — в кавычки)", s)
+	}
+
+	switch s {
+	case "null", "~", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+
+	return s, nil
+}
+
+// closingQuote — индекс кавычки, закрывающей строку в начале s, или -1
+func closingQuote(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case q == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// stripComment убирает # комментарий: в начале строки или после пробела, но не внутри кавычек
+func stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' || c == '\'' && quote == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || text[i-1] == ' ' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' '):
+			return text[:i]
+		}
+	}
+
+	return text
+}
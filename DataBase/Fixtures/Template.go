@@ -0,0 +1,149 @@
+package Fixtures
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	templateRe = regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`)
+	refRe      = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\.([A-Za-z0-9_-]+)$`)
+	nowRe      = regexp.MustCompile(`^now\s*(?:([+-])\s*(\S+))?$`)
+)
+
+type ref struct {
+	table, label string
+}
+
+// refs — ссылки на другие записи в значениях строки
+func (r *Row) refs() []ref {
+	var out []ref
+	for _, col := range r.Columns {
+		s, ok := r.Values[col].(string)
+		if !ok {
+			continue
+		}
+		for _, m := range templateRe.FindAllStringSubmatch(s, -1) {
+			if p := refRe.FindStringSubmatch(m[1]); p != nil {
+				out = append(out, ref{p[1], p[2]})
+			}
+		}
+	}
+
+	return out
+}
+
+// resolver подставляет шаблоны: ссылки — id записей, now — время загрузки
+type resolver struct {
+	set *Set
+	now time.Time
+}
+
+func (rs *resolver) value(v any) (any, error) {
+	s, ok := v.(string)
+	if !ok {
+		return v, nil
+	}
+
+	// значение целиком — шаблон: результат своего типа (int64, time.Time)
+	if m := templateRe.FindStringSubmatchIndex(s); m != nil && m[0] == 0 && m[1] == len(s) {
+		return rs.eval(s[m[2]:m[3]])
+	}
+
+	var firstErr error
+	out := templateRe.ReplaceAllStringFunc(s, func(t string) string {
+		v, err := rs.eval(templateRe.FindStringSubmatch(t)[1])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return t
+		}
+		if tm, ok := v.(time.Time); ok {
+			return tm.Format(time.RFC3339)
+		}
+		return fmt.Sprint(v)
+	})
+
+	return out, firstErr
+}
+
+func (rs *resolver) eval(expr string) (any, error) {
+	if m := nowRe.FindStringSubmatch(expr); m != nil {
+		if m[1] == "" {
+			return rs.now, nil
+		}
+		d, err := parseOffset(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("{{ %s }}: %w", expr, err)
+		}
+		if m[1] == "-" {
+			d = -d
+		}
+		return rs.now.Add(d), nil
+	}
+
+	if m := refRe.FindStringSubmatch(expr); m != nil {
+		return rs.set.id(m[1], m[2])
+	}
+
+	return nil, fmt.Errorf("{{ %s }}: ожидалась ссылка table.label или now±длительность", expr)
+}
+
+// parseOffset — time.ParseDuration плюс дни: 7d, 1d12h
+func parseOffset(s string) (time.Duration, error) {
+	var days time.Duration
+	if i := strings.IndexByte(s, 'd'); i >= 0 {
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, fmt.Errorf("длительность %q", s)
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		s = s[i+1:]
+		if s == "" {
+			return days, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+
+	return days + d, nil
+}
+
+// id — id записи table.label: явная колонка id или ID(label)
+func (s *Set) id(table, label string) (int64, error) {
+	t := s.Table(table)
+	if t == nil {
+		return 0, fmt.Errorf("ссылка на %s.%s: таблицы %s нет в фикстурах", table, label, table)
+	}
+	r := t.row(label)
+	if r == nil {
+		return 0, fmt.Errorf("ссылка на %s.%s: записи %s нет", table, label, label)
+	}
+
+	return r.id()
+}
+
+func (r *Row) id() (int64, error) {
+	v, ok := r.Values["id"]
+	if !ok {
+		return ID(r.Label), nil
+	}
+
+	switch id := v.(type) {
+	case int64:
+		return id, nil
+	case string:
+		if n, err := strconv.ParseInt(id, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%s: id должен быть целым числом, а не %v", r.Label, v)
+}
@@ -0,0 +1,286 @@
+package Instrument
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+/*
+conn, stmt и tx повторяют необязательные интерфейсы исходного драйвера: если у него есть ExecContext — вызываем его,
+если есть только старый Exec — его, если нет ничего — driver.ErrSkip, и database/sql сам подготовит запрос через Prepare.
+Так обёртка не меняет поведение драйвера, а только наблюдает.
+*/
+
+type conn struct {
+	c driver.Conn
+	o *Options
+}
+
+var (
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.SessionResetter    = (*conn)(nil)
+	_ driver.Validator          = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := time.Now()
+
+	var s driver.Stmt
+	var err error
+	if pc, ok := c.c.(driver.ConnPrepareContext); ok {
+		s, err = pc.PrepareContext(ctx, query)
+	} else {
+		s, err = c.c.Prepare(query)
+	}
+
+	c.o.emit(ctx, Event{Op: OpPrepare, Query: query, Err: err}, start)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stmt{s: s, query: query, o: c.o}, nil
+}
+
+func (c *conn) Close() error {
+	return c.c.Close()
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+
+	var t driver.Tx
+	var err error
+	if bt, ok := c.c.(driver.ConnBeginTx); ok {
+		t, err = bt.BeginTx(ctx, opts)
+	} else {
+		// Как database/sql для таких драйверов: уровень изоляции и read-only без BeginTx не передать
+		if opts.Isolation != driver.IsolationLevel(0) || opts.ReadOnly {
+			return nil, errors.New("instrument: драйвер не поддерживает уровни изоляции и read-only транзакции")
+		}
+		t, err = c.c.Begin()
+	}
+
+	c.o.emit(ctx, Event{Op: OpBegin, Err: err}, start)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tx{t: t, ctx: ctx, o: c.o}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var res driver.Result
+	var err error
+	switch ec := c.c.(type) {
+	case driver.ExecerContext:
+		res, err = ec.ExecContext(ctx, query, args)
+	case driver.Execer: // старые драйверы без ExecContext
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			res, err = ec.Exec(query, values)
+		}
+	default:
+		return nil, driver.ErrSkip
+	}
+
+	c.o.emit(ctx, Event{Op: OpExec, Query: query, Args: args, Err: err, RowsAffected: rowsAffected(res, err)}, start)
+
+	return res, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var rows driver.Rows
+	var err error
+	switch qc := c.c.(type) {
+	case driver.QueryerContext:
+		rows, err = qc.QueryContext(ctx, query, args)
+	case driver.Queryer: // старые драйверы без QueryContext
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			rows, err = qc.Query(query, values)
+		}
+	default:
+		return nil, driver.ErrSkip
+	}
+
+	c.o.emit(ctx, Event{Op: OpQuery, Query: query, Args: args, Err: err, RowsAffected: -1}, start)
+
+	return rows, err
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.c.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.c.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.c.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.c.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+type stmt struct {
+	s     driver.Stmt
+	query string
+	o     *Options
+}
+
+func (s *stmt) Close() error {
+	return s.s.Close()
+}
+
+func (s *stmt) NumInput() int {
+	return s.s.NumInput()
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valuesToNamed(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valuesToNamed(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var res driver.Result
+	var err error
+	if ec, ok := s.s.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			res, err = s.s.Exec(values)
+		}
+	}
+
+	s.o.emit(ctx, Event{Op: OpStmtExec, Query: s.query, Args: args, Err: err, RowsAffected: rowsAffected(res, err)}, start)
+
+	return res, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var rows driver.Rows
+	var err error
+	if qc, ok := s.s.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			rows, err = s.s.Query(values)
+		}
+	}
+
+	s.o.emit(ctx, Event{Op: OpStmtQuery, Query: s.query, Args: args, Err: err, RowsAffected: -1}, start)
+
+	return rows, err
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.s.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+type tx struct {
+	t   driver.Tx
+	ctx context.Context // ctx из BeginTx — у Commit и Rollback своего нет
+	o   *Options
+}
+
+func (t *tx) Commit() error {
+	start := time.Now()
+	err := t.t.Commit()
+	t.o.emit(t.ctx, Event{Op: OpCommit, Err: err}, start)
+
+	return err
+}
+
+func (t *tx) Rollback() error {
+	start := time.Now()
+	err := t.t.Rollback()
+	t.o.emit(t.ctx, Event{Op: OpRollback, Err: err}, start)
+
+	return err
+}
+
+var errNamedArgs = errors.New("instrument: драйвер не поддерживает именованные параметры")
+
+func namedToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		if a.Name != "" {
+			return nil, errNamedArgs
+		}
+		values[i] = a.Value
+	}
+
+	return values, nil
+}
+
+func valuesToNamed(values []driver.Value) []driver.NamedValue {
+	args := make([]driver.NamedValue, len(values))
+	for i, v := range values {
+		args[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+
+	return args
+}
+
+func rowsAffected(res driver.Result, err error) int64 {
+	if err != nil || res == nil {
+		return -1
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
@@ -0,0 +1,153 @@
+package Instrument
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+/*
+Обёртка над database/sql драйвером: видно, какой SQL на самом деле уходит в базу и сколько он выполняется.
+
+Драйвер "logged-postgres" регистрируется при импорте пакета — это lib/pq с логированием, код вызова не меняется:
+
+	db, err := sql.Open("logged-postgres", Connection.DSN())
+
+(или DB_DRIVER=logged-postgres для Connection.Open, пример — Connection.ExampleLoggedPostgres). Любой другой драйвер оборачивается так же:
+
+	sql.Register("logged-sqlite", Instrument.Wrap(&sqlite.Driver{}, &Instrument.Options{SlowThreshold: 50 * time.Millisecond}))
+	db := sql.OpenDB(Instrument.WrapConnector(connector, nil))
+
+Что перехватывается: Exec, Query, Prepare, выполнение подготовленных запросов, Begin, Commit, Rollback.
+На каждую операцию получается Event — длительность, ошибка, аргументы, число изменённых строк; дальше он:
+	- пишется в лог: Level = LogAll (по умолчанию) — всё, LogSlow — только медленные и ошибки, LogOff — ничего;
+	- помечается Slow, если дольше SlowThreshold (по умолчанию 200ms), в логе такие строки начинаются с SLOW;
+	- попадает в Metrics — счётчики и время по каждому тексту запроса;
+	- передаётся в OnEvent вместе с ctx вызова — отсюда можно писать спаны трассировки.
+
+Аргументы в лог по умолчанию не попадают как есть: числа, bool, время и NULL видны, строки и []byte — только длина
+(пароли, email, токены не должны оседать в логах). ShowArgs = true показывает всё — только для локальной отладки.
+
+Длительность Query — время до получения первого ответа, чтение строк (rows.Next) в неё не входит:
+driver.Rows не оборачивается, чтобы не потерять у него необязательные интерфейсы (ColumnTypes и т.п.).
+*/
+
+type Level int
+
+const (
+	LogAll Level = iota
+	LogSlow
+	LogOff
+)
+
+const DefaultSlowThreshold = 200 * time.Millisecond
+
+type Options struct {
+	Logger *log.Logger // nil — log.Default()
+	Level  Level
+
+	SlowThreshold time.Duration // 0 — DefaultSlowThreshold, отрицательное — не отмечать медленные
+
+	ShowArgs bool               // писать значения аргументов как есть
+	Redact   func(v any) string // как показывать аргумент, если ShowArgs выключен; по умолчанию RedactValue
+
+	Metrics *Metrics
+	OnEvent func(ctx context.Context, e Event)
+}
+
+// Default — настройки драйвера "logged-postgres" и обёрток, которым передали nil; менять до sql.Open
+var Default = &Options{Metrics: NewMetrics()}
+
+func init() {
+	sql.Register("logged-postgres", Wrap(&pq.Driver{}, nil))
+}
+
+// Wrap — драйвер, который пишет события по opts; nil — Default
+func Wrap(d driver.Driver, opts *Options) driver.Driver {
+	return &wrappedDriver{d: d, o: opts}
+}
+
+// WrapConnector — то же для sql.OpenDB(connector)
+func WrapConnector(c driver.Connector, opts *Options) driver.Connector {
+	return &connector{c: c, d: &wrappedDriver{d: c.Driver(), o: opts}}
+}
+
+type wrappedDriver struct {
+	d driver.Driver
+	o *Options
+}
+
+func (w *wrappedDriver) options() *Options {
+	if w.o != nil {
+		return w.o
+	}
+
+	return Default
+}
+
+func (w *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := w.d.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{c: c, o: w.options()}, nil
+}
+
+// OpenConnector — database/sql предпочитает его Open; если у исходного драйвера коннектора нет, подключаемся через Open
+func (w *wrappedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := w.d.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &connector{c: c, d: w}, nil
+	}
+
+	return &connector{c: dsnConnector{name: name, d: w.d}, d: w}, nil
+}
+
+type connector struct {
+	c driver.Connector
+	d *wrappedDriver
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{c: dc, o: c.d.options()}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.d
+}
+
+// Close — sql.DB.Close закрывает коннектор, если он это умеет
+func (c *connector) Close() error {
+	if closer, ok := c.c.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+type dsnConnector struct {
+	name string
+	d    driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.d.Open(c.name)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.d
+}
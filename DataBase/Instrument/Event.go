@@ -0,0 +1,162 @@
+package Instrument
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+type Op string
+
+const (
+	OpExec      Op = "exec"
+	OpQuery     Op = "query"
+	OpPrepare   Op = "prepare"
+	OpStmtExec  Op = "stmt.exec"
+	OpStmtQuery Op = "stmt.query"
+	OpBegin     Op = "begin"
+	OpCommit    Op = "commit"
+	OpRollback  Op = "rollback"
+)
+
+// Event — одна операция драйвера
+type Event struct {
+	Op           Op
+	Query        string // пусто для begin/commit/rollback
+	Args         []driver.NamedValue
+	Start        time.Time
+	Duration     time.Duration
+	Err          error
+	Slow         bool
+	RowsAffected int64 // только для exec; -1 — неизвестно
+}
+
+// Statement — запрос, а не управление транзакцией; по таким событиям считаются Metrics
+func (e Event) Statement() bool {
+	return e.Query != "" && e.Op != OpPrepare
+}
+
+func (o *Options) emit(ctx context.Context, e Event, start time.Time) {
+	// ErrSkip — не ошибка, а просьба database/sql пойти другим путём; этот же запрос придёт ещё раз
+	if errors.Is(e.Err, driver.ErrSkip) {
+		return
+	}
+
+	e.Start = start
+	e.Duration = time.Since(start)
+	if threshold := o.slowThreshold(); threshold > 0 && e.Duration >= threshold {
+		e.Slow = true
+	}
+
+	o.log(e)
+
+	if o.Metrics != nil {
+		o.Metrics.Observe(e)
+	}
+	if o.OnEvent != nil {
+		o.OnEvent(ctx, e)
+	}
+}
+
+func (o *Options) slowThreshold() time.Duration {
+	if o.SlowThreshold == 0 {
+		return DefaultSlowThreshold
+	}
+
+	return o.SlowThreshold
+}
+
+func (o *Options) log(e Event) {
+	switch {
+	case o.Level == LogOff:
+		return
+	case o.Level == LogSlow && !e.Slow && e.Err == nil:
+		return
+	}
+
+	logger := o.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	logger.Print(o.Format(e))
+}
+
+// Format — строка лога: "SLOW sql exec 312ms UPDATE orders SET status = $1 WHERE id = $2 [$1=<string 4> $2=42] rows=1"
+func (o *Options) Format(e Event) string {
+	var sb strings.Builder
+
+	if e.Slow {
+		sb.WriteString("SLOW ")
+	}
+	fmt.Fprintf(&sb, "sql %s %s", e.Op, e.Duration.Round(time.Microsecond))
+
+	if e.Query != "" {
+		sb.WriteString(" ")
+		sb.WriteString(Normalize(e.Query))
+	}
+
+	if len(e.Args) > 0 {
+		sb.WriteString(" [")
+		for i, a := range e.Args {
+			if i > 0 {
+				sb.WriteString(" ")
+			}
+			if a.Name != "" {
+				fmt.Fprintf(&sb, ":%s=", a.Name)
+			} else {
+				fmt.Fprintf(&sb, "$%d=", a.Ordinal)
+			}
+			sb.WriteString(o.formatArg(a.Value))
+		}
+		sb.WriteString("]")
+	}
+
+	if e.RowsAffected >= 0 && (e.Op == OpExec || e.Op == OpStmtExec) {
+		fmt.Fprintf(&sb, " rows=%d", e.RowsAffected)
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&sb, " err=%q", e.Err.Error())
+	}
+
+	return sb.String()
+}
+
+func (o *Options) formatArg(v any) string {
+	switch {
+	case o.ShowArgs:
+		return fmt.Sprintf("%v", v)
+	case o.Redact != nil:
+		return o.Redact(v)
+	}
+
+	return RedactValue(v)
+}
+
+// RedactValue показывает значения, по которым нельзя ничего узнать о человеке, а у строк и байтов — только длину
+func RedactValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return fmt.Sprintf("<string %d>", len(v))
+	case []byte:
+		return fmt.Sprintf("<bytes %d>", len(v))
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case int64, float64, bool:
+		return fmt.Sprint(v)
+	}
+
+	return fmt.Sprintf("<%T>", v)
+}
+
+// Normalize схлопывает пробелы и переводы строк: многострочный запрос из кода превращается в одну строку лога
+// и в один ключ Metrics независимо от отступов
+func Normalize(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
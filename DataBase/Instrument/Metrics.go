@@ -0,0 +1,121 @@
+package Instrument
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Metrics — статистика по каждому тексту запроса (после Normalize): сколько раз, сколько ошибок и медленных, суммарное и максимальное время
+type Metrics struct {
+	mu    sync.Mutex
+	stats map[string]*StatementStats
+}
+
+type StatementStats struct {
+	Query  string
+	Count  int64
+	Errors int64
+	Slow   int64
+	Total  time.Duration
+	Max    time.Duration
+}
+
+func (s StatementStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return s.Total / time.Duration(s.Count)
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{stats: make(map[string]*StatementStats)}
+}
+
+// Observe учитывает событие; begin/commit/rollback и prepare не считаются
+func (m *Metrics) Observe(e Event) {
+	if !e.Statement() {
+		return
+	}
+
+	query := Normalize(e.Query)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.stats[query]
+	if !ok {
+		s = &StatementStats{Query: query}
+		m.stats[query] = s
+	}
+
+	s.Count++
+	s.Total += e.Duration
+	s.Max = max(s.Max, e.Duration)
+	if e.Err != nil {
+		s.Errors++
+	}
+	if e.Slow {
+		s.Slow++
+	}
+}
+
+// Snapshot — копия статистики, самые «дорогие» по суммарному времени запросы первыми
+func (m *Metrics) Snapshot() []StatementStats {
+	m.mu.Lock()
+	out := make([]StatementStats, 0, len(m.stats))
+	for _, s := range m.stats {
+		out = append(out, *s)
+	}
+	m.mu.Unlock()
+
+	slices.SortFunc(out, func(a, b StatementStats) int {
+		if c := cmp.Compare(b.Total, a.Total); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Count, a.Count)
+	})
+
+	return out
+}
+
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clear(m.stats)
+}
+
+// WriteTo печатает Snapshot таблицей
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	tw := tabwriter.NewWriter(cw, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "COUNT\tERRORS\tSLOW\tTOTAL\tMEAN\tMAX\tQUERY")
+	for _, s := range m.Snapshot() {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			s.Count, s.Errors, s.Slow,
+			s.Total.Round(time.Microsecond), s.Mean().Round(time.Microsecond), s.Max.Round(time.Microsecond),
+			s.Query)
+	}
+	err := tw.Flush()
+
+	return cw.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
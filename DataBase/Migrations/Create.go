@@ -0,0 +1,56 @@
+package Migrations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create создаёт пару пустых файлов следующей версии в каталоге dir (на диске, не в embed) и возвращает их пути
+func Create(dir, name string) ([]string, error) {
+	name = strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migrations: пустое имя миграции")
+	}
+
+	existing, err := Load(os.DirFS(dir), ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var next int64 = 1
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%04d_%s", next, name)
+	files := []string{
+		filepath.Join(dir, base+".up.sql"),
+		filepath.Join(dir, base+".down.sql"),
+	}
+	bodies := []string{
+		"-- " + base + ": применение\n",
+		"-- " + base + ": откат, обратный up\n",
+	}
+
+	for i, file := range files {
+		// O_EXCL: не затереть файл, если кто-то создал такую же версию параллельно
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		_, err = f.WriteString(bodies[i])
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
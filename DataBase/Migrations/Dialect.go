@@ -0,0 +1,155 @@
+package Migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"learning/DataBase/Dialect"
+)
+
+// locker — то немногое, чем Postgres и SQLite различаются для самих миграций сверх Dialect.Dialect
+type locker interface {
+	// Lock захватывает блокировку миграций на соединении conn и держит её до Unlock; wait — сколько ждать чужую
+	Lock(ctx context.Context, conn *sql.Conn, wait time.Duration) error
+	Unlock(ctx context.Context, conn *sql.Conn) error
+
+	// DisableForeignKeys — для директивы foreign_keys=off: выключает внешние ключи на conn до начала транзакции
+	// и возвращает функцию, которая вернёт прежнее состояние; CheckForeignKeys — проверка ссылок перед commit
+	DisableForeignKeys(ctx context.Context, conn *sql.Conn) (restore func() error, err error)
+	CheckForeignKeys(ctx context.Context, tx *sql.Tx) error
+}
+
+// lockerFor — по диалекту, а не по имени драйвера: какой драйвер какой диалект, знает только Dialect.For
+func lockerFor(d Dialect.Dialect) (locker, error) {
+	switch d {
+	case Dialect.Postgres:
+		return postgres{}, nil
+	case Dialect.SQLite:
+		return sqlite{}, nil
+	}
+
+	return nil, fmt.Errorf("migrations: диалект %s не поддерживается", d.Name())
+}
+
+// lockKey — произвольное, но постоянное число: одинаковое у всех процессов, которые мигрируют эту базу
+const lockKey = 7_364_129_001
+
+// postgres блокирует через pg_advisory_lock: блокировка принадлежит сессии и снимается сама, если процесс упал.
+// Ждёт, сколько потребуется, — ограничить ожидание можно только через ctx
+type postgres struct{}
+
+func (postgres) Lock(ctx context.Context, conn *sql.Conn, _ time.Duration) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey)
+	return err
+}
+
+func (postgres) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+	return err
+}
+
+// DisableForeignKeys в Postgres не нужен: ALTER TABLE меняет ограничения без пересоздания таблицы
+func (postgres) DisableForeignKeys(context.Context, *sql.Conn) (func() error, error) {
+	return nil, errors.New("migrations: директива foreign_keys=off только для SQLite — в Postgres используйте ALTER TABLE")
+}
+
+func (postgres) CheckForeignKeys(context.Context, *sql.Tx) error { return nil }
+
+/*
+SQLite advisory-блокировок не знает, поэтому блокировка — строка в таблице schema_migrations_lock
+с PRIMARY KEY: второй процесс не сможет вставить такую же строку и ждёт.
+Если процесс упал, строка останется — её видно в ошибке, удаляется командой dbctl unlock.
+*/
+type sqlite struct{}
+
+func (sqlite) Lock(ctx context.Context, conn *sql.Conn, wait time.Duration) error {
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		locked_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(wait)
+
+	for {
+		_, err := conn.ExecContext(ctx, "INSERT INTO schema_migrations_lock (id, locked_at) VALUES (1, ?)", time.Now().UTC())
+		if err == nil {
+			return nil
+		}
+		// Строка уже есть — блокировку держит другой процесс; база занята его записью (SQLITE_BUSY) — тоже ждём
+		class := Dialect.SQLite.Classify(err)
+		if !errors.Is(class, Dialect.ErrUniqueViolation) && !errors.Is(class, Dialect.ErrSerialization) {
+			return err
+		}
+
+		if time.Now().After(deadline) {
+			var since time.Time
+			conn.QueryRowContext(ctx, "SELECT locked_at FROM schema_migrations_lock WHERE id = 1").Scan(&since)
+			return fmt.Errorf("%w (с %s); если мигрирующий процесс упал, выполните dbctl unlock", ErrLocked, since.Format(time.RFC3339))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+func (sqlite) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations_lock WHERE id = 1")
+	return err
+}
+
+func (sqlite) DisableForeignKeys(ctx context.Context, conn *sql.Conn) (func() error, error) {
+	var on bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&on); err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		if !on {
+			return nil
+		}
+		_, err := conn.ExecContext(context.Background(), "PRAGMA foreign_keys = ON")
+		return err
+	}, nil
+}
+
+// CheckForeignKeys — PRAGMA foreign_key_check: строки, которые ссылаются на несуществующих родителей
+func (sqlite) CheckForeignKeys(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var broken []string
+	for rows.Next() {
+		var table, parent string
+		var rowid sql.NullInt64
+		var fkid int64
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return err
+		}
+		broken = append(broken, fmt.Sprintf("%s rowid %d -> %s", table, rowid.Int64, parent))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(broken) > 0 {
+		return fmt.Errorf("migrations: нарушены внешние ключи: %s", strings.Join(broken, ", "))
+	}
+
+	return nil
+}
+
+var ErrLocked = errors.New("migrations: миграции уже выполняет другой процесс")
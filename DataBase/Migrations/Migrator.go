@@ -0,0 +1,389 @@
+package Migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"learning/DataBase/Dialect"
+)
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect.Dialect
+	locker     locker
+	migrations []Migration
+
+	Log      *log.Logger
+	LockWait time.Duration // сколько ждать чужую блокировку в SQLite, по умолчанию минута; Postgres ждёт до отмены ctx
+}
+
+// New — мигратор со встроенными миграциями из sql/; диалект — Dialect.For(Connection.Driver())
+func New(db *sql.DB, d Dialect.Dialect) (*Migrator, error) {
+	return NewFrom(db, d, Files, Dir)
+}
+
+// NewFrom — мигратор с миграциями из произвольной файловой системы, например, os.DirFS для своих файлов
+func NewFrom(db *sql.DB, d Dialect.Dialect, fsys fs.FS, dir string) (*Migrator, error) {
+	l, err := lockerFor(d)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := Load(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, dialect: d, locker: l, migrations: migrations, Log: log.Default(), LockWait: time.Minute}, nil
+}
+
+// State — строка вывода status
+type State struct {
+	Migration Migration
+	Applied   bool
+	AppliedAt time.Time
+	Drift     bool // файл изменён после применения
+	Missing   bool // версия применена, а файла нет
+}
+
+type applied struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// DriftError — применённые миграции не совпадают с файлами
+type DriftError struct {
+	Changed []string // файл отредактирован после применения
+	Missing []string // в базе есть версия, которой нет среди файлов
+}
+
+func (e *DriftError) Error() string {
+	var parts []string
+	if len(e.Changed) > 0 {
+		parts = append(parts, "изменены после применения: "+strings.Join(e.Changed, ", "))
+	}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "применены, но файлов нет: "+strings.Join(e.Missing, ", "))
+	}
+
+	return "migrations: дрейф схемы — " + strings.Join(parts, "; ")
+}
+
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up применяет все ещё не применённые миграции и возвращает их
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		have, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.checkDrift(have); err != nil {
+			return err
+		}
+
+		var latest int64
+		for v := range have {
+			latest = max(latest, v)
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := have[mig.Version]; ok {
+				continue
+			}
+			// Миграция из старой ветки, влитая после более новых, могла бы применить изменения не в том порядке
+			if mig.Version < latest {
+				return fmt.Errorf("migrations: %s старее последней применённой версии %d — переименуйте её в следующий номер", mig, latest)
+			}
+
+			if err := m.apply(ctx, conn, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down откатывает n последних применённых миграций
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("migrations: down %d: нужно положительное число", n)
+	}
+
+	var done []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		have, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.checkDrift(have); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < n; i-- {
+			mig := m.migrations[i]
+			if _, ok := have[mig.Version]; !ok {
+				continue
+			}
+
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Status — все миграции из файлов и из базы, по возрастанию версии
+func (m *Migrator) Status(ctx context.Context) ([]State, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	have, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []State
+	for _, mig := range m.migrations {
+		st := State{Migration: mig}
+		if a, ok := have[mig.Version]; ok {
+			st.Applied = true
+			st.AppliedAt = a.appliedAt
+			st.Drift = a.checksum != mig.Checksum(m.dialect.Name())
+			delete(have, mig.Version)
+		}
+		out = append(out, st)
+	}
+
+	for version, a := range have {
+		out = append(out, State{
+			Migration: Migration{Version: version, Name: a.name},
+			Applied:   true,
+			AppliedAt: a.appliedAt,
+			Missing:   true,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Migration.Version < out[j].Migration.Version })
+
+	return out, nil
+}
+
+// Version — номер последней применённой миграции, 0 — ни одной; его пишет в манифест архива Backup.Export
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	states, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64
+	for _, st := range states {
+		if st.Applied && st.Migration.Version > version {
+			version = st.Migration.Version
+		}
+	}
+
+	return version, nil
+}
+
+// ForceUnlock снимает блокировку, оставшуюся от упавшего процесса (нужно только SQLite)
+func (m *Migrator) ForceUnlock(ctx context.Context) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return m.locker.Unlock(ctx, conn)
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	// Блокировка Postgres живёт в сессии, поэтому всё делается на одном выделенном соединении
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.locker.Lock(ctx, conn, m.LockWait); err != nil {
+		return err
+	}
+	defer func() {
+		if err := m.locker.Unlock(context.Background(), conn); err != nil {
+			m.Log.Println("migrations: не удалось снять блокировку:", err)
+		}
+	}()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]applied, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int64]applied)
+	for rows.Next() {
+		var version int64
+		var a applied
+		if err := rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		out[version] = a
+	}
+
+	return out, rows.Err()
+}
+
+func (m *Migrator) checkDrift(have map[int64]applied) error {
+	drift := &DriftError{}
+	known := make(map[int64]bool, len(m.migrations))
+
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		if a, ok := have[mig.Version]; ok && a.checksum != mig.Checksum(m.dialect.Name()) {
+			drift.Changed = append(drift.Changed, mig.String())
+		}
+	}
+
+	for version, a := range have {
+		if !known[version] {
+			drift.Missing = append(drift.Missing, fmt.Sprintf("%04d_%s", version, a.name))
+		}
+	}
+
+	if len(drift.Changed) > 0 || len(drift.Missing) > 0 {
+		sort.Strings(drift.Changed)
+		sort.Strings(drift.Missing)
+		return drift
+	}
+
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	d := m.dialect
+	insert := fmt.Sprintf("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (%s, %s, %s, %s)",
+		d.Placeholder(1), d.Placeholder(2), d.Placeholder(3), d.Placeholder(4))
+
+	up := mig.Up(d.Name())
+
+	return m.inTx(ctx, conn, mig, "up", foreignKeysOff(up), func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, up); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, insert, mig.Version, mig.Name, mig.Checksum(d.Name()), time.Now().UTC())
+		return err
+	})
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	d := m.dialect
+
+	down := mig.Down(d.Name())
+	if strings.TrimSpace(down) == "" {
+		return fmt.Errorf("migrations: %s необратима — нет down-файла", mig)
+	}
+
+	return m.inTx(ctx, conn, mig, "down", foreignKeysOff(down), func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, down); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = "+d.Placeholder(1), mig.Version)
+		return err
+	})
+}
+
+// inTx: DDL в Postgres и SQLite транзакционный, поэтому упавшая миграция не оставляет схему наполовину изменённой.
+// noFK — директива foreign_keys=off: ключи выключаются до BEGIN и проверяются перед commit
+func (m *Migrator) inTx(ctx context.Context, conn *sql.Conn, mig Migration, direction string, noFK bool, fn func(tx *sql.Tx) error) error {
+	started := time.Now()
+
+	if noFK {
+		restore, err := m.locker.DisableForeignKeys(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("migrations: %s %s: %w", direction, mig, err)
+		}
+		defer func() {
+			if err := restore(); err != nil {
+				m.Log.Println("migrations: не удалось включить внешние ключи обратно:", err)
+			}
+		}()
+
+		migrate := fn
+		fn = func(tx *sql.Tx) error {
+			if err := migrate(tx); err != nil {
+				return err
+			}
+			return m.locker.CheckForeignKeys(ctx, tx)
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			err = errors.Join(err, rbErr)
+		}
+		return fmt.Errorf("migrations: %s %s: %w", direction, mig, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migrations: %s %s: commit: %w", direction, mig, err)
+	}
+
+	m.Log.Printf("migrations: %s %s (%s)", direction, mig, time.Since(started).Round(time.Millisecond))
+
+	return nil
+}
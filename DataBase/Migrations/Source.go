@@ -0,0 +1,153 @@
+package Migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Версионированные миграции схемы.
+
+Файлы лежат в sql/ и встраиваются в бинарник через embed:
+	0001_create_users.up.sql          — применить;
+	0001_create_users.down.sql        — откатить;
+	0001_create_users.up.sqlite.sql   — необязательная версия для конкретного диалекта (SERIAL в SQLite нет).
+Номер — версия миграции, миграции применяются строго по возрастанию, каждая в своей транзакции.
+
+Директива в начале файла меняет то, как он выполняется:
+	-- migrate: foreign_keys=off — внешние ключи выключены на время миграции (только SQLite). Нужна, когда таблица
+	   пересоздаётся (SQLite не умеет ALTER для CHECK и внешних ключей): иначе DROP TABLE старой таблицы сработает
+	   как DELETE и ON DELETE CASCADE удалит строки всех таблиц, которые на неё ссылаются. Это 12 шагов из
+	   https://www.sqlite.org/lang_altertable.html: PRAGMA foreign_keys=OFF до транзакции (внутри он ничего не делает),
+	   миграция, PRAGMA foreign_key_check перед commit, PRAGMA foreign_keys=ON после.
+
+Применённые версии записываются в schema_migrations вместе с контрольной суммой up-скрипта.
+Если уже применённый файл потом отредактировали, суммы разойдутся — это дрейф схемы, и Up/Down откажутся работать,
+пока кто-то не разберётся: правильный способ изменить схему — новая миграция, а не правка старой.
+*/
+
+//go:embed sql/*.sql
+var Files embed.FS
+
+// Dir — каталог с миграциями внутри Files
+const Dir = "sql"
+
+type Migration struct {
+	Version int64
+	Name    string
+
+	up, down map[string]string // диалект ("" — общий) -> SQL
+}
+
+// Up возвращает SQL применения для диалекта, с откатом на общий файл
+func (m Migration) Up(dialect string) string {
+	if sql, ok := m.up[dialect]; ok {
+		return sql
+	}
+
+	return m.up[""]
+}
+
+func (m Migration) Down(dialect string) string {
+	if sql, ok := m.down[dialect]; ok {
+		return sql
+	}
+
+	return m.down[""]
+}
+
+// Checksum — sha256 up-скрипта для диалекта; переводы строк нормализуются, чтобы checkout на Windows не давал дрейфа
+func (m Migration) Checksum(dialect string) string {
+	sum := sha256.Sum256([]byte(strings.ReplaceAll(m.Up(dialect), "\r\n", "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// foreignKeysOff — в начальных комментариях скрипта есть директива -- migrate: foreign_keys=off
+func foreignKeysOff(script string) bool {
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		comment, ok := strings.CutPrefix(line, "--")
+		if !ok {
+			return false
+		}
+		if directive, ok := strings.CutPrefix(strings.TrimSpace(comment), "migrate:"); ok && strings.TrimSpace(directive) == "foreign_keys=off" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 0001_create_users.up.sql, 0001_create_users.down.sqlite.sql
+var fileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)(?:\.([a-z0-9]+))?\.sql$`)
+
+// Load читает миграции из каталога dir файловой системы fsys, отсортированные по версии
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		m := fileRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migrations: %s: имя не по шаблону NNNN_name.up|down[.dialect].sql", e.Name())
+		}
+
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		name, direction, dialect := m[2], m[3], m[4]
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: name, up: map[string]string{}, down: map[string]string{}}
+			byVersion[version] = mig
+		}
+		if mig.Name != name {
+			return nil, fmt.Errorf("migrations: версия %d встречается с разными именами: %s и %s", version, mig.Name, name)
+		}
+
+		if direction == "up" {
+			mig.up[dialect] = string(data)
+		} else {
+			mig.down[dialect] = string(data)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up[""] == "" {
+			return nil, fmt.Errorf("migrations: %s: нет общего up-файла", m)
+		}
+		out = append(out, *m)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+
+	return out, nil
+}
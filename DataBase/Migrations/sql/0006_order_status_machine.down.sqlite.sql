@@ -0,0 +1,21 @@
+-- migrate: foreign_keys=off
+-- orders пересоздаётся со старым CHECK; внешние ключи выключены, чтобы DROP TABLE orders не удалил order_items
+DROP TABLE IF EXISTS order_status_history;
+
+CREATE TABLE orders_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    total_amount DECIMAL(10,2) CHECK (total_amount >= 0),
+    status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'shipped', 'delivered', 'cancelled')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO orders_old (id, user_id, total_amount, status, created_at, updated_at)
+SELECT id, user_id, total_amount, CASE status WHEN 'paid' THEN 'processing' WHEN 'refunded' THEN 'cancelled' ELSE status END, created_at, updated_at
+FROM orders;
+
+DROP TABLE orders;
+ALTER TABLE orders_old RENAME TO orders;
+CREATE INDEX IF NOT EXISTS idx_orders_user ON orders(user_id);
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status);
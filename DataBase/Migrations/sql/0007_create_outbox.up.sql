@@ -0,0 +1,17 @@
+-- Transactional outbox: события пишутся в той же транзакции, что и заказ, а публикует их Outbox.Relay.
+-- published_at IS NULL AND dead_at IS NULL — ждёт отправки; dead_at — отравленное сообщение, relay его больше не берёт
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    topic VARCHAR(100) NOT NULL,
+    partition_key VARCHAR(100) NOT NULL DEFAULT '',
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    available_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    published_at TIMESTAMP,
+    dead_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(available_at, id) WHERE published_at IS NULL AND dead_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_key ON outbox(partition_key, id) WHERE published_at IS NULL AND dead_at IS NULL;
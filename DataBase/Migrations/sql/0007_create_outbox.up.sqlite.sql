@@ -0,0 +1,16 @@
+-- То же, что в 0007_create_outbox.up.sql; JSONB в SQLite нет, payload хранится текстом
+CREATE TABLE IF NOT EXISTS outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    topic VARCHAR(100) NOT NULL,
+    partition_key VARCHAR(100) NOT NULL DEFAULT '',
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    available_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    published_at TIMESTAMP,
+    dead_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(available_at, id) WHERE published_at IS NULL AND dead_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_key ON outbox(partition_key, id) WHERE published_at IS NULL AND dead_at IS NULL;
@@ -0,0 +1,4 @@
+-- Индексы под отчёты о продажах (пакет Reports): заказы выбираются по диапазону created_at и статусу,
+-- позиции группируются по товару. Запросы одинаковые для Postgres и SQLite, поэтому и файл один
+CREATE INDEX IF NOT EXISTS idx_orders_created_status ON orders(created_at, status);
+CREATE INDEX IF NOT EXISTS idx_order_items_product ON order_items(product_id);
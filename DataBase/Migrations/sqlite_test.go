@@ -0,0 +1,153 @@
+//go:build sqlite
+
+package Migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"learning/DataBase/Connection"
+	"learning/DataBase/Dialect"
+)
+
+// openSQLite — свой файл SQLite во временном каталоге, открытый так же, как при DB_DRIVER=sqlite
+func openSQLite(t *testing.T) (*sql.DB, Dialect.Dialect) {
+	t.Helper()
+
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DATABASE_URL", filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)")
+
+	db, err := Connection.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	d, err := Dialect.For(Connection.Driver())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, d
+}
+
+func count(t *testing.T, db *sql.DB, table string) int {
+	t.Helper()
+
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	return n
+}
+
+// 0006 пересоздаёт orders; с включёнными внешними ключами DROP TABLE orders удалил бы order_items каскадом
+func TestSQLiteUpDownKeepsOrderItems(t *testing.T) {
+	ctx := context.Background()
+	db, d := openSQLite(t)
+
+	m, err := New(db, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Log = log.New(io.Discard, "", 0)
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, q := range []string{
+		"INSERT INTO users (id, username, email) VALUES (1, 'daniil', 'daniil@example.com')",
+		"INSERT INTO products (id, name, price) VALUES (1, 'iPhone 15', 999.99)",
+		"INSERT INTO orders (id, user_id, total_amount, status) VALUES (1, 1, 999.99, 'paid')",
+		"INSERT INTO order_items (order_id, product_id, quantity, price) VALUES (1, 1, 1, 999.99)",
+	} {
+		if _, err := db.ExecContext(ctx, q); err != nil {
+			t.Fatal(q, err)
+		}
+	}
+
+	latest := m.Migrations()[len(m.Migrations())-1].Version
+	var down int
+	for _, mig := range m.Migrations() {
+		if mig.Version >= 6 {
+			down++
+		}
+	}
+
+	if _, err := m.Down(ctx, down); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, db, "order_items"); n != 1 {
+		t.Fatalf("после down order_items: %d строк, ожидалась 1", n)
+	}
+
+	var status string
+	if err := db.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = 1").Scan(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status != "processing" {
+		t.Fatalf("после down статус %q, ожидался processing", status)
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, db, "order_items"); n != 1 {
+		t.Fatalf("после up order_items: %d строк, ожидалась 1", n)
+	}
+	if v, err := m.Version(ctx); err != nil || v != latest {
+		t.Fatalf("версия %d (%v), ожидалась %d", v, err, latest)
+	}
+
+	// Внешние ключи после миграции снова включены
+	if _, err := db.ExecContext(ctx, "INSERT INTO order_items (order_id, product_id, quantity, price) VALUES (42, 1, 1, 1)"); err == nil {
+		t.Fatal("позиция несуществующего заказа вставилась: внешние ключи остались выключены")
+	}
+	if n := count(t, db, "schema_migrations_lock"); n != 0 {
+		t.Fatalf("блокировка миграций не снята: %d строк", n)
+	}
+}
+
+// Вторая блокировка ждёт первую: конфликт по PRIMARY KEY распознаётся через Classify и даёт ErrLocked, а не ошибку вставки
+func TestSQLiteLockContention(t *testing.T) {
+	ctx := context.Background()
+	db, d := openSQLite(t)
+
+	l, err := lockerFor(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	second, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	if err := l.Lock(ctx, first, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Lock(ctx, second, 300*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Fatalf("вторая блокировка: %v, ожидалась ErrLocked", err)
+	}
+
+	if err := l.Unlock(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Lock(ctx, second, time.Second); err != nil {
+		t.Fatalf("после Unlock: %v", err)
+	}
+}
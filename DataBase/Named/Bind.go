@@ -0,0 +1,176 @@
+package Named
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"learning/DataBase/Scanner"
+)
+
+// Rewrite — Compile + Bind одним вызовом
+func Rewrite(style Style, query string, args ...any) (string, []any, error) {
+	q, err := Compile(query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return q.Bind(style, args...)
+}
+
+// Bind подставляет позиционные параметры вместо :name и возвращает запрос и аргументы для драйвера
+func (q *Query) Bind(style Style, args ...any) (string, []any, error) {
+	lookup, err := sources(args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sb strings.Builder
+	var out []any
+	bound := make(map[string]string) // для $n: имя -> уже выданные номера
+
+	for _, p := range q.parts {
+		if p.name == "" {
+			sb.WriteString(p.text)
+			continue
+		}
+
+		if text, ok := bound[p.name]; ok {
+			sb.WriteString(text)
+			continue
+		}
+
+		v, ok := lookup(p.name)
+		if !ok {
+			return "", nil, fmt.Errorf("%w :%s", ErrMissing, p.name)
+		}
+
+		values, err := expand(p.name, v)
+		if err != nil {
+			return "", nil, err
+		}
+
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			out = append(out, v)
+			placeholders[i] = style.Placeholder(len(out))
+		}
+
+		text := strings.Join(placeholders, ", ")
+		if style == Dollar {
+			bound[p.name] = text
+		}
+		sb.WriteString(text)
+	}
+
+	return sb.String(), out, nil
+}
+
+var (
+	valuerType = reflect.TypeFor[driver.Valuer]()
+	bytesType  = reflect.TypeFor[[]byte]()
+)
+
+// expand разворачивает срез в список значений; всё остальное — одно значение
+func expand(name string, v any) ([]any, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Type().Implements(valuerType) || rv.Type() == bytesType {
+		return []any{v}, nil
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []any{v}, nil
+	}
+
+	if rv.Len() == 0 {
+		return nil, fmt.Errorf("%w :%s", ErrEmptySlice, name)
+	}
+
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+
+	return out, nil
+}
+
+// sources превращает аргументы в одну функцию поиска по имени
+func sources(args []any) (func(name string) (any, bool), error) {
+	var lookups []func(string) (any, bool)
+
+	for _, arg := range args {
+		switch a := arg.(type) {
+		case sql.NamedArg:
+			lookups = append(lookups, func(name string) (any, bool) {
+				return a.Value, name == a.Name
+			})
+		case map[string]any:
+			lookups = append(lookups, func(name string) (any, bool) {
+				v, ok := a[name]
+				return v, ok
+			})
+		default:
+			lookup, err := reflectSource(arg)
+			if err != nil {
+				return nil, err
+			}
+			lookups = append(lookups, lookup)
+		}
+	}
+
+	return func(name string) (any, bool) {
+		for _, lookup := range lookups {
+			if v, ok := lookup(name); ok {
+				return v, true
+			}
+		}
+		return nil, false
+	}, nil
+}
+
+// reflectSource — map со строковыми ключами или структура (указатель на структуру)
+func reflectSource(arg any) (func(string) (any, bool), error) {
+	rv := reflect.ValueOf(arg)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		return func(name string) (any, bool) {
+			v := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+			if !v.IsValid() {
+				return nil, false
+			}
+			return v.Interface(), true
+		}, nil
+
+	case rv.Kind() == reflect.Struct:
+		fields := Scanner.Fields(rv.Type())
+		return func(name string) (any, bool) {
+			path, ok := fields[strings.ToLower(name)]
+			if !ok {
+				return nil, false
+			}
+			return fieldValue(rv, path), true
+		}, nil
+	}
+
+	return nil, fmt.Errorf("named: аргумент %T — нужен sql.NamedArg, map со строковыми ключами или структура", arg)
+}
+
+// fieldValue идёт по пути индексов; nil-указатель на встроенную структуру по дороге даёт NULL
+func fieldValue(v reflect.Value, path []int) any {
+	for i, idx := range path {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+
+	return v.Interface()
+}
@@ -0,0 +1,81 @@
+package Named
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Querier — общее у *sql.DB, *sql.Tx и *sql.Conn
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// DB — Querier, который принимает запросы с :name
+//
+//	db := Named.New(tx, Named.StyleFor(d)) // d — Dialect.For(Connection.Driver())
+//	rows, err := db.QueryContext(ctx, "SELECT ... WHERE id IN (:ids)", sql.Named("ids", ids))
+type DB struct {
+	q     Querier
+	style Style
+}
+
+func New(q Querier, style Style) *DB {
+	return &DB{q: q, style: style}
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	query, args, err := Rewrite(db.style, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.q.ExecContext(ctx, query, args...)
+}
+
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	query, args, err := Rewrite(db.style, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.q.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext возвращает свой Row, а не *sql.Row: у *sql.Row нельзя задать ошибку, а ошибка разбора (нет параметра, пустой срез)
+// должна прийти из Scan, как и в database/sql
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *Row {
+	rows, err := db.QueryContext(ctx, query, args...)
+
+	return &Row{rows: rows, err: err}
+}
+
+// Row — как *sql.Row: ошибки запроса откладываются до Scan, пустой результат — sql.ErrNoRows
+type Row struct {
+	rows *sql.Rows
+	err  error
+}
+
+func (r *Row) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	return r.rows.Close()
+}
+
+func (r *Row) Err() error {
+	return r.err
+}
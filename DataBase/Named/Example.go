@@ -0,0 +1,72 @@
+package Named
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"learning/DataBase/Connection"
+	"learning/DataBase/Dialect"
+)
+
+// ExampleRewrite — во что превращается запрос с :name для Postgres и для SQLite
+func ExampleRewrite() {
+	query := `SELECT id, total_amount::text FROM orders
+	          WHERE user_id = :user_id AND status IN (:statuses) AND note <> 'a:b' AND created_at > :since`
+
+	filter := struct {
+		UserID   int
+		Statuses []string
+	}{UserID: 2, Statuses: []string{"paid", "shipped"}}
+
+	for _, style := range []Style{Dollar, Question} {
+		q, args, err := Rewrite(style, query, filter, sql.Named("since", time.Now().AddDate(0, -1, 0)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(q)
+		fmt.Println(args...)
+	}
+}
+
+// ExampleNamedQuery — тот же SimpleSelectQuery, но параметры по имени и в любом порядке
+func ExampleNamedQuery() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := Connection.Open()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	d, err := Dialect.For(Connection.Driver())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db := New(conn, StyleFor(d))
+
+	rows, err := db.QueryContext(ctx,
+		"SELECT id, total_amount FROM orders WHERE total_amount > :min AND id IN (:ids) ORDER BY id",
+		map[string]any{"ids": []int{1, 2, 3}, "min": 100},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var total float64
+		if err := rows.Scan(&id, &total); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(id, total)
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,225 @@
+package Named
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"learning/DataBase/Dialect"
+)
+
+/*
+Именованные параметры :name поверх драйверов, которые понимают только позиционные ($1 у Postgres, ? у SQLite и MySQL).
+В databaseBasic.go отмечено, что lib/pq не поддерживает sql.Named — поэтому запрос переписывается до отправки в драйвер:
+
+	SELECT * FROM orders WHERE user_id = :user AND status IN (:statuses) AND created_at > :since
+	->
+	SELECT * FROM orders WHERE user_id = $1 AND status IN ($2, $3) AND created_at > $4
+
+Значения берутся из:
+	- sql.Named("user", 2) — можно передать несколько;
+	- map[string]any (или любой map со строковыми ключами);
+	- структуры или указателя на неё — по тегу db и snake_case, по тем же правилам, что и Scanner (UserID -> :user_id).
+Если источников несколько, побеждает первый, в котором имя нашлось.
+
+Что не считается параметром:
+	- двоеточие внутри строк 'a:b', идентификаторов "a:b", комментариев (строчных -- и блочных), dollar-quoted строк $$...$$;
+	- приведение типа ::text;
+	- двоеточие, за которым не идёт буква или _: срез массива arr[1:2].
+
+Срез (кроме []byte) разворачивается в список: IN (:ids) с []int{1, 2, 3} -> IN ($1, $2, $3).
+Пустой срез — ошибка: IN () — синтаксическая ошибка в SQL, и лучше узнать об этом до запроса.
+Одно и то же имя в запросе для $n получает один номер; для ? значение повторяется столько раз, сколько встречается имя.
+
+Разбор запроса кэшируется по тексту, так что повторные вызовы с тем же запросом стоят только подстановки.
+*/
+
+// Style — как драйвер записывает позиционный параметр
+type Style int
+
+const (
+	Dollar   Style = iota // $1, $2, ... — Postgres
+	Question              // ?, ?, ... — SQLite, MySQL
+)
+
+// StyleFor — стиль для диалекта; диалект по имени драйвера — Dialect.For(Connection.Driver())
+func StyleFor(d Dialect.Dialect) Style {
+	if d == Dialect.Postgres {
+		return Dollar
+	}
+
+	return Question
+}
+
+// Placeholder — n-й параметр (с 1), как у Dialect.Dialect
+func (s Style) Placeholder(n int) string {
+	if s == Dollar {
+		return fmt.Sprintf("$%d", n)
+	}
+
+	return "?"
+}
+
+var (
+	ErrMissing    = errors.New("named: нет значения для параметра")
+	ErrEmptySlice = errors.New("named: пустой срез для параметра")
+)
+
+// Query — разобранный запрос: куски текста вперемешку с именами параметров
+type Query struct {
+	text  string
+	parts []part
+	names []string // имена в порядке первого появления
+}
+
+type part struct {
+	text string
+	name string // не пусто — это параметр, text не используется
+}
+
+var cache sync.Map // string -> *Query
+
+// Compile разбирает запрос; результат кэшируется
+func Compile(query string) (*Query, error) {
+	if cached, ok := cache.Load(query); ok {
+		return cached.(*Query), nil
+	}
+
+	q, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := cache.LoadOrStore(query, q)
+
+	return actual.(*Query), nil
+}
+
+// Names — имена параметров запроса в порядке первого появления
+func (q *Query) Names() []string {
+	return append([]string(nil), q.names...)
+}
+
+func (q *Query) String() string {
+	return q.text
+}
+
+func parse(query string) (*Query, error) {
+	q := &Query{text: query}
+	seen := make(map[string]bool)
+
+	start := 0
+	flush := func(end int) {
+		if end > start {
+			q.parts = append(q.parts, part{text: query[start:end]})
+		}
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		case c == '\'' || c == '"':
+			end, err := skipQuoted(query, i, c)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			i += end
+
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("named: незакрытый комментарий /* с позиции %d", i)
+			}
+			i += 2 + end + 2
+
+		case c == '$':
+			end, err := skipDollarQuoted(query, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case c == ':' && strings.HasPrefix(query[i:], "::"):
+			i += 2
+
+		case c == ':' && i+1 < len(query) && isNameStart(query[i+1]):
+			end := i + 1
+			for end < len(query) && isNameChar(query[end]) {
+				end++
+			}
+
+			flush(i)
+			name := query[i+1 : end]
+			q.parts = append(q.parts, part{name: name})
+			if !seen[name] {
+				seen[name] = true
+				q.names = append(q.names, name)
+			}
+
+			i = end
+			start = end
+
+		default:
+			i++
+		}
+	}
+	flush(len(query))
+
+	return q, nil
+}
+
+// skipQuoted пропускает '...' или "..."; удвоенная кавычка внутри — экранирование
+func skipQuoted(s string, i int, quote byte) (int, error) {
+	for j := i + 1; j < len(s); j++ {
+		if s[j] != quote {
+			continue
+		}
+		if j+1 < len(s) && s[j+1] == quote {
+			j++
+			continue
+		}
+		return j + 1, nil
+	}
+
+	return 0, fmt.Errorf("named: незакрытая кавычка %c с позиции %d", quote, i)
+}
+
+// skipDollarQuoted пропускает $$...$$ и $tag$...$tag$; $1 и одиночный $ оставляет как есть
+func skipDollarQuoted(s string, i int) (int, error) {
+	j := i + 1
+	for j < len(s) && isNameChar(s[j]) && !(j == i+1 && isDigit(s[j])) {
+		j++
+	}
+	if j >= len(s) || s[j] != '$' {
+		return i + 1, nil
+	}
+
+	tag := s[i : j+1]
+	end := strings.Index(s[j+1:], tag)
+	if end < 0 {
+		return 0, fmt.Errorf("named: незакрытая строка %s с позиции %d", tag, i)
+	}
+
+	return j + 1 + end + len(tag), nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
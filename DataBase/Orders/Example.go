@@ -0,0 +1,94 @@
+package Orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"learning/DataBase/Connection"
+	"learning/DataBase/Dialect"
+)
+
+// ExampleRepository — то же, что SimpleInsertQuery, SimpleUpdateQuery, SimpleSelectQuery и SimpleDeleteQuery, через репозиторий.
+// База — из DB_DRIVER / DATABASE_URL: Postgres из docker-compose или файл SQLite (см. Connection)
+func ExampleRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	db, err := Connection.Open()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	d, err := Dialect.For(Connection.Driver())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	repo, err := NewSQL(ctx, db, d)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer repo.Close()
+
+	run(ctx, repo)
+}
+
+// ExampleMemoryRepository — тот же сценарий без базы
+func ExampleMemoryRepository() {
+	run(context.Background(), NewMemory())
+}
+
+func run(ctx context.Context, repo OrderRepository) {
+	o, err := repo.Create(ctx, Order{UserID: 2, TotalAmount: 1200.25})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("создан:", o.ID, o.Status, o.CreatedAt.Format(time.RFC3339))
+
+	stale := o
+	o.TotalAmount = 1555.55
+	if o, err = repo.Update(ctx, o); err != nil {
+		log.Fatal(err)
+	}
+
+	// Второе изменение по устаревшей копии — конфликт, а не молчаливая перезапись
+	stale.Status = "cancelled"
+	if _, err := repo.Update(ctx, stale); errors.Is(err, ErrConflict) {
+		fmt.Println("ожидаемо:", err)
+	}
+
+	// Статус меняется только по объявленным переходам, каждый попадает в order_status_history
+	for _, to := range []string{StatusPaid, StatusShipped} {
+		if o, err = repo.Transition(ctx, o.ID, to, Meta{Actor: "example", Reason: "демо"}); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if _, err := repo.Transition(ctx, o.ID, StatusPending, Meta{Actor: "example"}); errors.Is(err, ErrIllegalTransition) {
+		fmt.Println("ожидаемо:", err)
+	}
+
+	history, err := repo.History(ctx, o.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, c := range history {
+		fmt.Printf("  %q -> %s (%s)\n", c.From, c.To, c.Reason)
+	}
+
+	orders, err := repo.List(ctx, Filter{UserID: 2})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("заказов пользователя 2:", len(orders))
+
+	if err := repo.Delete(ctx, o.ID); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := repo.Get(ctx, o.ID); errors.Is(err, ErrNotFound) {
+		fmt.Println("удалён:", o.ID)
+	}
+}
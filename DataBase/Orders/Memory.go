@@ -0,0 +1,274 @@
+package Orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+var _ OrderRepository = (*Memory)(nil)
+
+/*
+Memory — фейковый репозиторий в памяти с теми же ограничениями, что и таблица orders.
+
+Изменение заказа держит m.mu, пока работают guard'ы и hooks, — это его «транзакция». Guard'ы и hooks получают ctx,
+помеченный этим репозиторием: Get, List и History с таким ctx не берут блокировку повторно и видят заказ уже в новом
+статусе (как SQL-репозиторий внутри транзакции), а Create, Update, Transition и Delete сразу возвращают ошибку,
+а не зависают. Hook, который обращается к репозиторию с другим ctx (context.Background()), зависнет — передавайте тот, что получили.
+*/
+type Memory struct {
+	Machine *Machine
+	Now     func() time.Time // часы «базы», в тестах можно подменить
+
+	mu      sync.Mutex
+	nextID  int
+	orders  map[int]Order
+	history []StatusChange
+	lastLog int // последний id в истории
+}
+
+func NewMemory() *Memory {
+	return &Memory{Machine: NewMachine(), Now: time.Now, nextID: 1, orders: make(map[int]Order)}
+}
+
+// hookKey — метка ctx guard'ов и hooks: значение — репозиторий, который держит m.mu
+type hookKey struct{}
+
+var errHookWrite = errors.New("orders: guard или hook не может менять репозиторий Memory, который его вызвал")
+
+// inHook — ctx для guard'ов и hooks этого репозитория
+func (m *Memory) inHook(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hookKey{}, m)
+}
+
+// read захватывает m.mu, если его уже не держит переход, из guard'а или hook'а которого пришёл вызов
+func (m *Memory) read(ctx context.Context) (unlock func()) {
+	if ctx.Value(hookKey{}) == m {
+		return func() {}
+	}
+
+	m.mu.Lock()
+	return m.mu.Unlock
+}
+
+// write захватывает m.mu; из guard'а или hook'а этого же репозитория — ошибка вместо взаимоблокировки
+func (m *Memory) write(ctx context.Context) (unlock func(), err error) {
+	if ctx.Value(hookKey{}) == m {
+		return nil, errHookWrite
+	}
+
+	m.mu.Lock()
+	return m.mu.Unlock, nil
+}
+
+func (m *Memory) Get(ctx context.Context, id int) (Order, error) {
+	defer m.read(ctx)()
+
+	o, ok := m.orders[id]
+	if !ok {
+		return Order{}, ErrNotFound
+	}
+
+	return o, nil
+}
+
+func (m *Memory) List(ctx context.Context, f Filter) ([]Order, error) {
+	defer m.read(ctx)()
+
+	var out []Order
+	for _, o := range m.orders {
+		if f.UserID != 0 && o.UserID != f.UserID {
+			continue
+		}
+		if f.Status != "" && o.Status != f.Status {
+			continue
+		}
+		out = append(out, o)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+
+	if f.Offset >= len(out) {
+		return nil, nil
+	}
+	out = out[f.Offset:]
+	if len(out) > f.limit() {
+		out = out[:f.limit()]
+	}
+
+	return out, nil
+}
+
+func (m *Memory) Create(ctx context.Context, o Order) (Order, error) {
+	if o.Status == "" {
+		o.Status = m.Machine.Initial()
+	}
+	if err := check(o); err != nil {
+		return Order{}, err
+	}
+
+	unlock, err := m.write(ctx)
+	if err != nil {
+		return Order{}, err
+	}
+	defer unlock()
+
+	o.ID = m.nextID
+	if err := m.Machine.check(m.inHook(ctx), o, "", o.Status); err != nil {
+		return Order{}, err
+	}
+
+	o.CreatedAt = m.Now()
+	o.UpdatedAt = o.CreatedAt
+	if err := m.commit(ctx, o, "", Meta{Reason: "created"}); err != nil {
+		return Order{}, err
+	}
+	m.nextID++
+
+	return o, nil
+}
+
+func (m *Memory) Update(ctx context.Context, o Order) (Order, error) {
+	unlock, err := m.write(ctx)
+	if err != nil {
+		return Order{}, err
+	}
+	defer unlock()
+
+	old, ok := m.orders[o.ID]
+	if !ok {
+		return Order{}, ErrNotFound
+	}
+	if !o.UpdatedAt.IsZero() && !o.UpdatedAt.Equal(old.UpdatedAt) {
+		return Order{}, fmt.Errorf("%w: заказ %d изменён после %s", ErrConflict, o.ID, o.UpdatedAt.Format("15:04:05.000"))
+	}
+
+	if o.Status == "" {
+		o.Status = old.Status
+	}
+	if err := check(o); err != nil {
+		return Order{}, err
+	}
+	if o.Status != old.Status {
+		if err := m.Machine.check(m.inHook(ctx), o, old.Status, o.Status); err != nil {
+			return Order{}, err
+		}
+	}
+
+	o.CreatedAt = old.CreatedAt
+	o.UpdatedAt = m.Now()
+
+	if o.Status == old.Status {
+		m.orders[o.ID] = o
+		return o, nil
+	}
+
+	if err := m.commit(ctx, o, old.Status, updateMeta(ctx)); err != nil {
+		return Order{}, err
+	}
+
+	return o, nil
+}
+
+func (m *Memory) Transition(ctx context.Context, id int, to string, meta Meta) (Order, error) {
+	unlock, err := m.write(ctx)
+	if err != nil {
+		return Order{}, err
+	}
+	defer unlock()
+
+	o, ok := m.orders[id]
+	if !ok {
+		return Order{}, ErrNotFound
+	}
+
+	from := o.Status
+	o.Status = to
+	if err := m.Machine.check(m.inHook(ctx), o, from, to); err != nil {
+		return Order{}, err
+	}
+	o.UpdatedAt = m.Now()
+
+	if err := m.commit(ctx, o, from, meta); err != nil {
+		return Order{}, err
+	}
+
+	return o, nil
+}
+
+func (m *Memory) History(ctx context.Context, id int) ([]StatusChange, error) {
+	defer m.read(ctx)()
+
+	var out []StatusChange
+	for _, c := range m.history {
+		if c.OrderID == id {
+			out = append(out, c)
+		}
+	}
+
+	return out, nil
+}
+
+// commit сохраняет заказ и строку истории и вызывает hooks; ошибка hook'а возвращает всё как было — как откат транзакции
+func (m *Memory) commit(ctx context.Context, o Order, from string, meta Meta) error {
+	change := StatusChange{
+		ID:        m.lastLog + 1,
+		OrderID:   o.ID,
+		From:      from,
+		To:        o.Status,
+		Actor:     meta.Actor,
+		Reason:    meta.Reason,
+		ChangedAt: o.UpdatedAt,
+	}
+
+	prev, existed := m.orders[o.ID]
+	m.orders[o.ID] = o
+	m.history = append(m.history, change)
+
+	if err := m.Machine.fire(m.inHook(ctx), o, change); err != nil {
+		m.history = m.history[:len(m.history)-1]
+		if existed {
+			m.orders[o.ID] = prev
+		} else {
+			delete(m.orders, o.ID)
+		}
+		return err
+	}
+	m.lastLog = change.ID
+
+	return nil
+}
+
+func (m *Memory) Delete(ctx context.Context, id int) error {
+	unlock, err := m.write(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := m.orders[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.orders, id)
+
+	// ON DELETE CASCADE
+	m.history = slices.DeleteFunc(m.history, func(c StatusChange) bool { return c.OrderID == id })
+
+	return nil
+}
+
+// check повторяет CHECK-ограничения таблицы
+func check(o Order) error {
+	if o.TotalAmount < 0 {
+		return fmt.Errorf("%w: total_amount < 0", ErrInvalid)
+	}
+	if !slices.Contains(Statuses, o.Status) {
+		return fmt.Errorf("%w: неизвестный статус %q", ErrInvalid, o.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package Orders
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+/*
+Репозиторий заказов — замена SimpleSelectQuery / SimpleInsertQuery / ... из databaseBasic.go.
+
+Отличия от учебных функций:
+	- каждый метод принимает context.Context: запрос отменяется вместе с HTTP-запросом или по таймауту;
+	- ошибки возвращаются, а не печатаются, и приводятся к типизированным: ErrNotFound, ErrConflict, ErrInvalid —
+	  вызывающему коду не нужно разбирать коды Postgres или SQLite, достаточно errors.Is;
+	- id и время создания/изменения база возвращает сама через RETURNING, часы приложения в это не вмешиваются;
+	- запросы готовятся один раз (Prepare) в конструкторе, а не разбираются заново при каждом вызове.
+
+Две реализации: SQL — настоящая (Postgres или SQLite, см. NewSQL и пакет Dialect), Memory — для тестов и примеров без базы.
+Поведение у них одинаковое, включая ошибки, поэтому код, проверенный на Memory, так же работает на базе.
+*/
+
+var (
+	ErrNotFound = errors.New("orders: заказ не найден")
+	ErrConflict = errors.New("orders: конфликт — запись изменена или уже существует")
+	ErrInvalid  = errors.New("orders: нарушено ограничение данных")
+)
+
+// Statuses — допустимые значения orders.status (CHECK в миграции 0006), переходы между ними — в Machine
+var Statuses = []string{StatusPending, StatusPaid, StatusShipped, StatusDelivered, StatusCancelled, StatusRefunded}
+
+type Order struct {
+	ID          int
+	UserID      int
+	TotalAmount float64
+	Status      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Filter для List; нулевые поля не ограничивают выборку
+type Filter struct {
+	UserID int
+	Status string
+	Limit  int // 0 — DefaultLimit
+	Offset int
+}
+
+const DefaultLimit = 100
+
+type OrderRepository interface {
+	Get(ctx context.Context, id int) (Order, error)
+	List(ctx context.Context, f Filter) ([]Order, error)
+	// Create возвращает заказ с id, created_at и updated_at из базы; статус — только начальный (пустой = "pending")
+	Create(ctx context.Context, o Order) (Order, error)
+	// Update перезаписывает user_id, total_amount и status одной транзакцией; смена статуса проверяется Machine
+	// и пишется в историю (автор и причина — из WithMeta), отвергнутый переход не сохраняет и остальные поля.
+	// Если o.UpdatedAt задан, это оптимистическая блокировка: заказ, изменённый кем-то после чтения, даст ErrConflict
+	Update(ctx context.Context, o Order) (Order, error)
+	Delete(ctx context.Context, id int) error
+
+	// Transition переводит заказ в статус to; недопустимый переход — *TransitionError
+	Transition(ctx context.Context, id int, to string, meta Meta) (Order, error)
+	// History — все смены статуса заказа по порядку, начиная с создания
+	History(ctx context.Context, id int) ([]StatusChange, error)
+}
+
+func (f Filter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultLimit
+	}
+
+	return f.Limit
+}
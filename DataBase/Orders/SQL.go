@@ -0,0 +1,310 @@
+package Orders
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Scanner"
+	"learning/DataBase/Transactions"
+)
+
+var _ OrderRepository = (*SQL)(nil)
+
+const columns = "id, user_id, total_amount, status, created_at, updated_at"
+
+type SQL struct {
+	Machine *Machine
+
+	runner  *Transactions.Runner
+	dialect Dialect.Dialect
+	tx      *sql.Tx // не nil у репозитория из InTx
+	stmts   statements
+	owner   bool // statements подготовлены этим экземпляром и закрываются в Close
+}
+
+type statements struct {
+	get, getForUpdate, list, create, update, del, history, addHistory *sql.Stmt
+}
+
+func (s statements) all() []*sql.Stmt {
+	return []*sql.Stmt{s.get, s.getForUpdate, s.list, s.create, s.update, s.del, s.history, s.addHistory}
+}
+
+// NewPostgres — NewSQL с диалектом Postgres
+func NewPostgres(ctx context.Context, db *sql.DB) (*SQL, error) {
+	return NewSQL(ctx, db, Dialect.Postgres)
+}
+
+/*
+NewSQL готовит все запросы репозитория для диалекта d; Close освобождает их.
+
+Запросы написаны один раз: $n переводит в плейсхолдеры диалекта Rebind, NOW() и FOR UPDATE — Now() и ForUpdate().
+На SQLite FOR UPDATE нет: чтение и запись в одной транзакции там и так не пересекаются с чужими записями,
+а если соседняя транзакция успела взять блокировку записи, SQLITE_BUSY повторяется через Transactions.WithTx.
+*/
+func NewSQL(ctx context.Context, db *sql.DB, d Dialect.Dialect) (*SQL, error) {
+	if !d.Returning() {
+		return nil, fmt.Errorf("orders: диалект %s без RETURNING не поддерживается", d.Name())
+	}
+
+	r := &SQL{Machine: NewMachine(), runner: Transactions.New(db), dialect: d, owner: true}
+	s := &r.stmts
+
+	queries := []struct {
+		stmt **sql.Stmt
+		sql  string
+	}{
+		{&s.get, "SELECT " + columns + " FROM orders WHERE id = $1"},
+		{&s.getForUpdate, "SELECT " + columns + " FROM orders WHERE id = $1" + d.ForUpdate()},
+		// NULL в параметре — фильтр не задан, так один подготовленный запрос покрывает все сочетания фильтров
+		{&s.list, "SELECT " + columns + ` FROM orders
+			WHERE (CAST($1 AS INTEGER) IS NULL OR user_id = $1)
+			  AND (CAST($2 AS TEXT) IS NULL OR status = $2)
+			ORDER BY id
+			LIMIT $3 OFFSET $4`},
+		{&s.create, `INSERT INTO orders (user_id, total_amount, status, created_at, updated_at)
+			VALUES ($1, $2, $3, ` + d.Now() + ", " + d.Now() + `)
+			RETURNING ` + columns},
+		{&s.update, `UPDATE orders SET user_id = $2, total_amount = $3, status = $4, updated_at = ` + d.Now() + `
+			WHERE id = $1
+			RETURNING ` + columns},
+		{&s.del, "DELETE FROM orders WHERE id = $1"},
+		{&s.history, `SELECT id, order_id, COALESCE(from_status, '') AS from_status, to_status, actor, reason, changed_at
+			FROM order_status_history WHERE order_id = $1 ORDER BY id`},
+		{&s.addHistory, `INSERT INTO order_status_history (order_id, from_status, to_status, actor, reason)
+			VALUES ($1, NULLIF($2, ''), $3, $4, $5)
+			RETURNING id, changed_at`},
+	}
+
+	for _, q := range queries {
+		stmt, err := db.PrepareContext(ctx, d.Rebind(q.sql))
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("orders: prepare: %w", err)
+		}
+		*q.stmt = stmt
+	}
+
+	return r, nil
+}
+
+// InTx возвращает репозиторий, который выполняет те же подготовленные запросы внутри транзакции tx
+func (r *SQL) InTx(ctx context.Context, tx *sql.Tx) *SQL {
+	bind := func(s *sql.Stmt) *sql.Stmt { return tx.StmtContext(ctx, s) }
+	s := r.stmts
+
+	return &SQL{
+		Machine: r.Machine,
+		runner:  r.runner,
+		dialect: r.dialect,
+		tx:      tx,
+		stmts: statements{
+			get:          bind(s.get),
+			getForUpdate: bind(s.getForUpdate),
+			list:         bind(s.list),
+			create:       bind(s.create),
+			update:       bind(s.update),
+			del:          bind(s.del),
+			history:      bind(s.history),
+			addHistory:   bind(s.addHistory),
+		},
+	}
+}
+
+func (r *SQL) Close() error {
+	if !r.owner {
+		return nil
+	}
+
+	var errs []error
+	for _, s := range r.stmts.all() {
+		if s != nil {
+			errs = append(errs, s.Close())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *SQL) Get(ctx context.Context, id int) (Order, error) {
+	return r.scanOrder(r.stmts.get.QueryRowContext(ctx, id))
+}
+
+func (r *SQL) List(ctx context.Context, f Filter) ([]Order, error) {
+	var userID, status any
+	if f.UserID != 0 {
+		userID = f.UserID
+	}
+	if f.Status != "" {
+		status = f.Status
+	}
+
+	rows, err := r.stmts.list.QueryContext(ctx, userID, status, f.limit(), f.Offset)
+	if err != nil {
+		return nil, r.classify(err)
+	}
+
+	orders, err := Scanner.ScanAll[Order](rows)
+
+	return orders, r.classify(err)
+}
+
+func (r *SQL) Create(ctx context.Context, o Order) (created Order, err error) {
+	if o.Status == "" {
+		o.Status = r.Machine.Initial()
+	}
+
+	err = r.inTx(ctx, func(ctx context.Context, r *SQL) error {
+		if err := r.Machine.check(ctx, o, "", o.Status); err != nil {
+			return err
+		}
+
+		created, err = r.scanOrder(r.stmts.create.QueryRowContext(ctx, o.UserID, o.TotalAmount, o.Status))
+		if err != nil {
+			return err
+		}
+
+		return r.record(ctx, created, "", Meta{Reason: "created"})
+	})
+
+	return created, err
+}
+
+func (r *SQL) Update(ctx context.Context, o Order) (updated Order, err error) {
+	err = r.inTx(ctx, func(ctx context.Context, r *SQL) error {
+		cur, err := r.scanOrder(r.stmts.getForUpdate.QueryRowContext(ctx, o.ID))
+		if err != nil {
+			return err
+		}
+		if !o.UpdatedAt.IsZero() && !o.UpdatedAt.Equal(cur.UpdatedAt) {
+			return fmt.Errorf("%w: заказ %d изменён после %s", ErrConflict, o.ID, o.UpdatedAt.Format("15:04:05.000"))
+		}
+
+		if o.Status == "" {
+			o.Status = cur.Status
+		}
+		changed := o.Status != cur.Status
+		if changed {
+			if err := r.Machine.check(ctx, o, cur.Status, o.Status); err != nil {
+				return err
+			}
+		}
+
+		updated, err = r.scanOrder(r.stmts.update.QueryRowContext(ctx, o.ID, o.UserID, o.TotalAmount, o.Status))
+		if err != nil || !changed {
+			return err
+		}
+
+		return r.record(ctx, updated, cur.Status, updateMeta(ctx))
+	})
+
+	return updated, err
+}
+
+func (r *SQL) Transition(ctx context.Context, id int, to string, meta Meta) (updated Order, err error) {
+	err = r.inTx(ctx, func(ctx context.Context, r *SQL) error {
+		cur, err := r.scanOrder(r.stmts.getForUpdate.QueryRowContext(ctx, id))
+		if err != nil {
+			return err
+		}
+
+		next := cur
+		next.Status = to
+		if err := r.Machine.check(ctx, next, cur.Status, to); err != nil {
+			return err
+		}
+
+		updated, err = r.scanOrder(r.stmts.update.QueryRowContext(ctx, id, cur.UserID, cur.TotalAmount, to))
+		if err != nil {
+			return err
+		}
+
+		return r.record(ctx, updated, cur.Status, meta)
+	})
+
+	return updated, err
+}
+
+func (r *SQL) History(ctx context.Context, id int) ([]StatusChange, error) {
+	rows, err := r.stmts.history.QueryContext(ctx, id)
+	if err != nil {
+		return nil, r.classify(err)
+	}
+
+	history, err := Scanner.ScanAll[StatusChange](rows)
+
+	return history, r.classify(err)
+}
+
+func (r *SQL) Delete(ctx context.Context, id int) error {
+	res, err := r.stmts.del.ExecContext(ctx, id)
+	if err != nil {
+		return r.classify(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// inTx выполняет fn в транзакции: своей (InTx), внешней из ctx (как SAVEPOINT) или новой
+func (r *SQL) inTx(ctx context.Context, fn func(ctx context.Context, r *SQL) error) error {
+	if r.tx != nil {
+		return fn(ctx, r)
+	}
+
+	return r.runner.WithTx(ctx, Transactions.Options{}, func(ctx context.Context, tx *sql.Tx) error {
+		return fn(ctx, r.InTx(ctx, tx))
+	})
+}
+
+// record пишет смену статуса в историю и вызывает hooks — всё в текущей транзакции
+func (r *SQL) record(ctx context.Context, o Order, from string, meta Meta) error {
+	change := StatusChange{OrderID: o.ID, From: from, To: o.Status, Actor: meta.Actor, Reason: meta.Reason}
+
+	err := r.stmts.addHistory.QueryRowContext(ctx, o.ID, from, o.Status, meta.Actor, meta.Reason).Scan(&change.ID, Scanner.Time(&change.ChangedAt))
+	if err != nil {
+		return r.classify(err)
+	}
+
+	return r.Machine.fire(ctx, o, change)
+}
+
+// scanOrder — для QueryRow: у *sql.Row нет Columns(), поэтому здесь порядок колонок задаёт константа columns
+func (r *SQL) scanOrder(s *sql.Row) (Order, error) {
+	var o Order
+
+	err := s.Scan(&o.ID, &o.UserID, &o.TotalAmount, &o.Status, Scanner.Time(&o.CreatedAt), Scanner.Time(&o.UpdatedAt))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Order{}, ErrNotFound
+	}
+	if err != nil {
+		return Order{}, r.classify(err)
+	}
+
+	return o, nil
+}
+
+// classify переводит ошибки диалекта в ошибки пакета, сохраняя исходную в цепочке
+func (r *SQL) classify(err error) error {
+	err = r.dialect.Classify(err)
+
+	switch {
+	case errors.Is(err, Dialect.ErrUniqueViolation), errors.Is(err, Dialect.ErrSerialization):
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	case errors.Is(err, Dialect.ErrForeignKeyViolation), errors.Is(err, Dialect.ErrCheckViolation),
+		errors.Is(err, Dialect.ErrNotNullViolation), errors.Is(err, Dialect.ErrInvalidValue):
+		return fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	return err
+}
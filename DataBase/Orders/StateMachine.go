@@ -0,0 +1,191 @@
+package Orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+/*
+Статус заказа — конечный автомат, а не произвольная строка:
+
+	pending ──> paid ──> shipped ──> delivered
+	   │          │                      │
+	   └─> cancelled <┘      paid, delivered ──> refunded
+
+Переход проверяется в репозитории (Transition и Update), внутри той же транзакции, что и изменение заказа:
+	1. переход объявлен в Machine — иначе *TransitionError с ErrIllegalTransition;
+	2. все guard'ы целевого статуса разрешили его — иначе *TransitionError с ошибкой guard'а;
+	3. строка пишется в order_status_history;
+	4. вызываются hooks — например, запись события в outbox. Ошибка hook'а откатывает весь переход.
+Транзакция доступна hook'ам через Transactions.FromContext(ctx).
+*/
+
+const (
+	StatusPending   = "pending"
+	StatusPaid      = "paid"
+	StatusShipped   = "shipped"
+	StatusDelivered = "delivered"
+	StatusCancelled = "cancelled"
+	StatusRefunded  = "refunded"
+)
+
+var ErrIllegalTransition = errors.New("orders: недопустимый переход статуса")
+
+type TransitionError struct {
+	OrderID  int
+	From, To string
+	Err      error // ErrIllegalTransition или ошибка guard'а
+}
+
+func (e *TransitionError) Error() string {
+	from := e.From
+	if from == "" {
+		from = "(новый)"
+	}
+
+	return fmt.Sprintf("orders: заказ %d: %s -> %s: %v", e.OrderID, from, e.To, e.Err)
+}
+
+func (e *TransitionError) Unwrap() error { return e.Err }
+
+// StatusChange — строка order_status_history
+type StatusChange struct {
+	ID        int
+	OrderID   int
+	From      string `db:"from_status"` // пусто для создания заказа
+	To        string `db:"to_status"`
+	Actor     string
+	Reason    string
+	ChangedAt time.Time
+}
+
+// Meta — кто и почему меняет статус, попадает в историю
+type Meta struct {
+	Actor  string
+	Reason string
+}
+
+type metaKey struct{}
+
+// WithMeta — Meta для смены статуса через Update: у Update, в отличие от Transition, параметра meta нет,
+// и без WithMeta в историю пишется Reason "update" без автора
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+func updateMeta(ctx context.Context) Meta {
+	if meta, ok := ctx.Value(metaKey{}).(Meta); ok {
+		return meta
+	}
+
+	return Meta{Reason: "update"}
+}
+
+type Guard func(ctx context.Context, o Order) error
+
+type Hook func(ctx context.Context, o Order, change StatusChange) error
+
+type Machine struct {
+	initial     string
+	transitions map[string][]string
+
+	mu     sync.RWMutex
+	guards map[string][]Guard
+	hooks  []Hook
+}
+
+// NewMachine — автомат статусов заказа с guard'ом "оплатить можно только непустой заказ"
+func NewMachine() *Machine {
+	m := &Machine{
+		initial: StatusPending,
+		transitions: map[string][]string{
+			StatusPending:   {StatusPaid, StatusCancelled},
+			StatusPaid:      {StatusShipped, StatusCancelled, StatusRefunded},
+			StatusShipped:   {StatusDelivered},
+			StatusDelivered: {StatusRefunded},
+		},
+		guards: make(map[string][]Guard),
+	}
+
+	m.Guard(StatusPaid, func(_ context.Context, o Order) error {
+		if o.TotalAmount <= 0 {
+			return errors.New("нельзя оплатить заказ с нулевой суммой")
+		}
+		return nil
+	})
+
+	return m
+}
+
+func (m *Machine) Initial() string {
+	return m.initial
+}
+
+// Can — объявлен ли переход (без guard'ов)
+func (m *Machine) Can(from, to string) bool {
+	return slices.Contains(m.transitions[from], to)
+}
+
+// Next — куда можно перейти из статуса
+func (m *Machine) Next(from string) []string {
+	return slices.Clone(m.transitions[from])
+}
+
+// Final — статус, из которого переходов нет
+func (m *Machine) Final(status string) bool {
+	return len(m.transitions[status]) == 0
+}
+
+// Guard добавляет условие для перехода в статус to
+func (m *Machine) Guard(to string, g Guard) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.guards[to] = append(m.guards[to], g)
+}
+
+// OnTransition добавляет hook, который вызывается после записи в историю, до commit
+func (m *Machine) OnTransition(h Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, h)
+}
+
+// check проверяет переход заказа o (в текущем статусе) в статус to; o передаётся guard'ам уже с новыми полями
+func (m *Machine) check(ctx context.Context, o Order, from, to string) error {
+	legal := from == "" && to == m.initial || m.Can(from, to)
+	if !legal {
+		return &TransitionError{OrderID: o.ID, From: from, To: to, Err: ErrIllegalTransition}
+	}
+
+	m.mu.RLock()
+	guards := slices.Clone(m.guards[to])
+	m.mu.RUnlock()
+
+	for _, g := range guards {
+		if err := g(ctx, o); err != nil {
+			return &TransitionError{OrderID: o.ID, From: from, To: to, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func (m *Machine) fire(ctx context.Context, o Order, change StatusChange) error {
+	m.mu.RLock()
+	hooks := slices.Clone(m.hooks)
+	m.mu.RUnlock()
+
+	for _, h := range hooks {
+		if err := h(ctx, o, change); err != nil {
+			return fmt.Errorf("orders: hook перехода %s -> %s: %w", change.From, change.To, err)
+		}
+	}
+
+	return nil
+}
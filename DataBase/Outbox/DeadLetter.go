@@ -0,0 +1,65 @@
+package Outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"learning/DataBase/Query"
+	"learning/DataBase/Scanner"
+)
+
+var ErrNotDead = errors.New("outbox: сообщения нет среди dead letters")
+
+// DeadLetters — сообщения, которые relay бросил, от новых к старым; limit <= 0 — все
+func (o *Outbox) DeadLetters(ctx context.Context, db Querier, limit int) ([]Message, error) {
+	sel := Query.Select(slices.Concat(messageColumns, []string{"dead_at"})...).
+		From("outbox").
+		Where(Query.IsNotNull("dead_at")).
+		OrderBy("id DESC")
+	if limit > 0 {
+		sel = sel.Limit(limit)
+	}
+
+	q, args, err := sel.ToSQL(o.dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: dead letters: %w", err)
+	}
+
+	return Scanner.ScanAll[Message](rows)
+}
+
+// Requeue возвращает dead letter в очередь с чистым счётчиком попыток — после того, как причину исправили
+func (o *Outbox) Requeue(ctx context.Context, db Querier, id int64) error {
+	q, args, err := Query.Update("outbox").
+		Set("dead_at", nil).
+		Set("attempts", 0).
+		Set("available_at", o.dialect.Time(time.Now().UTC())).
+		Where(Query.Eq("id", id), Query.IsNotNull("dead_at")).
+		ToSQL(o.dialect)
+	if err != nil {
+		return err
+	}
+
+	res, err := db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("outbox: requeue %d: %w", id, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %d", ErrNotDead, id)
+	}
+
+	return nil
+}
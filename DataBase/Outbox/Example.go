@@ -0,0 +1,108 @@
+package Outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"learning/DataBase/Fake"
+	"learning/DataBase/Transactions"
+)
+
+/*
+Примеры на Fake-драйвере: видно, какие запросы уходят в базу. С настоящим Postgres (docker-compose, миграция 0007):
+
+	db, _ := Connection.Open()
+	ob, _ := Outbox.New(Connection.Driver())
+	relay := ob.NewRelay(db, publisher, Outbox.RelayOptions{})
+	go relay.Run(ctx)
+*/
+
+func outboxRows() *Fake.Rows {
+	return Fake.NewRows("id", "topic", "partition_key", "payload", "created_at", "attempts", "last_error")
+}
+
+// ExampleEnqueue — заказ и событие о нём в одной транзакции
+func ExampleEnqueue() {
+	ctx := context.Background()
+	db, mock := Fake.New(nil)
+	defer db.Close()
+
+	ob, err := New("postgres")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^INSERT INTO orders`).WillReturnRows(Fake.NewRows("id").Add(42))
+	mock.ExpectQuery(`^INSERT INTO outbox \(topic, partition_key, payload, created_at, available_at\) VALUES \(\$1, \$2, \$3, \$4, \$5\) RETURNING id$`).
+		WithArgs("order.created", "42", `{"order_id":42,"user_id":1,"total_amount":0,"items":null}`, Fake.AnyArg(), Fake.AnyArg()).
+		WillReturnRows(Fake.NewRows("id").Add(1))
+	mock.ExpectCommit()
+
+	err = Transactions.New(db).WithTx(ctx, Transactions.Options{}, func(ctx context.Context, tx *sql.Tx) error {
+		var orderID int
+		if err := tx.QueryRowContext(ctx, "INSERT INTO orders (user_id) VALUES ($1) RETURNING id", 1).Scan(&orderID); err != nil {
+			return err
+		}
+
+		_, err := ob.EnqueueContext(ctx, "order.created", fmt.Sprint(orderID), OrderCreated{OrderID: orderID, UserID: 1})
+		return err
+	})
+	fmt.Println("транзакция:", err)
+
+	// вне транзакции записать событие нельзя
+	_, err = ob.EnqueueContext(ctx, "order.created", "43", OrderCreated{OrderID: 43})
+	fmt.Println(err)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// ExampleRelay — одна пачка: первое сообщение доставлено, второе отложено до следующей попытки, третье — dead letter
+func ExampleRelay() {
+	ctx := context.Background()
+	db, mock := Fake.New(nil)
+	defer db.Close()
+
+	ob, err := New("postgres")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Пачка арендуется одним UPDATE: строки, которые сейчас арендует другой relay, пропускаются (SKIP LOCKED)
+	now := time.Now()
+	mock.ExpectQuery(`^UPDATE outbox SET available_at = \$1 WHERE id IN \(SELECT id FROM outbox WHERE .* FOR UPDATE SKIP LOCKED\) RETURNING id, topic, partition_key, payload, created_at, attempts, last_error$`).
+		WillReturnRows(outboxRows().
+			Add(1, "order.created", "42", `{"order_id":42}`, now, 0, "").
+			Add(2, "order.created", "43", `{"order_id":43}`, now, 0, "").
+			Add(3, "order.created", "44", `{"order_id":44`, now, 2, "timeout"))
+	mock.ExpectExec(`^UPDATE outbox SET attempts = \$1, published_at = \$2, last_error = \$3 WHERE id = \$4$`).
+		WithArgs(1, Fake.AnyArg(), "", 1).WillReturnResult(0, 1)
+	mock.ExpectExec(`^UPDATE outbox SET attempts = \$1, available_at = \$2, last_error = \$3 WHERE id = \$4$`).
+		WithArgs(1, Fake.AnyArg(), "broker unavailable", 2).WillReturnResult(0, 1)
+	mock.ExpectExec(`^UPDATE outbox SET attempts = \$1, dead_at = \$2, last_error = \$3 WHERE id = \$4$`).
+		WithArgs(3, Fake.AnyArg(), "битый payload", 3).WillReturnResult(0, 1)
+
+	publisher := PublisherFunc(func(ctx context.Context, m Message) error {
+		switch m.ID {
+		case 2:
+			return errors.New("broker unavailable")
+		case 3:
+			return Permanent(errors.New("битый payload"))
+		}
+		fmt.Printf("опубликовано %d %s %s\n", m.ID, m.Topic, m.Payload)
+		return nil
+	})
+
+	n, err := ob.NewRelay(db, publisher, RelayOptions{}).RelayOnce(ctx)
+	fmt.Println("в пачке:", n, err)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		log.Fatal(err)
+	}
+}
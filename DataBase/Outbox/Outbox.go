@@ -0,0 +1,190 @@
+package Outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Orders"
+	"learning/DataBase/Query"
+	"learning/DataBase/Transactions"
+)
+
+/*
+Transactional outbox — как сообщить другим системам о заказе и не потерять событие.
+
+Наивно: закоммитить заказ, потом отправить событие в брокер. Процесс упал между commit и отправкой — событие потеряно;
+отправили до commit, а транзакция откатилась — все узнали о заказе, которого нет.
+
+Outbox: событие — это строка в таблице outbox (миграция 0007), и пишется она в той же транзакции, что и заказ с позициями.
+Закоммитилось одно — закоммитилось и другое. Публикует отдельный Relay: забирает неотправленные строки,
+отдаёт их Publisher'у и помечает отправленными.
+
+	ob, _ := Outbox.New(Connection.Driver())
+	repo.Machine.OnTransition(ob.OrderHook()) // смены статуса Orders.SQL — событиями order.status_changed
+
+	runner.WithTx(ctx, opts, func(ctx context.Context, tx *sql.Tx) error {
+		order, err := repo.InTx(ctx, tx).Create(ctx, Orders.Order{UserID: 1})
+		... INSERT INTO order_items ...
+		_, err = ob.Enqueue(ctx, tx, "order.created", strconv.Itoa(order.ID), event)
+		return err
+	})
+
+	relay := ob.NewRelay(db, publisher, Outbox.RelayOptions{})
+	go relay.Run(ctx)
+
+Гарантии:
+	- at-least-once: Relay может упасть после Publish, но до пометки — сообщение уйдёт ещё раз.
+	  Получатель должен быть идемпотентным, ключ для дедупликации — Message.ID;
+	- порядок по ключу: сообщения с одним Key (например, id заказа) публикуются строго по очереди —
+	  следующее не берётся, пока предыдущее не отправлено или не ушло в dead letter. Пустой Key — порядок не важен;
+	- неудачная публикация повторяется с растущей паузой (RelayOptions.Backoff), число попыток и последняя ошибка
+	  хранятся в строке; после MaxAttempts или ошибки Permanent(err) сообщение — dead letter:
+	  dead_at заполнен, relay его больше не берёт. Посмотреть — DeadLetters, вернуть в очередь — Requeue.
+
+Enqueue принимает *sql.Tx, а не *sql.DB: записать событие мимо транзакции не получится.
+*/
+
+var ErrNoTx = errors.New("outbox: событие можно записать только внутри транзакции")
+
+// Message — строка outbox
+type Message struct {
+	ID          int64
+	Topic       string
+	Key         string `db:"partition_key"` // порядок публикации гарантируется внутри одного ключа
+	Payload     []byte // JSON
+	CreatedAt   time.Time
+	Attempts    int    // сколько раз уже пытались опубликовать
+	LastError   string // ошибка последней попытки
+	PublishedAt *time.Time
+	DeadAt      *time.Time
+}
+
+// Outbox знает диалект базы: плейсхолдеры, время и то, как Relay забирает сообщения (см. Relay.go)
+type Outbox struct {
+	dialect Dialect.Dialect
+}
+
+func New(driver string) (*Outbox, error) {
+	d, err := Dialect.For(driver)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: %w", err)
+	}
+
+	return &Outbox{dialect: d}, nil
+}
+
+// Enqueue записывает событие в outbox в транзакции tx; payload — []byte / json.RawMessage с готовым JSON или значение для json.Marshal
+func (o *Outbox) Enqueue(ctx context.Context, tx *sql.Tx, topic, key string, payload any) (int64, error) {
+	if tx == nil {
+		return 0, ErrNoTx
+	}
+
+	body, err := encode(payload)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: %s: %w", topic, err)
+	}
+
+	now := time.Now().UTC()
+	// payload строкой: []byte lib/pq отправил бы как bytea, и jsonb его не принял бы
+	q, args, err := Query.Insert("outbox").
+		Columns("topic", "partition_key", "payload", "created_at", "available_at").
+		Values(topic, key, string(body), o.dialect.Time(now), o.dialect.Time(now)).
+		Returning("id").
+		ToSQL(o.dialect)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	if err := tx.QueryRowContext(ctx, q, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("outbox: запись %s: %w", topic, err)
+	}
+
+	return id, nil
+}
+
+// EnqueueContext — Enqueue в транзакции из ctx (Transactions.WithTx); вне транзакции — ErrNoTx
+func (o *Outbox) EnqueueContext(ctx context.Context, topic, key string, payload any) (int64, error) {
+	tx, ok := Transactions.FromContext(ctx)
+	if !ok {
+		return 0, ErrNoTx
+	}
+
+	return o.Enqueue(ctx, tx, topic, key, payload)
+}
+
+func encode(payload any) ([]byte, error) {
+	var body []byte
+	switch p := payload.(type) {
+	case json.RawMessage:
+		body = p
+	case []byte:
+		body = p
+	default:
+		return json.Marshal(payload)
+	}
+
+	if !json.Valid(body) {
+		return nil, errors.New("payload — не JSON")
+	}
+
+	return body, nil
+}
+
+// OrderCreated — событие order.created
+type OrderCreated struct {
+	OrderID     int         `json:"order_id"`
+	UserID      int         `json:"user_id"`
+	TotalAmount float64     `json:"total_amount"`
+	Items       []OrderItem `json:"items"`
+}
+
+type OrderItem struct {
+	ProductID int     `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+}
+
+// StatusChanged — событие order.status_changed
+type StatusChanged struct {
+	OrderID int       `json:"order_id"`
+	From    string    `json:"from,omitempty"`
+	To      string    `json:"to"`
+	Actor   string    `json:"actor,omitempty"`
+	Reason  string    `json:"reason,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+/*
+OrderHook — hook для Orders.Machine: каждый переход статуса становится событием order.status_changed с ключом id заказа.
+
+	repo.Machine.OnTransition(ob.OrderHook())
+
+Hook'и Orders.SQL вызываются внутри транзакции перехода, поэтому событие коммитится вместе с новым статусом.
+У Orders.Memory транзакции нет — там hook вернёт ErrNoTx и переход не состоится.
+*/
+func (o *Outbox) OrderHook() Orders.Hook {
+	return func(ctx context.Context, order Orders.Order, change Orders.StatusChange) error {
+		at := change.ChangedAt
+		if at.IsZero() {
+			at = time.Now()
+		}
+
+		_, err := o.EnqueueContext(ctx, "order.status_changed", strconv.Itoa(order.ID), StatusChanged{
+			OrderID: order.ID,
+			From:    change.From,
+			To:      change.To,
+			Actor:   change.Actor,
+			Reason:  change.Reason,
+			At:      at.UTC(),
+		})
+
+		return err
+	}
+}
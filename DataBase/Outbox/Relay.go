@@ -0,0 +1,214 @@
+package Outbox
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"learning/DataBase/Query"
+	"learning/DataBase/Scanner"
+)
+
+/*
+Relay забирает сообщения из outbox и публикует их.
+
+Держать транзакцию, пока идёт публикация, нельзя: в SQLite один писатель на всю базу, и создание заказов встало бы,
+а в Postgres долгая транзакция держит блокировки строк. Поэтому пачка "арендуется": один UPDATE ... RETURNING переносит
+available_at на now + Lease и возвращает строки, а публикация и пометка идут уже без транзакции. Другой relay арендованные
+строки не возьмёт, пока аренда не истекла, — можно запустить несколько relay'ев, и каждое сообщение достанется одному из них.
+Строки для аренды выбираются с Query.SkipLocked: в Postgres это FOR UPDATE SKIP LOCKED, и строки, которые в этот момент
+арендует другой relay, пропускаются, а не ждутся; в SQLite блокировок строк нет, и такой UPDATE там и так выполняется один.
+Упал посреди пачки — через Lease её сообщения снова доступны. Lease должна быть больше времени публикации пачки.
+*/
+
+// Publisher отправляет сообщение наружу: брокер, webhook, другой сервис. nil — доставлено
+type Publisher interface {
+	Publish(ctx context.Context, m Message) error
+}
+
+type PublisherFunc func(ctx context.Context, m Message) error
+
+func (f PublisherFunc) Publish(ctx context.Context, m Message) error { return f(ctx, m) }
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent — повторять бессмысленно (битый payload, неизвестный topic): сообщение сразу уходит в dead letter
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type RelayOptions struct {
+	BatchSize    int                             // сообщений за один заход, по умолчанию 100
+	PollInterval time.Duration                   // пауза, когда очередь пуста, по умолчанию секунда
+	MaxAttempts  int                             // после стольких неудач — dead letter, по умолчанию 10
+	Backoff      func(attempt int) time.Duration // пауза перед попыткой attempt+1, по умолчанию DefaultBackoff
+	Lease        time.Duration                   // аренда пачки, по умолчанию минута
+	Logger       *log.Logger                     // ошибки Run; по умолчанию log.Default()
+}
+
+// DefaultBackoff — 1s, 2s, 4s, ... но не больше 5 минут
+func DefaultBackoff(attempt int) time.Duration {
+	d := time.Second << min(attempt-1, 9)
+	return min(d, 5*time.Minute)
+}
+
+type Relay struct {
+	outbox *Outbox
+	db     *sql.DB
+	pub    Publisher
+	opts   RelayOptions
+	now    func() time.Time
+}
+
+func (o *Outbox) NewRelay(db *sql.DB, pub Publisher, opts RelayOptions) *Relay {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 10
+	}
+	if opts.Backoff == nil {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = time.Minute
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+
+	return &Relay{outbox: o, db: db, pub: pub, opts: opts, now: func() time.Time { return time.Now().UTC() }}
+}
+
+// Run публикует, пока не отменён ctx: полная пачка — сразу следующая, неполная — пауза PollInterval
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.opts.Logger.Println("outbox:", err)
+		}
+		if n == r.opts.BatchSize && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
+
+var messageColumns = []string{"id", "topic", "partition_key", "payload", "created_at", "attempts", "last_error"}
+
+// pending — готовые к отправке сообщения, у которых нет более раннего неотправленного сообщения с тем же ключом
+func (r *Relay) pending(now time.Time, columns ...string) Query.SelectBuilder {
+	earlier := Query.Select("1").From("outbox p").Where(
+		Query.Raw("p.partition_key = outbox.partition_key"),
+		Query.Raw("p.id < outbox.id"),
+		Query.IsNull("p.published_at"),
+		Query.IsNull("p.dead_at"),
+	)
+
+	return Query.Select(columns...).
+		From("outbox").
+		Where(
+			Query.IsNull("published_at"),
+			Query.IsNull("dead_at"),
+			Query.Le("available_at", r.outbox.dialect.Time(now)),
+			Query.Or(Query.Eq("partition_key", ""), Query.NotExists(earlier)),
+		).
+		OrderBy("id").
+		Limit(r.opts.BatchSize)
+}
+
+// RelayOnce обрабатывает одну пачку и возвращает, сколько сообщений в ней было (доставленных и нет)
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	now := r.now()
+
+	claim := Query.Update("outbox").
+		Set("available_at", r.outbox.dialect.Time(now.Add(r.opts.Lease))).
+		Where(Query.InSelect("id", r.pending(now, "id").SkipLocked())).
+		Returning(messageColumns...)
+
+	q, args, err := claim.ToSQL(r.outbox.dialect)
+	if err != nil {
+		return 0, err
+	}
+
+	msgs, err := fetch(ctx, r.db, q, args)
+	if err != nil {
+		return 0, err
+	}
+	// порядок строк RETURNING не определён
+	slices.SortFunc(msgs, func(a, b Message) int { return cmp.Compare(a.ID, b.ID) })
+
+	for _, m := range msgs {
+		if err := r.deliver(ctx, r.db, m); err != nil {
+			return len(msgs), err
+		}
+	}
+
+	return len(msgs), nil
+}
+
+// Querier — *sql.DB или *sql.Tx
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func fetch(ctx context.Context, db Querier, q string, args []any) ([]Message, error) {
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: выборка: %w", err)
+	}
+
+	return Scanner.ScanAll[Message](rows)
+}
+
+// deliver публикует одно сообщение и записывает результат: отправлено, повторить позже или dead letter
+func (r *Relay) deliver(ctx context.Context, db Querier, m Message) error {
+	pubErr := r.pub.Publish(ctx, m)
+	if pubErr != nil && ctx.Err() != nil {
+		return ctx.Err() // остановились посреди пачки — это не неудача сообщения
+	}
+
+	d := r.outbox.dialect
+	now := r.now()
+	attempts := m.Attempts + 1
+	upd := Query.Update("outbox").Set("attempts", attempts).Where(Query.Eq("id", m.ID))
+
+	var permanent *permanentError
+	switch {
+	case pubErr == nil:
+		upd = upd.Set("published_at", d.Time(now)).Set("last_error", "")
+	case errors.As(pubErr, &permanent) || attempts >= r.opts.MaxAttempts:
+		upd = upd.Set("dead_at", d.Time(now)).Set("last_error", pubErr.Error())
+	default:
+		upd = upd.Set("available_at", d.Time(now.Add(r.opts.Backoff(attempts)))).Set("last_error", pubErr.Error())
+	}
+
+	q, args, err := upd.ToSQL(r.outbox.dialect)
+	if err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("outbox: сообщение %d: %w", m.ID, err)
+	}
+
+	return nil
+}
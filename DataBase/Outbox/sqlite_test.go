@@ -0,0 +1,112 @@
+//go:build sqlite
+
+package Outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"learning/DataBase/Connection"
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Migrations"
+	"learning/DataBase/Orders"
+)
+
+// openSQLite — свой файл SQLite во временном каталоге, открытый так же, как при DB_DRIVER=sqlite, со всеми миграциями
+func openSQLite(t *testing.T) (*sql.DB, Dialect.Dialect) {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DATABASE_URL", filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+
+	db, err := Connection.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	d, err := Dialect.For(Connection.Driver())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Migrations.New(db, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Log = log.New(io.Discard, "", 0)
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.ExecContext(ctx, "INSERT INTO users (id, username, email) VALUES (1, 'daniil', 'daniil@example.com')"); err != nil {
+		t.Fatal(err)
+	}
+
+	return db, d
+}
+
+// Переходы Orders.SQL и события outbox коммитятся вместе; отвергнутый переход не оставляет ни статуса, ни события
+func TestOrderEventsSQLite(t *testing.T) {
+	ctx := context.Background()
+	db, d := openSQLite(t)
+
+	ob, err := New(Connection.Driver())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := Orders.NewSQL(ctx, db, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	repo.Machine.OnTransition(ob.OrderHook())
+
+	o, err := repo.Create(ctx, Orders.Order{UserID: 1, TotalAmount: 999.99})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Transition(ctx, o.ID, Orders.StatusPaid, Orders.Meta{Actor: "test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Transition(ctx, o.ID, Orders.StatusPending, Orders.Meta{}); !errors.Is(err, Orders.ErrIllegalTransition) {
+		t.Fatalf("paid -> pending: %v, ожидалась ErrIllegalTransition", err)
+	}
+
+	history, err := repo.History(ctx, o.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("история: %d записей, ожидалось 2", len(history))
+	}
+
+	var published []StatusChanged
+	relay := ob.NewRelay(db, PublisherFunc(func(ctx context.Context, m Message) error {
+		var e StatusChanged
+		if err := json.Unmarshal(m.Payload, &e); err != nil {
+			return err
+		}
+		published = append(published, e)
+		return nil
+	}), RelayOptions{})
+
+	// Сообщения одного заказа публикуются по очереди: следующее — в следующей пачке
+	for range 3 {
+		if _, err := relay.RelayOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(published) != 2 || published[0].To != Orders.StatusPending || published[1].From != Orders.StatusPending || published[1].To != Orders.StatusPaid {
+		t.Fatalf("опубликовано %+v", published)
+	}
+}
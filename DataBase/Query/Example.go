@@ -0,0 +1,147 @@
+package Query
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"learning/DataBase/Connection"
+	"learning/DataBase/Dialect"
+)
+
+// ExampleCheatSheet — примеры из шпаргалки refreshKnowledge.go, собранные построителем
+func ExampleCheatSheet() {
+	queries := []Builder{
+		Select().From("products"),                   // (1)
+		Select("product", "price").From("products"), // (2)
+		Select(`price AS "цена"`, `quantity AS "кол-во товара"`).From("products"), // (3)
+		Select().From("products").Where(Gt("price", 100)),                         // (4)
+		Select().From("products").Where(Gt("price", 100), Lt("quantity", 10)),     // (5)
+		Select().From("products").Where(Or(Gt("price", 100), Lt("quantity", 10))), // (6)
+		Select().From("products").Where(In("category", "electronics", "books")),   // (7)
+		Select().From("users").Where(Like("name", "%Даниил%")),                    // (8)
+		Select().From("users").Where(Between("name", "Борис", "Дмитрий")),         // (9)
+		Select().From("products").OrderBy("id DESC"),                              // (10)
+		Select().From("products").Limit(10),                                       // (11)
+		Select("COUNT(*)").From("products").Where(Gt("price", 100)),               // (12)
+		Select().From("products").Offset(10),                                      // (13)
+
+		// GROUP BY / HAVING / JOIN
+		Select("u.name", "COUNT(*) AS orders", "SUM(o.total_amount) AS total").
+			From("orders o").
+			Join("users u", Eq("u.id", Col("o.user_id"))).
+			Where(Ne("o.status", "cancelled")).
+			GroupBy("u.name").
+			Having(Gt("COUNT(*)", 1)).
+			OrderBy("total DESC"),
+
+		// Подзапрос: пользователи, у которых есть оплаченные заказы
+		Select("id", "name").From("users").
+			Where(InSelect("id", Select("user_id").From("orders").Where(Eq("status", "paid")))),
+
+		Insert("orders").Columns("user_id", "total_amount", "status").
+			Values(2, 1200.25, "pending").
+			Values(3, 99.90, "pending").
+			Returning("id", "created_at"),
+
+		Update("products").Set("price", Raw("1.2 * price")).AllRows(), // (2) из «Добавление данных»
+
+		Update("orders").Set("status", "paid").Set("updated_at", Raw("NOW()")).
+			Where(Eq("id", 42), Eq("status", "pending")).
+			Returning("updated_at"),
+
+		Delete("orders").Where(Eq("status", "cancelled"), Lt("created_at", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))),
+	}
+
+	for _, q := range queries {
+		fmt.Println(String(q))
+	}
+
+	// Ошибка, а не UPDATE всей таблицы
+	if _, _, err := Update("orders").Set("status", "cancelled").ToSQL(Dialect.Postgres); err != nil {
+		fmt.Println("ожидаемо:", err)
+	}
+}
+
+// OrderSearch — необязательные фильтры: пустое поле не добавляет условия
+type OrderSearch struct {
+	UserID   int
+	Statuses []string
+	MinTotal float64
+	Since    time.Time
+}
+
+func (f OrderSearch) Query() SelectBuilder {
+	q := Select("id", "user_id", "total_amount", "status", "created_at", "updated_at").
+		From("orders").
+		OrderBy("id DESC").
+		Limit(100)
+
+	if f.UserID != 0 {
+		q = q.Where(Eq("user_id", f.UserID))
+	}
+	if len(f.Statuses) > 0 {
+		q = q.Where(In("status", f.Statuses...))
+	}
+	if f.MinTotal > 0 {
+		q = q.Where(Ge("total_amount", f.MinTotal))
+	}
+	if !f.Since.IsZero() {
+		q = q.Where(Ge("created_at", f.Since))
+	}
+
+	return q
+}
+
+// ExampleOrderSearch — один и тот же поиск для Postgres и SQLite, затем выполнение на базе из Connection
+func ExampleOrderSearch() {
+	search := OrderSearch{UserID: 2, Statuses: []string{"paid", "shipped"}, Since: time.Now().AddDate(0, -1, 0)}
+
+	for _, d := range []Dialect.Dialect{Dialect.Postgres, Dialect.SQLite} {
+		sql, args, err := search.Query().ToSQL(d)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(sql, args)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	db, err := Connection.Open()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	d, err := Dialect.For(Connection.Driver())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sql, args, err := search.Query().ToSQL(d)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rows, err := db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, userID int
+		var total float64
+		var status string
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(&id, &userID, &total, &status, &createdAt, &updatedAt); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(id, userID, total, status)
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
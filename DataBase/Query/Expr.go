@@ -0,0 +1,268 @@
+package Query
+
+import (
+	"fmt"
+	"strings"
+
+	"learning/DataBase/Dialect"
+)
+
+// Expr — кусок SQL с аргументами: условие, колонка, подзапрос
+type Expr interface {
+	build(b *buf)
+}
+
+type column string
+
+// Col — ссылка на колонку там, где ожидается значение: Eq("u.id", Col("o.user_id"))
+func Col(name string) Expr {
+	return column(name)
+}
+
+func (c column) build(b *buf) {
+	b.write(string(c))
+}
+
+type raw struct {
+	sql  string
+	args []any
+}
+
+// Raw — произвольный SQL; каждый ? заменяется плейсхолдером диалекта для очередного аргумента.
+// Строковые литералы с ? внутри сюда не писать — значение передайте аргументом.
+// По той же причине нельзя записать операторы jsonb ?, ?| и ?& — вместо них jsonb_exists(col, ?), jsonb_exists_any(col, ?)
+// и jsonb_exists_all(col, ?)
+func Raw(sql string, args ...any) Expr {
+	return raw{sql: sql, args: args}
+}
+
+func (r raw) build(b *buf) {
+	parts := strings.Split(r.sql, "?")
+	if len(parts)-1 != len(r.args) {
+		b.fail(countError("Raw("+r.sql+")", len(parts)-1, len(r.args)))
+		return
+	}
+
+	b.write(parts[0])
+	for i, arg := range r.args {
+		b.value(arg)
+		b.write(parts[i+1])
+	}
+}
+
+type compare struct {
+	col   string
+	op    string
+	value any
+}
+
+// Eq — col = v; Eq(col, nil) — col IS NULL
+func Eq(col string, v any) Expr { return compare{col, "=", v} }
+
+// Ne — col <> v; Ne(col, nil) — col IS NOT NULL
+func Ne(col string, v any) Expr { return compare{col, "<>", v} }
+
+func Gt(col string, v any) Expr { return compare{col, ">", v} }
+func Ge(col string, v any) Expr { return compare{col, ">=", v} }
+func Lt(col string, v any) Expr { return compare{col, "<", v} }
+func Le(col string, v any) Expr { return compare{col, "<=", v} }
+
+func Like(col, pattern string) Expr    { return compare{col, "LIKE", pattern} }
+func NotLike(col, pattern string) Expr { return compare{col, "NOT LIKE", pattern} }
+
+// ILike — LIKE без учёта регистра, есть только в Postgres
+func ILike(col, pattern string) Expr { return compare{col, "ILIKE", pattern} }
+
+func (c compare) build(b *buf) {
+	if c.value == nil {
+		switch c.op {
+		case "=":
+			b.write(c.col, " IS NULL")
+			return
+		case "<>":
+			b.write(c.col, " IS NOT NULL")
+			return
+		}
+	}
+
+	b.write(c.col, " ", c.op, " ")
+	b.value(c.value)
+}
+
+func IsNull(col string) Expr    { return Raw(col + " IS NULL") }
+func IsNotNull(col string) Expr { return Raw(col + " IS NOT NULL") }
+
+type in struct {
+	col    string
+	not    bool
+	values []any
+	sub    Expr
+}
+
+// In — col IN (v1, v2, ...). Пустой список — заведомо ложное условие, а не синтаксическая ошибка IN ()
+func In[T any](col string, values ...T) Expr {
+	return in{col: col, values: anys(values)}
+}
+
+// NotIn — col NOT IN (...); пустой список — заведомо истинное условие
+func NotIn[T any](col string, values ...T) Expr {
+	return in{col: col, not: true, values: anys(values)}
+}
+
+// InSelect — col IN (SELECT ...)
+func InSelect(col string, q SelectBuilder) Expr {
+	return in{col: col, sub: q}
+}
+
+func (e in) build(b *buf) {
+	op := " IN "
+	if e.not {
+		op = " NOT IN "
+	}
+
+	if e.sub != nil {
+		b.write(e.col, op)
+		e.sub.build(b)
+		return
+	}
+
+	if len(e.values) == 0 {
+		if e.not {
+			b.write("1 = 1")
+		} else {
+			b.write("1 = 0")
+		}
+		return
+	}
+
+	b.write(e.col, op, "(")
+	for i, v := range e.values {
+		if i > 0 {
+			b.write(", ")
+		}
+		b.value(v)
+	}
+	b.write(")")
+}
+
+type between struct {
+	col      string
+	not      bool
+	from, to any
+}
+
+// Between — col BETWEEN from AND to, границы включительно
+func Between(col string, from, to any) Expr    { return between{col, false, from, to} }
+func NotBetween(col string, from, to any) Expr { return between{col, true, from, to} }
+
+func (e between) build(b *buf) {
+	b.write(e.col)
+	if e.not {
+		b.write(" NOT")
+	}
+	b.write(" BETWEEN ")
+	b.value(e.from)
+	b.write(" AND ")
+	b.value(e.to)
+}
+
+type exists struct {
+	not bool
+	sub SelectBuilder
+}
+
+func Exists(q SelectBuilder) Expr    { return exists{false, q} }
+func NotExists(q SelectBuilder) Expr { return exists{true, q} }
+
+func (e exists) build(b *buf) {
+	if e.not {
+		b.write("NOT ")
+	}
+	b.write("EXISTS ")
+	e.sub.build(b)
+}
+
+type group struct {
+	op    string
+	exprs []Expr
+}
+
+// And — все условия; nil пропускаются, так что необязательный фильтр можно передать как nil
+func And(exprs ...Expr) Expr { return group{"AND", exprs} }
+
+// Or — хотя бы одно условие
+func Or(exprs ...Expr) Expr { return group{"OR", exprs} }
+
+func (g group) items() []Expr {
+	var out []Expr
+	for _, e := range g.exprs {
+		if e != nil {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+func (g group) build(b *buf) {
+	items := g.items()
+	if len(items) == 0 {
+		// пустой AND ничего не ограничивает, пустой OR ничему не удовлетворяет
+		if g.op == "AND" {
+			b.write("1 = 1")
+		} else {
+			b.write("1 = 0")
+		}
+		return
+	}
+
+	for i, e := range items {
+		if i > 0 {
+			b.write(" ", g.op, " ")
+		}
+		wrap(b, e, g.op)
+	}
+}
+
+type not struct {
+	expr Expr
+}
+
+func Not(e Expr) Expr { return not{e} }
+
+func (n not) build(b *buf) {
+	b.write("NOT (")
+	n.expr.build(b)
+	b.write(")")
+}
+
+// wrap берёт в скобки вложенную группу с другим оператором: a AND (b OR c)
+func wrap(b *buf, e Expr, parent string) {
+	if g, ok := e.(group); ok && g.op != parent && len(g.items()) > 1 {
+		b.write("(")
+		g.build(b)
+		b.write(")")
+		return
+	}
+
+	e.build(b)
+}
+
+func anys[T any](values []T) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+
+	return out
+}
+
+// String — запрос с плейсхолдерами Postgres, для отладки и логов
+func String(q Builder) string {
+	sql, args, err := q.ToSQL(Dialect.Postgres)
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+
+	return fmt.Sprintf("%s %v", sql, args)
+}
@@ -0,0 +1,222 @@
+package Query
+
+import (
+	"slices"
+	"strings"
+
+	"learning/DataBase/Dialect"
+)
+
+type InsertBuilder struct {
+	table     string
+	columns   []string
+	rows      [][]any
+	suffix    Expr
+	returning []string
+}
+
+// Insert("orders").Columns("user_id", "total_amount").Values(2, 100.5).Returning("id")
+func Insert(table string) InsertBuilder {
+	return InsertBuilder{table: table}
+}
+
+func (i InsertBuilder) Columns(columns ...string) InsertBuilder {
+	i.columns = add(i.columns, columns...)
+	return i
+}
+
+// Values — одна строка; можно вызвать несколько раз, получится VALUES (...), (...). Значение может быть Expr: Raw("NOW()")
+func (i InsertBuilder) Values(values ...any) InsertBuilder {
+	i.rows = add(i.rows, values)
+	return i
+}
+
+// SetMap — колонки и значения одной строки из map; колонки идут по алфавиту, чтобы текст запроса не менялся от запуска к запуску
+func (i InsertBuilder) SetMap(m map[string]any) InsertBuilder {
+	columns := make([]string, 0, len(m))
+	for c := range m {
+		columns = append(columns, c)
+	}
+	slices.Sort(columns)
+
+	values := make([]any, len(columns))
+	for n, c := range columns {
+		values[n] = m[c]
+	}
+
+	i.columns = columns
+	i.rows = [][]any{values}
+
+	return i
+}
+
+// Suffix — то, что идёт после VALUES: ON CONFLICT ... DO NOTHING и т.п.
+func (i InsertBuilder) Suffix(e Expr) InsertBuilder {
+	i.suffix = e
+	return i
+}
+
+func (i InsertBuilder) Returning(columns ...string) InsertBuilder {
+	i.returning = add(i.returning, columns...)
+	return i
+}
+
+func (i InsertBuilder) ToSQL(d Dialect.Dialect) (string, []any, error) {
+	b := &buf{dialect: d}
+
+	if err := checkTable(i.table); err != nil {
+		return "", nil, err
+	}
+	if len(i.rows) == 0 {
+		return "", nil, ErrNoValues
+	}
+
+	b.write("INSERT INTO ", i.table)
+	if len(i.columns) > 0 {
+		b.write(" (", strings.Join(i.columns, ", "), ")")
+	}
+
+	b.write(" VALUES ")
+	for n, row := range i.rows {
+		if len(i.columns) > 0 && len(row) != len(i.columns) {
+			b.fail(countError("INSERT INTO "+i.table, len(i.columns), len(row)))
+		}
+		if n > 0 {
+			b.write(", ")
+		}
+		b.write("(")
+		for k, v := range row {
+			if k > 0 {
+				b.write(", ")
+			}
+			b.value(v)
+		}
+		b.write(")")
+	}
+
+	if i.suffix != nil {
+		b.write(" ")
+		i.suffix.build(b)
+	}
+	b.returning(i.returning)
+
+	return b.result()
+}
+
+type UpdateBuilder struct {
+	table     string
+	set       []assignment
+	where     []Expr
+	all       bool
+	returning []string
+}
+
+type assignment struct {
+	column string
+	value  any
+}
+
+// Update("orders").Set("status", "paid").Set("updated_at", Raw("NOW()")).Where(Eq("id", 1))
+func Update(table string) UpdateBuilder {
+	return UpdateBuilder{table: table}
+}
+
+func (u UpdateBuilder) Set(column string, value any) UpdateBuilder {
+	u.set = add(u.set, assignment{column, value})
+	return u
+}
+
+func (u UpdateBuilder) Where(exprs ...Expr) UpdateBuilder {
+	u.where = add(u.where, exprs...)
+	return u
+}
+
+// AllRows разрешает UPDATE без WHERE
+func (u UpdateBuilder) AllRows() UpdateBuilder {
+	u.all = true
+	return u
+}
+
+func (u UpdateBuilder) Returning(columns ...string) UpdateBuilder {
+	u.returning = add(u.returning, columns...)
+	return u
+}
+
+func (u UpdateBuilder) ToSQL(d Dialect.Dialect) (string, []any, error) {
+	b := &buf{dialect: d}
+
+	if err := checkTable(u.table); err != nil {
+		return "", nil, err
+	}
+	if len(u.set) == 0 {
+		return "", nil, ErrNoValues
+	}
+	if !u.all && !hasConditions(u.where) {
+		return "", nil, ErrNoWhere
+	}
+
+	b.write("UPDATE ", u.table, " SET ")
+	for n, a := range u.set {
+		if n > 0 {
+			b.write(", ")
+		}
+		b.write(a.column, " = ")
+		b.value(a.value)
+	}
+
+	if hasConditions(u.where) {
+		b.write(" WHERE ")
+		b.conditions(u.where)
+	}
+	b.returning(u.returning)
+
+	return b.result()
+}
+
+type DeleteBuilder struct {
+	table     string
+	where     []Expr
+	all       bool
+	returning []string
+}
+
+// Delete("orders").Where(Eq("id", 1)).Returning("id")
+func Delete(table string) DeleteBuilder {
+	return DeleteBuilder{table: table}
+}
+
+func (dl DeleteBuilder) Where(exprs ...Expr) DeleteBuilder {
+	dl.where = add(dl.where, exprs...)
+	return dl
+}
+
+// AllRows разрешает DELETE без WHERE
+func (dl DeleteBuilder) AllRows() DeleteBuilder {
+	dl.all = true
+	return dl
+}
+
+func (dl DeleteBuilder) Returning(columns ...string) DeleteBuilder {
+	dl.returning = add(dl.returning, columns...)
+	return dl
+}
+
+func (dl DeleteBuilder) ToSQL(d Dialect.Dialect) (string, []any, error) {
+	b := &buf{dialect: d}
+
+	if err := checkTable(dl.table); err != nil {
+		return "", nil, err
+	}
+	if !dl.all && !hasConditions(dl.where) {
+		return "", nil, ErrNoWhere
+	}
+
+	b.write("DELETE FROM ", dl.table)
+	if hasConditions(dl.where) {
+		b.write(" WHERE ")
+		b.conditions(dl.where)
+	}
+	b.returning(dl.returning)
+
+	return b.result()
+}
@@ -0,0 +1,135 @@
+package Query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"learning/DataBase/Dialect"
+)
+
+/*
+Построитель запросов: те же конструкции, что в шпаргалке refreshKnowledge.go, но собранные из кусков, а не склеенные строкой.
+
+	sql, args, err := Query.Select("o.id", "u.name", "o.total_amount").
+		From("orders o").
+		Join("users u", Eq("u.id", Col("o.user_id"))).
+		Where(In("o.status", "paid", "shipped"), Like("u.name", "%Даниил%")).
+		OrderBy("o.id DESC").
+		Limit(10).
+		ToSQL(Dialect.Postgres)
+
+	-> SELECT o.id, u.name, o.total_amount FROM orders o JOIN users u ON u.id = o.user_id
+	   WHERE o.status IN ($1, $2) AND u.name LIKE $3 ORDER BY o.id DESC LIMIT 10
+	   args: [paid shipped %Даниил%]
+
+Правила:
+	- значения всегда уходят в args, в текст запроса попадают только плейсхолдеры — SQL-инъекции через значения невозможны;
+	- имена таблиц и колонок ("orders o", "COUNT(*) AS n", "id DESC") вставляются как есть, поэтому они должны быть из кода,
+	  а не из запроса пользователя; сортировку по полю из запроса — только через белый список;
+	- Col("o.user_id") — сравнение с колонкой, а не со значением (условия JOIN);
+	- Raw("total_amount > ? * ?", a, b) — произвольный кусок SQL, каждый ? заменяется на плейсхолдер диалекта;
+	  поэтому ? внутри строковых литералов и операторы jsonb ?, ?|, ?& в Raw не записать (см. Raw);
+	- Offset без Limit на SQLite дописывает LIMIT -1: OFFSET без LIMIT там синтаксическая ошибка;
+	- Where и Having можно вызывать несколько раз — условия соединяются через AND, nil пропускается;
+	  так удобно собирать необязательные фильтры;
+	- построители неизменяемые: каждый метод возвращает копию, общую заготовку можно достраивать по-разному.
+
+Update и Delete без Where — ошибка ErrNoWhere (UPDATE orders SET status = 'cancelled' без условия задевает всю таблицу);
+если так и задумано — AllRows().
+*/
+
+var (
+	ErrNoWhere  = errors.New("query: UPDATE/DELETE без WHERE; если нужны все строки — AllRows()")
+	ErrNoTable  = errors.New("query: не указана таблица")
+	ErrNoValues = errors.New("query: нет значений")
+)
+
+// Builder — всё, что умеет отдать запрос и аргументы
+type Builder interface {
+	ToSQL(d Dialect.Dialect) (string, []any, error)
+}
+
+// buf накапливает текст и аргументы; первая ошибка запоминается, остальная сборка продолжается вхолостую
+type buf struct {
+	sb      strings.Builder
+	args    []any
+	dialect Dialect.Dialect
+	err     error
+}
+
+func (b *buf) write(parts ...string) {
+	for _, p := range parts {
+		b.sb.WriteString(p)
+	}
+}
+
+// value — плейсхолдер для значения или само выражение, если это Expr (Col, Raw, подзапрос)
+func (b *buf) value(v any) {
+	if e, ok := v.(Expr); ok {
+		e.build(b)
+		return
+	}
+
+	b.args = append(b.args, v)
+	b.write(b.dialect.Placeholder(len(b.args)))
+}
+
+func (b *buf) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *buf) result() (string, []any, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+
+	return b.sb.String(), b.args, nil
+}
+
+// conditions пишет выражения через AND; пустые (nil) пропускает
+func (b *buf) conditions(exprs []Expr) {
+	group{"AND", exprs}.build(b)
+}
+
+func (b *buf) returning(columns []string) {
+	if len(columns) > 0 {
+		b.write(" RETURNING ", strings.Join(columns, ", "))
+	}
+}
+
+func (b *buf) limit(keyword string, n int) {
+	if n >= 0 {
+		b.write(" ", keyword, " ", strconv.Itoa(n))
+	}
+}
+
+func hasConditions(exprs []Expr) bool {
+	for _, e := range exprs {
+		if e != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// add — append, который никогда не пишет в общий массив: построители копируются по значению
+func add[T any](s []T, v ...T) []T {
+	return append(s[:len(s):len(s)], v...)
+}
+
+func checkTable(table string) error {
+	if strings.TrimSpace(table) == "" {
+		return ErrNoTable
+	}
+
+	return nil
+}
+
+func countError(what string, want, got int) error {
+	return fmt.Errorf("query: %s: ожидалось %d значений, передано %d", what, want, got)
+}
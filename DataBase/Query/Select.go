@@ -0,0 +1,198 @@
+package Query
+
+import (
+	"strings"
+
+	"learning/DataBase/Dialect"
+)
+
+type SelectBuilder struct {
+	distinct bool
+	columns  []string
+	from     string
+	joins    []join
+	where    []Expr
+	groupBy  []string
+	having   []Expr
+	orderBy  []string
+	limit    int
+	offset   int
+	lock     lock
+}
+
+type lock int
+
+const (
+	noLock lock = iota
+	lockForUpdate
+	lockSkipLocked
+)
+
+type join struct {
+	kind  string
+	table string
+	on    Expr
+}
+
+// Select — SELECT columns; без колонок — SELECT *
+func Select(columns ...string) SelectBuilder {
+	return SelectBuilder{columns: columns, limit: -1, offset: -1}
+}
+
+func (s SelectBuilder) Distinct() SelectBuilder {
+	s.distinct = true
+	return s
+}
+
+// Columns добавляет колонки к уже выбранным
+func (s SelectBuilder) Columns(columns ...string) SelectBuilder {
+	s.columns = add(s.columns, columns...)
+	return s
+}
+
+// From — таблица, можно с алиасом: From("orders o")
+func (s SelectBuilder) From(table string) SelectBuilder {
+	s.from = table
+	return s
+}
+
+func (s SelectBuilder) Join(table string, on Expr) SelectBuilder {
+	return s.join("JOIN", table, on)
+}
+
+func (s SelectBuilder) LeftJoin(table string, on Expr) SelectBuilder {
+	return s.join("LEFT JOIN", table, on)
+}
+
+func (s SelectBuilder) RightJoin(table string, on Expr) SelectBuilder {
+	return s.join("RIGHT JOIN", table, on)
+}
+
+func (s SelectBuilder) FullJoin(table string, on Expr) SelectBuilder {
+	return s.join("FULL JOIN", table, on)
+}
+
+func (s SelectBuilder) CrossJoin(table string) SelectBuilder {
+	return s.join("CROSS JOIN", table, nil)
+}
+
+func (s SelectBuilder) join(kind, table string, on Expr) SelectBuilder {
+	s.joins = add(s.joins, join{kind, table, on})
+	return s
+}
+
+// Where добавляет условия через AND
+func (s SelectBuilder) Where(exprs ...Expr) SelectBuilder {
+	s.where = add(s.where, exprs...)
+	return s
+}
+
+func (s SelectBuilder) GroupBy(columns ...string) SelectBuilder {
+	s.groupBy = add(s.groupBy, columns...)
+	return s
+}
+
+// Having — условия на группы, через AND: Having(Gt("COUNT(*)", 5))
+func (s SelectBuilder) Having(exprs ...Expr) SelectBuilder {
+	s.having = add(s.having, exprs...)
+	return s
+}
+
+// OrderBy("created_at DESC", "id")
+func (s SelectBuilder) OrderBy(columns ...string) SelectBuilder {
+	s.orderBy = add(s.orderBy, columns...)
+	return s
+}
+
+// Limit; отрицательное значение снимает ограничение
+func (s SelectBuilder) Limit(n int) SelectBuilder {
+	s.limit = n
+	return s
+}
+
+// Offset; без Limit на SQLite перед ним пишется LIMIT -1
+func (s SelectBuilder) Offset(n int) SelectBuilder {
+	s.offset = n
+	return s
+}
+
+// ForUpdate — блокировка выбранных строк до конца транзакции: Dialect.ForUpdate().
+// В диалекте без блокировок строк (SQLite: пишет одна транзакция на всю базу) ничего не добавляет
+func (s SelectBuilder) ForUpdate() SelectBuilder {
+	s.lock = lockForUpdate
+	return s
+}
+
+// SkipLocked — FOR UPDATE SKIP LOCKED: занятые другими транзакциями строки пропускаются, а не ждутся (очереди, outbox).
+// Как и ForUpdate, в диалекте без блокировок строк ничего не добавляет: там нечего пропускать
+func (s SelectBuilder) SkipLocked() SelectBuilder {
+	s.lock = lockSkipLocked
+	return s
+}
+
+func (s SelectBuilder) ToSQL(d Dialect.Dialect) (string, []any, error) {
+	b := &buf{dialect: d}
+	s.write(b)
+
+	return b.result()
+}
+
+// build — подзапрос в скобках, с общей нумерацией параметров
+func (s SelectBuilder) build(b *buf) {
+	b.write("(")
+	s.write(b)
+	b.write(")")
+}
+
+func (s SelectBuilder) write(b *buf) {
+	b.write("SELECT ")
+	if s.distinct {
+		b.write("DISTINCT ")
+	}
+	if len(s.columns) == 0 {
+		b.write("*")
+	} else {
+		b.write(strings.Join(s.columns, ", "))
+	}
+
+	if s.from != "" {
+		b.write(" FROM ", s.from)
+	}
+
+	for _, j := range s.joins {
+		b.write(" ", j.kind, " ", j.table)
+		if j.on != nil {
+			b.write(" ON ")
+			j.on.build(b)
+		}
+	}
+
+	if hasConditions(s.where) {
+		b.write(" WHERE ")
+		b.conditions(s.where)
+	}
+	if len(s.groupBy) > 0 {
+		b.write(" GROUP BY ", strings.Join(s.groupBy, ", "))
+	}
+	if hasConditions(s.having) {
+		b.write(" HAVING ")
+		b.conditions(s.having)
+	}
+	if len(s.orderBy) > 0 {
+		b.write(" ORDER BY ", strings.Join(s.orderBy, ", "))
+	}
+
+	b.limit("LIMIT", s.limit)
+	if s.limit < 0 && s.offset >= 0 && b.dialect == Dialect.SQLite {
+		// OFFSET без LIMIT SQLite не разбирает; LIMIT -1 там — без ограничения
+		b.write(" LIMIT -1")
+	}
+	b.limit("OFFSET", s.offset)
+
+	if forUpdate := b.dialect.ForUpdate(); s.lock != noLock && forUpdate != "" {
+		b.write(forUpdate)
+		if s.lock == lockSkipLocked {
+			b.write(" SKIP LOCKED")
+		}
+	}
+}
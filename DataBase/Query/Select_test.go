@@ -0,0 +1,29 @@
+package Query
+
+import (
+	"testing"
+
+	"learning/DataBase/Dialect"
+)
+
+// Блокировка строк берётся из диалекта: в SQLite FOR UPDATE нет, и запрос без неё остаётся корректным
+func TestSelectLock(t *testing.T) {
+	for _, tc := range []struct {
+		q    SelectBuilder
+		d    Dialect.Dialect
+		want string
+	}{
+		{Select("id").From("outbox").ForUpdate(), Dialect.Postgres, "SELECT id FROM outbox FOR UPDATE"},
+		{Select("id").From("outbox").Limit(10).SkipLocked(), Dialect.Postgres, "SELECT id FROM outbox LIMIT 10 FOR UPDATE SKIP LOCKED"},
+		{Select("id").From("outbox").ForUpdate(), Dialect.SQLite, "SELECT id FROM outbox"},
+		{Select("id").From("outbox").Limit(10).SkipLocked(), Dialect.SQLite, "SELECT id FROM outbox LIMIT 10"},
+	} {
+		got, _, err := tc.q.ToSQL(tc.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: %q, ожидалось %q", tc.d.Name(), got, tc.want)
+		}
+	}
+}
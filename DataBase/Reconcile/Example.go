@@ -0,0 +1,49 @@
+package Reconcile
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"learning/DataBase/Fake"
+)
+
+/*
+С настоящей базой — через dbctl:
+
+	go run ./tools/dbctl reconcile        — только отчёт; есть расхождения — код выхода 1 (удобно в cron и CI)
+	go run ./tools/dbctl reconcile -fix   — исправить
+*/
+
+// ExampleRun — пачки по два заказа: у второго сумма разошлась с позициями, у третьего позиций нет вовсе
+func ExampleRun() {
+	db, mock := Fake.New(nil)
+	defer db.Close()
+
+	rows := func() *Fake.Rows { return Fake.NewRows("id", "total_amount", "items", "count") }
+	batch := `^SELECT o.id, COALESCE\(o.total_amount, 0\), COALESCE\(SUM\(oi.quantity \* oi.price\), 0\), COUNT\(oi.id\) ` +
+		`FROM orders o LEFT JOIN order_items oi ON oi.order_id = o.id WHERE o.id > \$1 GROUP BY o.id, o.total_amount ORDER BY o.id LIMIT 2$`
+
+	mock.ExpectQuery(batch).WithArgs(0).WillReturnRows(rows().Add(1, 1999.98, 1999.98, 2).Add(2, 100.0, 89.99, 1))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^UPDATE orders SET total_amount = \(SELECT COALESCE\(SUM\(quantity \* price\), 0\) FROM order_items WHERE order_id = \$1\)`).
+		WithArgs(2).WillReturnRows(Fake.NewRows("total_amount").Add(89.99))
+	mock.ExpectCommit()
+	mock.ExpectQuery(batch).WithArgs(2).WillReturnRows(rows().Add(3, 50.0, 0.0, 0))
+	mock.ExpectQuery(batch).WithArgs(3).WillReturnRows(rows())
+
+	report, err := Run(context.Background(), db, Options{
+		BatchSize:  2,
+		Fix:        true,
+		OnMismatch: func(m Mismatch) { fmt.Println(m) },
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("проверено %d, расхождений %d, исправлено %d, без позиций %d, в сумме %+.2f\n",
+		report.Checked, report.Mismatched, report.Fixed, report.WithoutItems, report.Delta)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,263 @@
+package Reconcile
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Query"
+	"learning/DataBase/Transactions"
+)
+
+/*
+Сверка orders.total_amount с суммой позиций SUM(order_items.quantity * price).
+
+Сумма заказа хранится отдельно от позиций и пишется отдельным UPDATE (TransactionExample считал её в Go во float64),
+поэтому может разойтись с позициями: ошибка округления, позицию добавили или удалили мимо пересчёта, ручная правка в базе.
+
+	rep, err := Reconcile.Run(ctx, db, Reconcile.Options{Driver: "postgres", Fix: true, OnMismatch: print})
+	fmt.Println(rep.Checked, rep.Mismatched, rep.Fixed, rep.Biggest)
+
+Как работает:
+	- заказы идут пачками по BatchSize в порядке id; следующая пачка — WHERE o.id > последний id (keyset),
+	  а не OFFSET: OFFSET на каждой пачке заново пропускает все предыдущие строки, а вставки посреди обхода сдвигают страницы;
+	- сумма позиций считается в базе (LEFT JOIN + GROUP BY), в Go приходит по строке на заказ — в памяти не больше пачки;
+	- суммы сравниваются в копейках: 2059.96 из DECIMAL и 2059.9600000000005 из SUM по REAL в SQLite — одно и то же;
+	- заказ без позиций, но с ненулевой суммой — расхождение, но Fix его не трогает: обнулить сумму заказа, у которого
+	  потерялись позиции, значит потерять и сумму. Такие заказы — в Report.WithoutItems, разбираться вручную.
+
+Fix исправляет расхождения пачки одной транзакцией: UPDATE берёт сумму из подзапроса по order_items, а не из того,
+что насчитал обход, — если позиции изменились между чтением и исправлением, запишется актуальная сумма.
+Упала пачка — откатится только она; уже исправленные пачки остаются исправленными, повторный запуск их не найдёт.
+*/
+
+const (
+	DefaultBatchSize = 500
+	DefaultTop       = 10
+)
+
+type Options struct {
+	Driver     string         // "postgres" (по умолчанию) или "sqlite"
+	BatchSize  int            // заказов за один запрос; 0 — DefaultBatchSize
+	Fix        bool           // исправлять total_amount по позициям
+	Top        int            // сколько самых больших расхождений оставить в Report.Biggest; 0 — DefaultTop
+	OnMismatch func(Mismatch) // вызывается для каждого расхождения сразу, как оно найдено (после исправления пачки, если Fix)
+}
+
+// Mismatch — заказ, у которого сумма не совпала с позициями
+type Mismatch struct {
+	OrderID int
+	Total   float64 // orders.total_amount (NULL — 0)
+	Items   float64 // SUM(quantity * price) по позициям
+	Count   int     // сколько позиций
+	Fixed   bool    // total_amount заменён на Items
+}
+
+// Delta — на сколько сумма заказа больше суммы позиций; отрицательная — заказ недооценён
+func (m Mismatch) Delta() float64 {
+	return float64(cents(m.Total)-cents(m.Items)) / 100
+}
+
+func (m Mismatch) String() string {
+	s := fmt.Sprintf("заказ %d: total_amount %.2f, позиции %.2f (%d шт.), разница %+.2f", m.OrderID, m.Total, m.Items, m.Count, m.Delta())
+	if m.Fixed {
+		s += " — исправлено"
+	}
+
+	return s
+}
+
+type Report struct {
+	Checked      int        // сколько заказов проверено
+	Mismatched   int        // сколько с расхождением, включая WithoutItems
+	WithoutItems int        // с ненулевой суммой, но без позиций — Fix их не исправляет
+	Fixed        int        // сколько исправлено
+	Delta        float64    // сумма всех расхождений (Total - Items): насколько заказы в целом завышены
+	Biggest      []Mismatch // самые большие расхождения по модулю, не больше Options.Top
+	Batches      int
+	Duration     time.Duration
+}
+
+/*
+Run проходит по всем заказам и сравнивает суммы; ctx прерывает обход между пачками и внутри запроса.
+При ошибке возвращает и Report — то, что успели проверить и исправить до неё.
+*/
+func Run(ctx context.Context, db *sql.DB, opts Options) (*Report, error) {
+	driver := opts.Driver
+	if driver == "" {
+		driver = "postgres"
+	}
+	d, err := Dialect.For(driver)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile: %w", err)
+	}
+
+	batch := opts.BatchSize
+	if batch <= 0 {
+		batch = DefaultBatchSize
+	}
+	top := opts.Top
+	if top <= 0 {
+		top = DefaultTop
+	}
+
+	c := &checker{db: db, dialect: d, runner: Transactions.New(db), opts: opts}
+	report := &Report{}
+	start := time.Now()
+	defer func() { report.Duration = time.Since(start) }()
+
+	for after := 0; ; {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		checked, last, found, err := c.batch(ctx, after, batch)
+		if err != nil {
+			return report, err
+		}
+		if checked == 0 {
+			return report, nil
+		}
+		report.Batches++
+		report.Checked += checked
+		after = last
+
+		if opts.Fix {
+			if err := c.fix(ctx, found); err != nil {
+				return report, err
+			}
+		}
+
+		for _, m := range found {
+			report.add(m, top)
+			if opts.OnMismatch != nil {
+				opts.OnMismatch(m)
+			}
+		}
+	}
+}
+
+type checker struct {
+	db      *sql.DB
+	dialect Dialect.Dialect
+	runner  *Transactions.Runner
+	opts    Options
+}
+
+// batch — до size заказов с id > after: сколько проверено, последний id и расхождения
+func (c *checker) batch(ctx context.Context, after, size int) (checked, last int, found []Mismatch, err error) {
+	q, args, err := Query.Select("o.id", "COALESCE(o.total_amount, 0)", "COALESCE(SUM(oi.quantity * oi.price), 0)", "COUNT(oi.id)").
+		From("orders o").
+		LeftJoin("order_items oi", Query.Eq("oi.order_id", Query.Col("o.id"))).
+		Where(Query.Gt("o.id", after)).
+		GroupBy("o.id", "o.total_amount").
+		OrderBy("o.id").
+		Limit(size).
+		ToSQL(c.dialect)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("reconcile: %w", err)
+	}
+
+	rows, err := c.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("reconcile: заказы после %d: %w", after, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m Mismatch
+		if err := rows.Scan(&m.OrderID, &m.Total, &m.Items, &m.Count); err != nil {
+			return 0, 0, nil, fmt.Errorf("reconcile: %w", err)
+		}
+		checked++
+		last = m.OrderID
+
+		if cents(m.Total) != cents(m.Items) {
+			found = append(found, m)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, nil, fmt.Errorf("reconcile: заказы после %d: %w", after, err)
+	}
+
+	return checked, last, found, nil
+}
+
+// fix исправляет расхождения одной пачки в одной транзакции; found обновляется на месте
+func (c *checker) fix(ctx context.Context, found []Mismatch) error {
+	if !slices.ContainsFunc(found, fixable) {
+		return nil
+	}
+
+	update := c.dialect.Rebind(`UPDATE orders
+		SET total_amount = (SELECT COALESCE(SUM(quantity * price), 0) FROM order_items WHERE order_id = $1),
+		    updated_at = ` + c.dialect.Now() + `
+		WHERE id = $1
+		RETURNING total_amount`)
+
+	fixed := make([]bool, len(found))
+	items := make([]float64, len(found))
+	err := c.runner.WithTx(ctx, Transactions.Options{}, func(ctx context.Context, tx *sql.Tx) error {
+		clear(fixed) // WithTx может повторить транзакцию целиком
+		for i, m := range found {
+			if !fixable(m) {
+				continue
+			}
+
+			err := tx.QueryRowContext(ctx, update, m.OrderID).Scan(&items[i])
+			if errors.Is(err, sql.ErrNoRows) {
+				continue // заказ удалили после чтения — исправлять нечего
+			}
+			if err != nil {
+				return fmt.Errorf("reconcile: исправление заказа %d: %w", m.OrderID, err)
+			}
+			fixed[i] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range found {
+		if fixed[i] {
+			found[i].Fixed, found[i].Items = true, items[i]
+		}
+	}
+
+	return nil
+}
+
+// fixable — у заказа есть позиции; заказ без позиций Fix не обнуляет
+func fixable(m Mismatch) bool {
+	return m.Count > 0
+}
+
+func (r *Report) add(m Mismatch, top int) {
+	r.Mismatched++
+	r.Delta = float64(cents(r.Delta)+cents(m.Delta())) / 100
+	if m.Count == 0 {
+		r.WithoutItems++
+	}
+	if m.Fixed {
+		r.Fixed++
+	}
+
+	r.Biggest = append(r.Biggest, m)
+	slices.SortStableFunc(r.Biggest, func(a, b Mismatch) int {
+		return cmp.Compare(math.Abs(b.Delta()), math.Abs(a.Delta()))
+	})
+	if len(r.Biggest) > top {
+		r.Biggest = r.Biggest[:top]
+	}
+}
+
+func cents(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
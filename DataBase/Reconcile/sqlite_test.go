@@ -0,0 +1,160 @@
+//go:build sqlite
+
+package Reconcile
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"learning/DataBase/Connection"
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Migrations"
+)
+
+/*
+Заказы для сверки, по два в пачке (BatchSize 2):
+
+	1 — сумма совпадает с позициями;
+	2 — сумма занижена: 100.00 при позициях 2 * 49.99 + 10.00 = 109.98;
+	3 — позиций нет, а сумма 500.00 — Fix его не трогает;
+	4 — позиций нет и сумма 0 — не расхождение;
+	5 — сумма завышена: 1999.99 при позиции 999.99;
+	6 — сумма занижена: 10.00 при позициях 2 * 10.00.
+*/
+func openOrders(t *testing.T) *sql.DB {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DATABASE_URL", filepath.Join(t.TempDir(), "test.db")+"?_pragma=foreign_keys(1)")
+
+	db, err := Connection.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := Migrations.New(db, Dialect.SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Log = log.New(io.Discard, "", 0)
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, q := range []string{
+		"INSERT INTO users (id, username, email) VALUES (1, 'daniil', 'daniil@example.com')",
+		"INSERT INTO products (id, name, price) VALUES (1, 'iPhone 15', 999.99), (2, 'Чехол', 49.99), (3, 'Плёнка', 10.00)",
+		`INSERT INTO orders (id, user_id, total_amount, status) VALUES
+			(1, 1, 999.99, 'paid'), (2, 1, 100.00, 'paid'), (3, 1, 500.00, 'pending'),
+			(4, 1, 0, 'pending'), (5, 1, 1999.99, 'paid'), (6, 1, 10.00, 'pending')`,
+		`INSERT INTO order_items (order_id, product_id, quantity, price) VALUES
+			(1, 1, 1, 999.99), (2, 2, 2, 49.99), (2, 3, 1, 10.00), (5, 1, 1, 999.99), (6, 3, 2, 10.00)`,
+	} {
+		if _, err := db.ExecContext(ctx, q); err != nil {
+			t.Fatal(q, err)
+		}
+	}
+
+	return db
+}
+
+func total(t *testing.T, db *sql.DB, orderID int) float64 {
+	t.Helper()
+
+	var v float64
+	if err := db.QueryRow("SELECT total_amount FROM orders WHERE id = ?", orderID).Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+
+	return v
+}
+
+func TestRunFixesDriftedTotals(t *testing.T) {
+	ctx := context.Background()
+	db := openOrders(t)
+
+	var seen []int
+	report, err := Run(ctx, db, Options{
+		Driver:     "sqlite",
+		BatchSize:  2,
+		Fix:        true,
+		Top:        2,
+		OnMismatch: func(m Mismatch) { seen = append(seen, m.OrderID) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Checked != 6 || report.Batches != 3 {
+		t.Fatalf("проверено %d в %d пачках, ожидалось 6 в 3", report.Checked, report.Batches)
+	}
+	if report.Mismatched != 4 || report.Fixed != 3 || report.WithoutItems != 1 {
+		t.Fatalf("расхождений %d, исправлено %d, без позиций %d, ожидалось 4, 3, 1",
+			report.Mismatched, report.Fixed, report.WithoutItems)
+	}
+	if cents(report.Delta) != cents(-9.98+500+1000-10) {
+		t.Fatalf("сумма расхождений %.2f, ожидалось 1480.02", report.Delta)
+	}
+	if len(seen) != 4 || seen[0] != 2 || seen[1] != 3 || seen[2] != 5 || seen[3] != 6 {
+		t.Fatalf("OnMismatch для заказов %v, ожидалось [2 3 5 6]", seen)
+	}
+
+	if len(report.Biggest) != 2 || report.Biggest[0].OrderID != 5 || report.Biggest[1].OrderID != 3 {
+		t.Fatalf("самые большие расхождения %v, ожидались заказы 5 и 3", report.Biggest)
+	}
+	if b := report.Biggest[0]; !b.Fixed || cents(b.Items) != 99999 || cents(b.Delta()) != 100000 {
+		t.Fatalf("заказ 5: %v", b)
+	}
+	if b := report.Biggest[1]; b.Fixed || b.Count != 0 {
+		t.Fatalf("заказ 3 без позиций: %v, ожидалось не исправлен", b)
+	}
+
+	for id, want := range map[int]float64{1: 999.99, 2: 109.98, 3: 500, 4: 0, 5: 999.99, 6: 20} {
+		if got := total(t, db, id); cents(got) != cents(want) {
+			t.Errorf("заказ %d: total_amount %.2f, ожидалось %.2f", id, got, want)
+		}
+	}
+
+	// повторный запуск находит только заказ без позиций
+	report, err = Run(ctx, db, Options{Driver: "sqlite", BatchSize: 2, Fix: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Mismatched != 1 || report.WithoutItems != 1 || report.Fixed != 0 {
+		t.Fatalf("повторный запуск: расхождений %d, без позиций %d, исправлено %d, ожидалось 1, 1, 0",
+			report.Mismatched, report.WithoutItems, report.Fixed)
+	}
+}
+
+// Исправление пачки — одна транзакция: упал UPDATE заказа 6 — откатился и заказ 5 из той же пачки
+func TestRunFixRollsBackBatch(t *testing.T) {
+	ctx := context.Background()
+	db := openOrders(t)
+
+	if _, err := db.ExecContext(ctx, `CREATE TRIGGER orders_6_locked BEFORE UPDATE OF total_amount ON orders
+		WHEN NEW.id = 6 BEGIN SELECT RAISE(ABORT, 'заказ 6 заблокирован'); END`); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(ctx, db, Options{Driver: "sqlite", BatchSize: 2, Fix: true})
+	if err == nil || !strings.Contains(err.Error(), "заказ 6 заблокирован") {
+		t.Fatalf("ошибка %v, ожидалась ошибка триггера", err)
+	}
+	if report == nil || report.Fixed != 1 || report.Checked != 6 {
+		t.Fatalf("отчёт до ошибки %+v, ожидалось проверено 6, исправлен 1 (заказ 2)", report)
+	}
+
+	if got := total(t, db, 2); cents(got) != cents(109.98) {
+		t.Fatalf("заказ 2 из прошлой пачки: %.2f, ожидалось исправлен до 109.98", got)
+	}
+	if got := total(t, db, 5); cents(got) != cents(1999.99) {
+		t.Fatalf("заказ 5: %.2f, ожидалось 1999.99 — транзакция пачки должна откатиться", got)
+	}
+}
@@ -0,0 +1,106 @@
+package Reports
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"learning/DataBase/Fake"
+)
+
+/*
+Примеры на Fake-драйвере: ответы базы расписаны заранее, видно, какие запросы уходят. С настоящей базой
+(docker-compose, миграции до 0008, данные — dbctl seed):
+
+	db, _ := Connection.Open()
+	rep, _ := Reports.New(db, Connection.Driver())
+	http.ListenAndServe(":8080", Reports.Handler(rep))
+
+	curl 'localhost:8080/reports/revenue?period=month&from=2025-01-01&to=2025-12-31&tz=Europe/Moscow&format=csv'
+*/
+
+// ExampleReports_Revenue — выручка по неделям марта по Москве, выгрузка в CSV
+func ExampleReports_Revenue() {
+	db, mock := Fake.New(nil)
+	defer db.Close()
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		log.Fatal(err)
+	}
+	p, err := Days("2025-03-01", "2025-03-31", moscow)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 1 марта — суббота: недели 24.02 (обрезана до 01.03), 03.03, 10.03, 17.03, 24.03, 31.03 (один день)
+	mock.ExpectQuery(`^WITH buckets \(n, start_at, end_at\) AS \(VALUES \(0, CAST\(\$1 AS TIMESTAMP\), CAST\(\$2 AS TIMESTAMP\)\), .* GROUP BY b.n ORDER BY b.n$`).
+		WithArgs(p.From.UTC(), Fake.AnyArg(), Fake.AnyArg(), Fake.AnyArg(), Fake.AnyArg(), Fake.AnyArg(), p.To.UTC(), "paid", "shipped", "delivered").
+		WillReturnRows(Fake.NewRows("n", "count", "sum").
+			Add(0, 0, 0).Add(1, 2, 1029.98).Add(2, 1, 2499.99).Add(3, 0, 0).Add(4, 3, 2059.96).Add(5, 0, 0))
+
+	rep, err := New(db, "postgres")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	revenue, err := rep.Revenue(context.Background(), p, Week)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := WriteCSV(os.Stdout, revenue); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// ExampleHandler — топ товаров по выручке через HTTP, с HAVING по количеству
+func ExampleHandler() {
+	db, mock := Fake.New(nil)
+	defer db.Close()
+
+	mock.ExpectQuery(`^SELECT p.id, p.name, SUM\(oi.quantity\), SUM\(oi.quantity \* oi.price\), COUNT\(DISTINCT o.id\) FROM order_items oi `+
+		`JOIN orders o ON o.id = oi.order_id JOIN products p ON p.id = oi.product_id `+
+		`WHERE o.created_at >= \$1 AND o.created_at < \$2 AND o.status IN \(\$3, \$4, \$5\) `+
+		`GROUP BY p.id, p.name HAVING SUM\(oi.quantity\) >= \$6 `+
+		`ORDER BY SUM\(oi.quantity \* oi.price\) DESC, SUM\(oi.quantity\) DESC, p.id LIMIT 3$`).
+		WithArgs(Fake.AnyArg(), Fake.AnyArg(), "paid", "shipped", "delivered", 2).
+		WillReturnRows(Fake.NewRows("id", "name", "quantity", "revenue", "orders").
+			Add(1, "iPhone 15", 3, 2999.97, 2).
+			Add(8, "Гантели", 2, 299.98, 1))
+
+	rep, err := New(db, "postgres")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srv := httptest.NewServer(Handler(rep))
+	defer srv.Close()
+
+	for _, url := range []string{
+		"/reports/products?by=revenue&limit=3&min_quantity=2&from=2025-03-01&to=2025-03-31&format=csv",
+		"/reports/revenue?period=year", // неверный параметр — 400 без запроса к базе
+	} {
+		resp, err := http.Get(srv.URL + url)
+		if err != nil {
+			log.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		fmt.Println(resp.StatusCode, resp.Header.Get("Content-Disposition"))
+		fmt.Print(string(body))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,103 @@
+package Reports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+/*
+Выгрузка отчётов: JSON — целиком, с параметрами (Meta); CSV — только строки, с заголовком, для Excel и Google Sheets.
+
+В CSV суммы — с точкой и двумя знаками (1999.98), даты — "2006-01-02" в часовом поясе отчёта:
+так файл одинаково читается в любой локали, а разделитель колонок — запятая, как требует RFC 4180.
+*/
+
+// Table — отчёт, который можно выгрузить в CSV
+type Table interface {
+	Header() []string
+	Records() [][]string
+}
+
+var (
+	_ Table = (*RevenueReport)(nil)
+	_ Table = (*ProductReport)(nil)
+	_ Table = (*Summary)(nil)
+	_ Table = (*StatusReport)(nil)
+)
+
+func WriteJSON(w io.Writer, report any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(report)
+}
+
+func WriteCSV(w io.Writer, t Table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Header()); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(t.Records()); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+func (r *RevenueReport) Header() []string {
+	return []string{string(r.Period), "orders", "revenue", "aov"}
+}
+
+func (r *RevenueReport) Records() [][]string {
+	records := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		records[i] = []string{row.Start.Format(time.DateOnly), strconv.Itoa(row.Orders), amount(row.Revenue), amount(row.AOV)}
+	}
+
+	return records
+}
+
+func (r *ProductReport) Header() []string {
+	return []string{"product_id", "name", "quantity", "revenue", "orders"}
+}
+
+func (r *ProductReport) Records() [][]string {
+	records := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		records[i] = []string{strconv.Itoa(row.ProductID), row.Name, strconv.Itoa(row.Quantity), amount(row.Revenue), strconv.Itoa(row.Orders)}
+	}
+
+	return records
+}
+
+func (s *Summary) Header() []string {
+	return []string{"from", "to", "orders", "revenue", "aov"}
+}
+
+// Records — одна строка; to — последний день включительно, как его вводили в Days
+func (s *Summary) Records() [][]string {
+	return [][]string{{
+		s.From.Format(time.DateOnly), s.To.Add(-time.Nanosecond).Format(time.DateOnly),
+		strconv.Itoa(s.Orders), amount(s.Revenue), amount(s.AOV),
+	}}
+}
+
+func (r *StatusReport) Header() []string {
+	return []string{"status", "orders", "amount"}
+}
+
+func (r *StatusReport) Records() [][]string {
+	records := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		records[i] = []string{row.Status, strconv.Itoa(row.Orders), amount(row.Amount)}
+	}
+
+	return records
+}
+
+func amount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,179 @@
+package Reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // в golang:alpine (Dockerfile) нет /usr/share/zoneinfo, без этого LoadLocation("Europe/Moscow") не сработает
+)
+
+/*
+HTTP API отчётов:
+
+	GET /reports/revenue?period=week&from=2025-03-01&to=2025-03-31&tz=Europe/Moscow
+	GET /reports/products?by=revenue&limit=5&min_quantity=2
+	GET /reports/summary?from=2025-03-01&to=2025-03-31
+	GET /reports/statuses?tz=Europe/Moscow&format=csv
+
+Общие параметры:
+	from, to — даты "2006-01-02", to включительно; по умолчанию последние 30 дней, считая сегодня;
+	tz       — часовой пояс IANA, по умолчанию UTC;
+	status   — можно повторять: какие заказы считать продажами (по умолчанию RevenueStatuses);
+	format   — json (по умолчанию) или csv; вместо него можно прислать Accept: text/csv.
+
+CSV отдаётся с Content-Disposition: attachment — браузер сохранит файл revenue-2025-03-01-2025-03-31.csv.
+Неверный параметр — 400 {"error": {"code": "invalid_params", "message": ...}}, остальные ошибки — 500 без подробностей.
+*/
+
+// DefaultDays — диапазон по умолчанию, если from не задан
+const DefaultDays = 30
+
+func Handler(r *Reports) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /reports/revenue", serve("revenue", func(ctx context.Context, req *http.Request, p Params) (Table, error) {
+		period, err := ParsePeriod(value(req, "period", string(Day)))
+		if err != nil {
+			return nil, err
+		}
+		return r.Revenue(ctx, p, period)
+	}))
+
+	mux.HandleFunc("GET /reports/products", serve("products", func(ctx context.Context, req *http.Request, p Params) (Table, error) {
+		by, err := ParseBy(value(req, "by", string(ByQuantity)))
+		if err != nil {
+			return nil, err
+		}
+		limit, err := number(req, "limit", DefaultTop)
+		if err != nil {
+			return nil, err
+		}
+		minQuantity, err := number(req, "min_quantity", 0)
+		if err != nil {
+			return nil, err
+		}
+		return r.TopProducts(ctx, p, TopOptions{By: by, Limit: limit, MinQuantity: minQuantity})
+	}))
+
+	mux.HandleFunc("GET /reports/summary", serve("summary", func(ctx context.Context, _ *http.Request, p Params) (Table, error) {
+		return r.Summary(ctx, p)
+	}))
+
+	mux.HandleFunc("GET /reports/statuses", serve("statuses", func(ctx context.Context, _ *http.Request, p Params) (Table, error) {
+		return r.Statuses(ctx, p)
+	}))
+
+	return mux
+}
+
+type build func(ctx context.Context, req *http.Request, p Params) (Table, error)
+
+func serve(name string, fn build) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		p, err := params(req, time.Now())
+		if err != nil {
+			fail(w, err)
+			return
+		}
+
+		report, err := fn(req.Context(), req, p)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+
+		if wantsCSV(req) {
+			last := p.To.In(p.location()).AddDate(0, 0, -1)
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s-%s.csv"`,
+				name, p.From.In(p.location()).Format(time.DateOnly), last.Format(time.DateOnly)))
+			err = WriteCSV(w, report)
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+			err = WriteJSON(w, report)
+		}
+		if err != nil {
+			log.Printf("reports: %s: запись ответа: %v", name, err)
+		}
+	}
+}
+
+// params — from, to, tz и status из строки запроса; now — для диапазона по умолчанию
+func params(req *http.Request, now time.Time) (Params, error) {
+	loc, err := time.LoadLocation(value(req, "tz", "UTC"))
+	if err != nil {
+		return Params{}, fmt.Errorf("%w: tz: %w", ErrInvalidParams, err)
+	}
+
+	today := now.In(loc).Format(time.DateOnly)
+	to := value(req, "to", today)
+	from := req.URL.Query().Get("from")
+	if from == "" {
+		last, err := time.ParseInLocation(time.DateOnly, to, loc)
+		if err != nil {
+			return Params{}, fmt.Errorf("%w: to: %w", ErrInvalidParams, err)
+		}
+		from = last.AddDate(0, 0, 1-DefaultDays).Format(time.DateOnly)
+	}
+
+	p, err := Days(from, to, loc)
+	if err != nil {
+		return Params{}, err
+	}
+	if statuses, ok := req.URL.Query()["status"]; ok {
+		p.Statuses = statuses
+	}
+
+	return p, p.validate()
+}
+
+func value(req *http.Request, key, def string) string {
+	if v := req.URL.Query().Get(key); v != "" {
+		return v
+	}
+
+	return def
+}
+
+func number(req *http.Request, key string, def int) (int, error) {
+	v := req.URL.Query().Get(key)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %s=%q — ожидается неотрицательное число", ErrInvalidParams, key, v)
+	}
+
+	return n, nil
+}
+
+func wantsCSV(req *http.Request) bool {
+	switch req.URL.Query().Get("format") {
+	case "csv":
+		return true
+	case "":
+		return strings.Contains(req.Header.Get("Accept"), "text/csv")
+	}
+
+	return false
+}
+
+func fail(w http.ResponseWriter, err error) {
+	status, code, message := http.StatusInternalServerError, "internal", "внутренняя ошибка"
+	if errors.Is(err, ErrInvalidParams) {
+		status, code, message = http.StatusBadRequest, "invalid_params", err.Error()
+	} else {
+		log.Printf("reports: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = WriteJSON(w, map[string]any{"error": map[string]string{"code": code, "message": message}})
+}
@@ -0,0 +1,116 @@
+package Reports
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"learning/DataBase/Query"
+)
+
+// By — по чему строится топ товаров
+type By string
+
+const (
+	ByQuantity By = "quantity"
+	ByRevenue  By = "revenue"
+)
+
+func ParseBy(s string) (By, error) {
+	switch b := By(s); b {
+	case ByQuantity, ByRevenue:
+		return b, nil
+	}
+
+	return "", fmt.Errorf("%w: сортировка %q — ожидается quantity или revenue", ErrInvalidParams, s)
+}
+
+const DefaultTop = 10
+
+type TopOptions struct {
+	By          By  // по умолчанию ByQuantity
+	Limit       int // 0 — DefaultTop
+	MinQuantity int // HAVING: товары, проданные меньше MinQuantity штук, в топ не попадают
+}
+
+// ProductSales — продажи одного товара за период
+type ProductSales struct {
+	ProductID int     `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	Revenue   float64 `json:"revenue"` // SUM(quantity * price) по позициям: цена на момент заказа, а не текущая products.price
+	Orders    int     `json:"orders"`  // в скольких заказах встречается
+}
+
+type ProductReport struct {
+	Meta
+	By   By             `json:"by"`
+	Rows []ProductSales `json:"rows"`
+}
+
+/*
+TopProducts — самые продаваемые товары по штукам или по выручке.
+
+	SELECT p.id, p.name, SUM(oi.quantity), SUM(oi.quantity * oi.price), COUNT(DISTINCT o.id)
+	FROM order_items oi JOIN orders o ON ... JOIN products p ON ...
+	WHERE o.created_at >= $1 AND o.created_at < $2 AND o.status IN (...)
+	GROUP BY p.id, p.name
+	HAVING SUM(oi.quantity) >= $n
+	ORDER BY SUM(oi.quantity) DESC, p.id
+	LIMIT 10
+
+WHERE отбирает строки до группировки, HAVING — группы после неё: условие на SUM в WHERE написать нельзя.
+ORDER BY повторяет выражение, а не ссылается на алиас: так запрос одинаково понимают Postgres и SQLite.
+*/
+func (r *Reports) TopProducts(ctx context.Context, p Params, opts TopOptions) (*ProductReport, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	by := opts.By
+	if by == "" {
+		by = ByQuantity
+	}
+	if _, err := ParseBy(string(by)); err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultTop
+	}
+
+	quantity, revenue := "SUM(oi.quantity)", "SUM(oi.quantity * oi.price)"
+	order := []string{quantity + " DESC", revenue + " DESC", "p.id"}
+	if by == ByRevenue {
+		order[0], order[1] = order[1], order[0]
+	}
+
+	q := Query.Select("p.id", "p.name", quantity, revenue, "COUNT(DISTINCT o.id)").
+		From("order_items oi").
+		Join("orders o", Query.Eq("o.id", Query.Col("oi.order_id"))).
+		Join("products p", Query.Eq("p.id", Query.Col("oi.product_id"))).
+		Where(r.between("o", p), Query.In("o.status", p.statuses()...)).
+		GroupBy("p.id", "p.name").
+		OrderBy(order...).
+		Limit(limit)
+	if opts.MinQuantity > 0 {
+		q = q.Having(Query.Ge(quantity, opts.MinQuantity))
+	}
+
+	report := &ProductReport{Meta: p.meta(), By: by}
+	err := r.each(ctx, q, func(rows *sql.Rows) error {
+		var s ProductSales
+		if err := rows.Scan(&s.ProductID, &s.Name, &s.Quantity, &s.Revenue, &s.Orders); err != nil {
+			return err
+		}
+		s.Revenue = money(s.Revenue)
+		report.Rows = append(report.Rows, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,255 @@
+package Reports
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Orders"
+	"learning/DataBase/Query"
+)
+
+/*
+Отчёты о продажах для финансов — GROUP BY и агрегатные функции на настоящих таблицах orders и order_items.
+
+	rep, _ := Reports.New(db, Connection.Driver())
+	p, _ := Reports.Days("2025-03-01", "2025-03-31", moscow)
+
+	revenue, _ := rep.Revenue(ctx, p, Reports.Week) // выручка по неделям, пустые недели — нулями
+	top, _ := rep.TopProducts(ctx, p, Reports.TopOptions{By: Reports.ByRevenue, Limit: 10})
+	summary, _ := rep.Summary(ctx, p)               // заказы, выручка, средний чек (AOV)
+	statuses, _ := rep.Statuses(ctx, p)             // сколько заказов в каждом статусе
+
+	Reports.WriteCSV(w, revenue) // или WriteJSON; HTTP — Handler (Handler.go)
+
+Что считается продажей: заказ в одном из RevenueStatuses (оплачен, отправлен, доставлен); pending ещё не деньги,
+cancelled и refunded — уже не деньги. Params.Statuses меняет набор.
+
+Часовой пояс. created_at хранится без пояса, в UTC (NOW() сервера в UTC, Dialect.Time в SQLite — тоже UTC).
+«День» у финансов — день по Москве, а не по UTC, поэтому границы дней, недель и месяцев считаются в Go
+в Params.Location (time.Date учитывает переходы на летнее время) и передаются в запрос готовыми моментами UTC.
+Группировать по date_trunc / strftime в SQL было бы проще, но SQLite часовых поясов не знает вовсе,
+а Postgres понадобился бы created_at AT TIME ZONE — выражение, которое не ложится на индекс.
+
+Индексы — миграция 0008: orders(created_at, status) для диапазона дат и order_items(product_id) для топа товаров.
+*/
+
+var ErrInvalidParams = errors.New("reports: неверные параметры отчёта")
+
+// RevenueStatuses — статусы заказов, которые входят в выручку
+var RevenueStatuses = []string{Orders.StatusPaid, Orders.StatusShipped, Orders.StatusDelivered}
+
+// Params — общие параметры отчётов
+type Params struct {
+	From, To time.Time      // полуинтервал [From, To)
+	Location *time.Location // часовой пояс для границ дней, недель и месяцев; nil — UTC
+	Statuses []string       // какие заказы считаются продажами; nil — RevenueStatuses (Statuses() его не использует)
+}
+
+// Days — Params по датам "2006-01-02" в часовом поясе loc: с начала дня from до конца дня to включительно
+func Days(from, to string, loc *time.Location) (Params, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	f, err := time.ParseInLocation(time.DateOnly, from, loc)
+	if err != nil {
+		return Params{}, fmt.Errorf("%w: from: %w", ErrInvalidParams, err)
+	}
+	t, err := time.ParseInLocation(time.DateOnly, to, loc)
+	if err != nil {
+		return Params{}, fmt.Errorf("%w: to: %w", ErrInvalidParams, err)
+	}
+
+	return Params{From: f, To: t.AddDate(0, 0, 1), Location: loc}, nil
+}
+
+func (p Params) location() *time.Location {
+	if p.Location == nil {
+		return time.UTC
+	}
+
+	return p.Location
+}
+
+func (p Params) statuses() []string {
+	if p.Statuses == nil {
+		return RevenueStatuses
+	}
+
+	return p.Statuses
+}
+
+func (p Params) validate() error {
+	if p.From.IsZero() || p.To.IsZero() {
+		return fmt.Errorf("%w: не задан диапазон дат", ErrInvalidParams)
+	}
+	if !p.From.Before(p.To) {
+		return fmt.Errorf("%w: начало диапазона %s не раньше конца %s", ErrInvalidParams,
+			p.From.Format(time.RFC3339), p.To.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// Meta — параметры, с которыми построен отчёт; попадает в JSON, чтобы выгрузку можно было понять без запроса
+type Meta struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Timezone string    `json:"timezone"`
+}
+
+func (p Params) meta() Meta {
+	loc := p.location()
+	return Meta{From: p.From.In(loc), To: p.To.In(loc), Timezone: loc.String()}
+}
+
+// Querier — *sql.DB или *sql.Tx: отчёт можно построить и внутри транзакции с REPEATABLE READ, чтобы цифры сошлись между собой
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+type Reports struct {
+	db      Querier
+	dialect Dialect.Dialect
+}
+
+func New(db Querier, driver string) (*Reports, error) {
+	d, err := Dialect.For(driver)
+	if err != nil {
+		return nil, fmt.Errorf("reports: %w", err)
+	}
+
+	return &Reports{db: db, dialect: d}, nil
+}
+
+// between — условие на created_at заказа: по нему идёт индекс idx_orders_created_status
+func (r *Reports) between(alias string, p Params) Query.Expr {
+	return Query.And(
+		Query.Ge(alias+".created_at", r.dialect.Time(p.From.UTC())),
+		Query.Lt(alias+".created_at", r.dialect.Time(p.To.UTC())),
+	)
+}
+
+// Summary — итоги за период: число заказов, выручка и средний чек (AOV)
+type Summary struct {
+	Meta
+	Orders  int     `json:"orders"`
+	Revenue float64 `json:"revenue"`
+	AOV     float64 `json:"aov"`
+}
+
+func (r *Reports) Summary(ctx context.Context, p Params) (*Summary, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	q := Query.Select("COUNT(*)", "COALESCE(SUM(o.total_amount), 0)", "COALESCE(AVG(o.total_amount), 0)").
+		From("orders o").
+		Where(r.between("o", p), Query.In("o.status", p.statuses()...))
+
+	s := &Summary{Meta: p.meta()}
+	err := r.each(ctx, q, func(rows *sql.Rows) error {
+		return rows.Scan(&s.Orders, &s.Revenue, &s.AOV)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Revenue, s.AOV = money(s.Revenue), money(s.AOV)
+
+	return s, nil
+}
+
+// StatusCount — сколько заказов в статусе и на какую сумму
+type StatusCount struct {
+	Status string  `json:"status"`
+	Orders int     `json:"orders"`
+	Amount float64 `json:"amount"`
+}
+
+type StatusReport struct {
+	Meta
+	Rows []StatusCount `json:"rows"`
+}
+
+// Statuses — заказы за период по статусам; статусы без заказов тоже в отчёте, с нулями, в порядке Orders.Statuses
+func (r *Reports) Statuses(ctx context.Context, p Params) (*StatusReport, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	q := Query.Select("o.status", "COUNT(*)", "COALESCE(SUM(o.total_amount), 0)").
+		From("orders o").
+		Where(r.between("o", p)).
+		GroupBy("o.status")
+
+	counts := make(map[string]StatusCount)
+	err := r.each(ctx, q, func(rows *sql.Rows) error {
+		var c StatusCount
+		if err := rows.Scan(&c.Status, &c.Orders, &c.Amount); err != nil {
+			return err
+		}
+		c.Amount = money(c.Amount)
+		counts[c.Status] = c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StatusReport{Meta: p.meta()}
+	for _, status := range Orders.Statuses {
+		c, ok := counts[status]
+		if !ok {
+			c = StatusCount{Status: status}
+		}
+		report.Rows = append(report.Rows, c)
+		delete(counts, status)
+	}
+	// статусы, которых нет в Orders.Statuses (старый processing из init.sql), — в конце, чтобы сумма сходилась
+	for _, status := range slices.Sorted(maps.Keys(counts)) {
+		report.Rows = append(report.Rows, counts[status])
+	}
+
+	return report, nil
+}
+
+// each выполняет запрос и вызывает scan для каждой строки
+func (r *Reports) each(ctx context.Context, b Query.Builder, scan func(rows *sql.Rows) error) error {
+	q, args, err := b.ToSQL(r.dialect)
+	if err != nil {
+		return fmt.Errorf("reports: %w", err)
+	}
+
+	return r.query(ctx, q, args, scan)
+}
+
+func (r *Reports) query(ctx context.Context, q string, args []any, scan func(rows *sql.Rows) error) error {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("reports: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return fmt.Errorf("reports: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reports: %w", err)
+	}
+
+	return nil
+}
+
+// money округляет до копеек: SUM по REAL в SQLite даёт 2059.9600000000005, AVG в Postgres — 16 знаков после запятой
+func money(v float64) float64 {
+	return math.Round(v*100) / 100
+}
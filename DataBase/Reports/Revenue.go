@@ -0,0 +1,176 @@
+package Reports
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Period string
+
+const (
+	Day   Period = "day"
+	Week  Period = "week" // с понедельника, как в ISO 8601
+	Month Period = "month"
+)
+
+// MaxBuckets — больше периодов в одном отчёте не бывает: каждая граница — параметр запроса, а у SQLite их до 32766
+// (у старых сборок — 999). Год по дням укладывается
+const MaxBuckets = 400
+
+func ParsePeriod(s string) (Period, error) {
+	switch p := Period(s); p {
+	case Day, Week, Month:
+		return p, nil
+	}
+
+	return "", fmt.Errorf("%w: период %q — ожидается day, week или month", ErrInvalidParams, s)
+}
+
+// start — начало периода, в который попадает t, в часовом поясе t
+func (p Period) start(t time.Time) time.Time {
+	y, m, d := t.Date()
+
+	switch p {
+	case Week:
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case Month:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	}
+
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// next — начало следующего периода; AddDate сохраняет полночь и при переходе на летнее время
+func (p Period) next(start time.Time) time.Time {
+	switch p {
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	}
+
+	return start.AddDate(0, 0, 1)
+}
+
+// bounds — границы периодов внутри [from, to): первая — from, последняя — to, между ними — начала периодов
+func (p Period) bounds(from, to time.Time) ([]time.Time, error) {
+	bounds := []time.Time{from}
+	for b := p.next(p.start(from)); b.Before(to); b = p.next(b) {
+		bounds = append(bounds, b)
+		if len(bounds) > MaxBuckets {
+			return nil, fmt.Errorf("%w: больше %d периодов %s — возьмите период крупнее", ErrInvalidParams, MaxBuckets, p)
+		}
+	}
+
+	return append(bounds, to), nil
+}
+
+// RevenueRow — выручка за один период; периоды без продаж — с нулями
+type RevenueRow struct {
+	Start   time.Time `json:"start"` // начало периода в часовом поясе отчёта; первый и последний периоды обрезаны диапазоном
+	Orders  int       `json:"orders"`
+	Revenue float64   `json:"revenue"`
+	AOV     float64   `json:"aov"`
+}
+
+type RevenueReport struct {
+	Meta
+	Period Period       `json:"period"`
+	Rows   []RevenueRow `json:"rows"`
+}
+
+/*
+Revenue — выручка по дням, неделям или месяцам.
+
+Периоды — строки VALUES, к ним LEFT JOIN заказов, так что пустые периоды приходят из базы строками с нулями:
+
+	WITH buckets (n, start_at, end_at) AS (VALUES (0, CAST($1 AS TIMESTAMP), CAST($2 AS TIMESTAMP)), (1, CAST($2 ...), ...)
+	SELECT b.n, COUNT(o.id), COALESCE(SUM(o.total_amount), 0)
+	FROM buckets b LEFT JOIN orders o ON o.created_at >= b.start_at AND o.created_at < b.end_at AND o.status IN (...)
+	GROUP BY b.n ORDER BY b.n
+
+Граница периода — один нумерованный параметр, общий для двух соседних строк ($2 — конец первого и начало второго).
+CAST нужен Postgres: без него тип параметра в VALUES выводится как text и сравнение с TIMESTAMP не сработает.
+Каждый период — диапазон по idx_orders_created_status.
+*/
+func (r *Reports) Revenue(ctx context.Context, p Params, period Period) (*RevenueReport, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := ParsePeriod(string(period)); err != nil {
+		return nil, err
+	}
+
+	loc := p.location()
+	bounds, err := period.bounds(p.From.In(loc), p.To.In(loc))
+	if err != nil {
+		return nil, err
+	}
+
+	d := r.dialect
+	args := make([]any, 0, len(bounds)+len(p.statuses()))
+
+	var sb strings.Builder
+	sb.WriteString("WITH buckets (n, start_at, end_at) AS (VALUES ")
+	for i, b := range bounds {
+		args = append(args, d.Time(b.UTC()))
+		if i == len(bounds)-1 {
+			break
+		}
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "(%d, CAST($%d AS %s), CAST($%d AS %s))", i, i+1, d.TimeType(), i+2, d.TimeType())
+	}
+	sb.WriteString(`)
+		SELECT b.n, COUNT(o.id), COALESCE(SUM(o.total_amount), 0)
+		FROM buckets b
+		LEFT JOIN orders o ON o.created_at >= b.start_at AND o.created_at < b.end_at AND o.status IN (`)
+	for i, status := range p.statuses() {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		args = append(args, status)
+		fmt.Fprintf(&sb, "$%d", len(args))
+	}
+	if len(p.statuses()) == 0 {
+		sb.WriteString("NULL") // IN (NULL) — ни один заказ, как и пустой Query.In
+	}
+	sb.WriteString(`)
+		GROUP BY b.n
+		ORDER BY b.n`)
+
+	report := &RevenueReport{Meta: p.meta(), Period: period, Rows: make([]RevenueRow, len(bounds)-1)}
+	for i := range report.Rows {
+		report.Rows[i].Start = period.start(bounds[i])
+	}
+
+	err = r.query(ctx, d.Rebind(sb.String()), args, func(rows *sql.Rows) error {
+		var (
+			n       int
+			orders  int
+			revenue float64
+		)
+		if err := rows.Scan(&n, &orders, &revenue); err != nil {
+			return err
+		}
+		if n < 0 || n >= len(report.Rows) {
+			return fmt.Errorf("период %d вне отчёта", n)
+		}
+
+		row := &report.Rows[n]
+		row.Orders, row.Revenue = orders, money(revenue)
+		if orders > 0 {
+			row.AOV = money(revenue / float64(orders))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,280 @@
+package Scanner
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+/*
+Сканирование строк в структуры по именам колонок, а не по позиции.
+
+	orders, err := Scanner.ScanAll[Order](rows)
+
+вместо
+
+	rows.Scan(&o.ID, &o.UserID, &o.TotalAmount, &o.Status, &o.CreatedAt, &o.UpdatedAt)
+
+Колонка сопоставляется с полем:
+	- по тегу db:"user_name" (как в OOP.UserSerialize);
+	- без тега — по имени поля в snake_case: UserID -> user_id, TotalAmount -> total_amount;
+	- db:"-" — поле не участвует.
+Встроенные (embedded) структуры раскрываются, их поля доступны так, будто объявлены во внешней; *Embedded создаётся по необходимости.
+Поля-указатели (*string) и sql.NullString / sql.NullInt64 / ... получают NULL как nil / Valid=false — это умеет сам database/sql.
+Поля time.Time и *time.Time принимают и строку: SQLite хранит время текстом, и драйвер не всегда узнаёт в нём время
+(например, в колонках RETURNING). Для QueryRow то же самое — Scanner.Time(&o.CreatedAt).
+
+Колонка, для которой нет поля, — ошибка: опечатка в алиасе или SELECT * с лишними колонками должны быть видны сразу,
+а не превращаться в молча пустое поле.
+
+Разбор структуры через reflect выполняется один раз на тип и кэшируется.
+Если T не структура (int, string, time.Time, ...), строка должна состоять из одной колонки, она сканируется прямо в T.
+*/
+
+// Rows — то, что нужно от *sql.Rows; позволяет подставлять свои реализации
+type Rows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close() error
+}
+
+var ErrUnknownColumn = errors.New("scanner: нет поля для колонки")
+
+// ScanOne читает первую строку и закрывает rows; пустой результат — sql.ErrNoRows
+func ScanOne[T any](rows Rows) (T, error) {
+	var zero T
+	defer rows.Close()
+
+	plan, err := planFor[T](rows)
+	if err != nil {
+		return zero, err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return zero, err
+		}
+		return zero, sql.ErrNoRows
+	}
+
+	var v T
+	if err := plan.scan(rows, &v); err != nil {
+		return zero, err
+	}
+
+	return v, rows.Close()
+}
+
+// ScanAll читает все строки и закрывает rows
+func ScanAll[T any](rows Rows) ([]T, error) {
+	var out []T
+
+	err := Each(rows, func(v T) error {
+		out = append(out, v)
+		return nil
+	})
+
+	return out, err
+}
+
+// Each вызывает fn для каждой строки по мере чтения, не держа весь результат в памяти; ошибка fn прекращает чтение
+func Each[T any](rows Rows, fn func(T) error) error {
+	defer rows.Close()
+
+	plan, err := planFor[T](rows)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var v T
+		if err := plan.scan(rows, &v); err != nil {
+			return err
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// plan — куда сканировать каждую колонку конкретного запроса
+type plan struct {
+	columns []string
+	paths   [][]int // путь индексов поля для каждой колонки; nil — сканировать прямо в значение (не структура)
+}
+
+func planFor[T any](rows Rows) (*plan, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeFor[T]()
+	p := &plan{columns: columns, paths: make([][]int, len(columns))}
+
+	if !isStruct(t) {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("scanner: %s не структура, а колонок %d", t, len(columns))
+		}
+		return p, nil
+	}
+
+	fields := fieldsOf(t)
+	for i, col := range columns {
+		path, ok := fields[strings.ToLower(col)]
+		if !ok {
+			return nil, fmt.Errorf("%w %q в %s", ErrUnknownColumn, col, t)
+		}
+		p.paths[i] = path
+	}
+
+	return p, nil
+}
+
+func (p *plan) scan(rows Rows, dst any) error {
+	v := reflect.ValueOf(dst).Elem()
+
+	if p.paths[0] == nil && len(p.paths) == 1 && !isStruct(v.Type()) {
+		return rows.Scan(target(v))
+	}
+
+	targets := make([]any, len(p.paths))
+	for i, path := range p.paths {
+		targets[i] = target(fieldByIndexAlloc(v, path))
+	}
+
+	return rows.Scan(targets...)
+}
+
+// target — адрес поля для rows.Scan; время — через приёмник, который понимает и строки
+func target(f reflect.Value) any {
+	switch dst := f.Addr().Interface().(type) {
+	case *time.Time:
+		return Time(dst)
+	case **time.Time:
+		return nullTime{dst}
+	default:
+		return dst
+	}
+}
+
+// fieldByIndexAlloc — как FieldByIndex, но создаёт nil-указатели на встроенные структуры по пути
+func fieldByIndexAlloc(v reflect.Value, path []int) reflect.Value {
+	for i, idx := range path {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+
+	return v
+}
+
+var cache sync.Map // reflect.Type -> map[string][]int
+
+// Fields — колонка (в нижнем регистре) -> путь индексов поля по тем же правилам, что и при сканировании.
+// Нужна тем, кто идёт в обратную сторону — из структуры в параметры запроса (Named). Результат общий, менять его нельзя
+func Fields(t reflect.Type) map[string][]int {
+	return fieldsOf(t)
+}
+
+// fieldsOf возвращает колонку (в нижнем регистре) -> путь индексов поля; результат кэшируется на тип
+func fieldsOf(t reflect.Type) map[string][]int {
+	if cached, ok := cache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+
+	fields := make(map[string][]int)
+	depth := make(map[string]int)
+	collect(t, nil, fields, depth)
+
+	actual, _ := cache.LoadOrStore(t, fields)
+
+	return actual.(map[string][]int)
+}
+
+func collect(t reflect.Type, prefix []int, fields map[string][]int, depth map[string]int) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		path := append(append([]int(nil), prefix...), i)
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		// Встроенная структура без тега раскрывается; с тегом db — это обычное поле (например, свой тип со Scanner)
+		if sf.Anonymous && !hasTag && isStruct(ft) {
+			collect(ft, path, fields, depth)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = SnakeCase(sf.Name)
+		}
+		name = strings.ToLower(name)
+
+		// Как в Go: поле внешней структуры скрывает одноимённое поле встроенной
+		if d, ok := depth[name]; ok && d <= len(path) {
+			continue
+		}
+		fields[name] = path
+		depth[name] = len(path)
+	}
+}
+
+var scannerType = reflect.TypeFor[sql.Scanner]()
+
+// isStruct — структура, которую нужно раскладывать по полям; time.Time и типы с методом Scan (sql.NullString) сканируются целиком
+func isStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if t.PkgPath() == "time" && t.Name() == "Time" {
+		return false
+	}
+
+	return !reflect.PointerTo(t).Implements(scannerType)
+}
+
+// SnakeCase: UserID -> user_id, TotalAmount -> total_amount, HTTPServer -> http_server
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if prevLower || nextLower {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
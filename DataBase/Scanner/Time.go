@@ -0,0 +1,72 @@
+package Scanner
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Форматы, в которых время приходит строкой: SQLite (CURRENT_TIMESTAMP, Dialect.TimeLayout), запись time.Time драйвером, RFC 3339
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Time — приёмник для rows.Scan / row.Scan, который кладёт в dst и time.Time, и время строкой; строка без зоны — UTC
+func Time(dst *time.Time) sql.Scanner {
+	return timeDest{dst}
+}
+
+type timeDest struct {
+	dst *time.Time
+}
+
+func (t timeDest) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*t.dst = v
+		return nil
+	case string:
+		return parseTime(v, t.dst)
+	case []byte:
+		return parseTime(string(v), t.dst)
+	case nil:
+		return fmt.Errorf("scanner: NULL в time.Time — нужно поле *time.Time")
+	}
+
+	return fmt.Errorf("scanner: %T не время", src)
+}
+
+type nullTime struct {
+	dst **time.Time
+}
+
+func (t nullTime) Scan(src any) error {
+	if src == nil {
+		*t.dst = nil
+		return nil
+	}
+
+	var v time.Time
+	if err := (timeDest{&v}).Scan(src); err != nil {
+		return err
+	}
+	*t.dst = &v
+
+	return nil
+}
+
+func parseTime(s string, dst *time.Time) error {
+	for _, layout := range timeLayouts {
+		if v, err := time.Parse(layout, s); err == nil {
+			*dst = v
+			return nil
+		}
+	}
+
+	return fmt.Errorf("scanner: %q не похоже на время", s)
+}
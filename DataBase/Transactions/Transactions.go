@@ -0,0 +1,180 @@
+package Transactions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"learning/DataBase/Dialect"
+)
+
+/*
+WithTx — правильная обёртка над Begin/Commit/Rollback.
+
+	err := runner.WithTx(ctx, Transactions.Options{Isolation: sql.LevelSerializable}, func(ctx context.Context, tx *sql.Tx) error {
+		...
+		return nil // commit; ошибка или panic — rollback
+	})
+
+Что делает:
+	- Commit, если fn вернула nil; ошибку Commit возвращает (в отличие от defer tx.Commit() в TransactionExample);
+	- Rollback, если fn вернула ошибку или запаниковала; panic после отката пробрасывается дальше;
+	- уровень изоляции: sql.LevelReadCommitted (по умолчанию в Postgres), sql.LevelRepeatableRead, sql.LevelSerializable;
+	- повтор всей транзакции с экспоненциальной задержкой, если Postgres ответил 40001 (serialization_failure)
+	  или 40P01 (deadlock_detected) — при SERIALIZABLE это нормальная ситуация, и правильная реакция — просто повторить.
+	  В SQLite то же значит SQLITE_BUSY: базу держит другая пишущая транзакция (классификация — Dialect.Classify).
+	  Поэтому fn должна быть повторяемой: без побочных эффектов вне базы (письма, HTTP-запросы — через outbox);
+	- вложенный WithTx (с ctx, который получила fn) не открывает новую транзакцию, а ставит SAVEPOINT:
+	  ошибка внутри откатывает только вложенную часть, внешняя транзакция может продолжить.
+	  Повторяет при конфликте только самый внешний вызов — savepoint после serialization_failure уже не спасти.
+	  Уровень изоляции и ReadOnly у savepoint свои быть не могут — они у всей транзакции. Поэтому вложенный вызов,
+	  который явно просит другой уровень или ReadOnly внутри пишущей транзакции, получает ErrNestedOptions, а не молча
+	  выполняется с настройками внешней. Options{} (или те же Isolation/ReadOnly, что у внешней) — можно;
+	  MaxRetries и паузы вложенного вызова не используются.
+*/
+
+type Options struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+
+	MaxRetries int           // сколько раз повторять при 40001/40P01; 0 — DefaultMaxRetries, -1 — не повторять
+	Backoff    time.Duration // первая пауза, дальше удваивается; по умолчанию 20ms
+	MaxBackoff time.Duration // по умолчанию 1s
+}
+
+const DefaultMaxRetries = 5
+
+// ErrNestedOptions — вложенный WithTx просит Isolation или ReadOnly, которых у внешней транзакции нет
+var ErrNestedOptions = errors.New("transactions: вложенная транзакция не может сменить уровень изоляции или ReadOnly")
+
+type Runner struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Runner {
+	return &Runner{db: db}
+}
+
+type txKey struct{}
+
+type txState struct {
+	tx    *sql.Tx
+	depth int
+	opts  Options // с какими Isolation и ReadOnly открыта внешняя транзакция
+}
+
+// FromContext возвращает транзакцию, внутри которой выполняется код, если она есть
+func FromContext(ctx context.Context) (*sql.Tx, bool) {
+	st, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+
+	return st.tx, true
+}
+
+func (r *Runner) WithTx(ctx context.Context, opts Options, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		if (opts.Isolation != sql.LevelDefault && opts.Isolation != st.opts.Isolation) || (opts.ReadOnly && !st.opts.ReadOnly) {
+			return fmt.Errorf("%w: внешняя %s, read only %t; вложенная %s, read only %t",
+				ErrNestedOptions, st.opts.Isolation, st.opts.ReadOnly, opts.Isolation, opts.ReadOnly)
+		}
+		return savepoint(ctx, st, fn)
+	}
+
+	retries := opts.MaxRetries
+	if retries == 0 {
+		retries = DefaultMaxRetries
+	}
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = 20 * time.Millisecond
+	}
+	maxBackoff := opts.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := r.once(ctx, opts, fn)
+		if err == nil || !Retryable(err) || attempt >= retries {
+			if err != nil && attempt > 0 {
+				err = fmt.Errorf("transactions: после %d повторов: %w", attempt, err)
+			}
+			return err
+		}
+
+		// Полная случайная задержка (full jitter): конкурирующие транзакции не повторяются синхронно
+		sleep := min(backoff<<attempt, maxBackoff)
+		sleep = time.Duration(rand.Int64N(int64(sleep)) + 1)
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(sleep):
+		}
+	}
+}
+
+func (r *Runner) once(ctx context.Context, opts Options, fn func(ctx context.Context, tx *sql.Tx) error) (err error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{tx: tx, opts: opts}), tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return errors.Join(err, fmt.Errorf("rollback: %w", rbErr))
+		}
+		return err
+	}
+
+	// Для SERIALIZABLE конфликт часто обнаруживается именно на COMMIT, его тоже нужно повторять
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func savepoint(ctx context.Context, st *txState, fn func(ctx context.Context, tx *sql.Tx) error) (err error) {
+	name := fmt.Sprintf("sp_%d", st.depth+1)
+
+	if _, err := st.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			st.tx.ExecContext(context.WithoutCancel(ctx), "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+	}()
+
+	inner := &txState{tx: st.tx, depth: st.depth + 1, opts: st.opts}
+	if err := fn(context.WithValue(ctx, txKey{}, inner), st.tx); err != nil {
+		if _, rbErr := st.tx.ExecContext(context.WithoutCancel(ctx), "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback to %s: %w", name, rbErr))
+		}
+		return err
+	}
+
+	_, err = st.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+
+	return err
+}
+
+// Retryable — ошибка, после которой транзакцию стоит повторить целиком: 40001 / 40P01 в Postgres, SQLITE_BUSY в SQLite
+func Retryable(err error) bool {
+	return errors.Is(Dialect.Classify(err), Dialect.ErrSerialization)
+}
@@ -0,0 +1,92 @@
+//go:build sqlite
+
+package Transactions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"learning/DataBase/Connection"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DATABASE_URL", filepath.Join(t.TempDir(), "test.db"))
+
+	db, err := Connection.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec("CREATE TABLE items (name TEXT NOT NULL)"); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// Вложенный WithTx — savepoint: его ошибка откатывает только вложенную часть, а чужие Isolation/ReadOnly — ErrNestedOptions
+func TestNestedWithTx(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	r := New(db)
+
+	insert := func(name string) func(ctx context.Context, tx *sql.Tx) error {
+		return func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "INSERT INTO items (name) VALUES (?)", name)
+			return err
+		}
+	}
+	failed := errors.New("вложенная часть не удалась")
+
+	err := r.WithTx(ctx, Options{}, func(ctx context.Context, tx *sql.Tx) error {
+		if err := insert("outer")(ctx, tx); err != nil {
+			return err
+		}
+		if err := r.WithTx(ctx, Options{}, insert("inner")); err != nil {
+			return err
+		}
+		if err := r.WithTx(ctx, Options{}, func(ctx context.Context, tx *sql.Tx) error {
+			insert("rolled back")(ctx, tx)
+			return failed
+		}); !errors.Is(err, failed) {
+			t.Errorf("ошибка savepoint: %v", err)
+		}
+
+		if err := r.WithTx(ctx, Options{Isolation: sql.LevelSerializable}, insert("serializable")); !errors.Is(err, ErrNestedOptions) {
+			t.Errorf("другой уровень изоляции: %v, ожидалась ErrNestedOptions", err)
+		}
+		if err := r.WithTx(ctx, Options{ReadOnly: true}, insert("read only")); !errors.Is(err, ErrNestedOptions) {
+			t.Errorf("ReadOnly внутри пишущей: %v, ожидалась ErrNestedOptions", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT name FROM items ORDER BY rowid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	if len(names) != 2 || names[0] != "outer" || names[1] != "inner" {
+		t.Fatalf("в таблице %v, ожидалось [outer inner]", names)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	_ "github.com/lib/pq"
 	"log"
 	"time"
+
+	"learning/DataBase/Connection"
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Scanner"
 )
 
 /*
@@ -63,6 +66,7 @@ rows.Scan() копирует значения текущей строки в п
 	Именованные. В запросе указывается имя параметра, перед именем идёт знак : !!!!!!!!!!!! 🔍 POSTGRES - НЕ ПОДДЕРЖИВАЕТ  🔍!!!!!!!!!
 		В качестве значения передаётся объект типа sql.NamedArg. Чтобы его получить, нужно вызвать функцию Named() из пакета database/sql. В качестве аргументов этой функции нужно передать имя параметра и значение:
 		rows, err := db.Query("SELECT product FROM products WHERE price > :price", sql.Named("price", 500))
+		Для Postgres это делает пакет Named: переписывает :price в $1 до отправки в драйвер (Named.ExampleNamedQuery)
 */
 
 func main() {
@@ -103,10 +107,20 @@ type Order struct { //Тестовая струтура заказов
 }
 
 func ConnectToDB() (*sql.DB, error) {
-	connStr := "host=postgres user=go_user password=go_password dbname=go_learning_db sslmode=disable"
-	return sql.Open("postgres", connStr) //возвращает структуру для пула Соединений с БД
+	return Connection.Open() // драйвер из DB_DRIVER, параметры из DB_HOST, DB_USER, ... (см. DataBase/Connection)
+}
+
+// dialect — диалект драйвера из DB_DRIVER: запросы ниже написаны с $n, Rebind переводит их в ?1 для SQLite
+func dialect() Dialect.Dialect {
+	d, err := Dialect.For(Connection.Driver())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return d
 }
 
+// Deprecated: учебный вариант, ошибки не возвращаются; в коде используйте Orders.OrderRepository.List
 func SimpleSelectQuery(db *sql.DB) ([]Order, error) { //Работа со всеми полями
 	var orders []Order
 
@@ -121,7 +135,7 @@ func SimpleSelectQuery(db *sql.DB) ([]Order, error) { //Работа со все
 
 	for rows.Next() { //проходится по результатам
 		var o Order
-		err := rows.Scan(&o.ID, &o.UserID, &o.TotalAmount, &o.Status, &o.CreatedAt, &o.UpdatedAt) //заполняет переданную структуру, согласно очерёдности данных я так полагаю
+		err := rows.Scan(&o.ID, &o.UserID, &o.TotalAmount, &o.Status, Scanner.Time(&o.CreatedAt), Scanner.Time(&o.UpdatedAt)) //заполняет переданную структуру, согласно очерёдности данных я так полагаю; время в SQLite — текст, его разбирает Scanner.Time
 		if err != nil {
 			log.Println("Ошибка при чтении строки:", err)
 			continue
@@ -136,12 +150,13 @@ func SimpleSelectQuery(db *sql.DB) ([]Order, error) { //Работа со все
 	return orders, nil
 }
 
+// Deprecated: учебный вариант, ошибки не возвращаются; в коде используйте Orders.OrderRepository.Get
 func SimpleSelectWithOneRow(db *sql.DB) Order {
 	var order Order
 
 	row := db.QueryRow("SELECT id, user_id, total_amount, status, created_at, updated_at FROM orders LIMIT 1")
 
-	err := row.Scan(&order.ID, &order.UserID, &order.TotalAmount, &order.Status, &order.CreatedAt, &order.UpdatedAt)
+	err := row.Scan(&order.ID, &order.UserID, &order.TotalAmount, &order.Status, Scanner.Time(&order.CreatedAt), Scanner.Time(&order.UpdatedAt))
 
 	if err != nil {
 		log.Println("Ошибка при чтении строки:", err)
@@ -150,12 +165,14 @@ func SimpleSelectWithOneRow(db *sql.DB) Order {
 	return order
 }
 
+// Deprecated: учебный вариант, ошибки не возвращаются; в коде используйте Orders.OrderRepository.Create
 func SimpleInsertQuery(db *sql.DB) {
-	now := time.Now()
+	d := dialect()
+	now := d.Time(time.Now())
 
 	res, err := db.Exec(
-		`INSERT INTO orders (user_id, total_amount, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5)`,
+		d.Rebind(`INSERT INTO orders (user_id, total_amount, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5)`),
 		2,
 		1200.25,
 		"pending",
@@ -173,9 +190,10 @@ func SimpleInsertQuery(db *sql.DB) {
 	return
 }
 
+// Deprecated: учебный вариант, ошибки не возвращаются; в коде используйте Orders.OrderRepository.Update
 func SimpleUpdateQuery(db *sql.DB) {
 	res, err := db.Exec(
-		`UPDATE orders SET total_amount = $1 WHERE id = $2`,
+		dialect().Rebind(`UPDATE orders SET total_amount = $1 WHERE id = $2`),
 		1555.55,
 		4,
 	)
@@ -190,9 +208,10 @@ func SimpleUpdateQuery(db *sql.DB) {
 	return
 }
 
+// Deprecated: учебный вариант, ошибки не возвращаются; в коде используйте Orders.OrderRepository.Delete
 func SimpleDeleteQuery(db *sql.DB) {
 	_, err := db.Exec(
-		`DELETE FROM orders WHERE id = $1`,
+		dialect().Rebind(`DELETE FROM orders WHERE id = $1`),
 		11,
 	)
 
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	_ "github.com/lib/pq"
 	"log"
+	"strconv"
 	"time"
+
+	"learning/DataBase/Connection"
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Orders"
+	"learning/DataBase/Outbox"
+	"learning/DataBase/Transactions"
 )
 
 /*
@@ -26,8 +33,7 @@ func (db *DB) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error)
 */
 
 func main() {
-	connStr := "host=postgres user=go_user password=go_password dbname=go_learning_db sslmode=disable"
-	db, err := sql.Open("postgres", connStr)
+	db, err := Connection.Open()
 
 	if err != nil {
 		log.Fatal("Ошибка подключения:", err)
@@ -38,69 +44,116 @@ func main() {
 	defer db.Close()
 }
 
-func TransactionExample(db *sql.DB) {
-	// Начало транзакции
-	tx, err := db.Begin()
-	if err != nil {
-		log.Fatal("Ошибка начала транзакции:", err)
-	}
+/*
+Ручной вариант через defer (как было раньше) терял ошибку Commit, не откатывался при panic и не задавал уровень изоляции:
 
-	// В конце фиксируем или откатываем
 	defer func() {
 		if err != nil {
 			tx.Rollback()
-			fmt.Println("Транзакция откатена")
 		} else {
-			tx.Commit()
-			fmt.Println("Транзакция успешно выполнена")
+			tx.Commit() // ошибка Commit пропадала
 		}
 	}()
 
-	// Создаем новый заказ для пользователя с id=1
-	var orderID int
-	err = tx.QueryRow(
-		`INSERT INTO orders (user_id, total_amount, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5) RETURNING id`,
-		1, 0.0, "pending", time.Now(), time.Now(),
-	).Scan(&orderID)
+Теперь то же самое делает Transactions.WithTx: commit/rollback, panic, изоляция и повтор при 40001/40P01.
+*/
+
+func TransactionExample(db *sql.DB) {
+	ctx := context.Background()
+	runner := Transactions.New(db)
+
+	// Запросы ниже написаны с $n; Rebind переводит их под драйвер из DB_DRIVER, Time — время в виде, который понимает база
+	d, err := Dialect.For(Connection.Driver())
 	if err != nil {
-		log.Println("Ошибка при создании заказа:", err)
-		return
+		log.Fatal(err)
 	}
 
-	// Добавляем товары в order_items
-	items := []struct {
-		ProductID int
-		Quantity  int
-		Price     float64
-	}{
-		{1, 2, 999.99}, // 2 iPhone 15
-		{3, 3, 29.99},  // 3 Футболки Nike
+	// Уведомление других систем о заказе — через outbox (миграция 0007), публикует его Outbox.Relay
+	events, err := Outbox.New(Connection.Driver())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Заказ создаётся репозиторием, а не INSERT'ом: так пишется и история статусов, и событие order.status_changed —
+	// hook outbox вызывается в той же транзакции, что и всё ниже
+	repo, err := Orders.NewSQL(ctx, db, d)
+	if err != nil {
+		log.Fatal(err)
 	}
+	defer repo.Close()
+	repo.Machine.OnTransition(events.OrderHook())
 
-	total := 0.0
-	for _, item := range items {
-		_, err = tx.Exec(
-			`INSERT INTO order_items (order_id, product_id, quantity, price, created_at)
-			 VALUES ($1, $2, $3, $4, $5)`,
-			orderID, item.ProductID, item.Quantity, item.Price, time.Now(),
-		)
+	err = runner.WithTx(ctx, Transactions.Options{Isolation: sql.LevelRepeatableRead}, func(ctx context.Context, tx *sql.Tx) error {
+		// Создаем новый заказ для пользователя с id=1
+		order, err := repo.InTx(ctx, tx).Create(ctx, Orders.Order{UserID: 1})
 		if err != nil {
-			log.Println("Ошибка при добавлении элемента заказа:", err)
-			return
+			return fmt.Errorf("создание заказа: %w", err)
 		}
-		total += float64(item.Quantity) * item.Price
-	}
+		orderID := order.ID
+
+		// Добавляем товары в order_items
+		items := []struct {
+			ProductID int
+			Quantity  int
+			Price     float64
+		}{
+			{1, 2, 999.99}, // 2 iPhone 15
+			{3, 3, 29.99},  // 3 Футболки Nike
+		}
+
+		var total float64
+		created := Outbox.OrderCreated{OrderID: orderID, UserID: 1}
+		for _, item := range items {
+			_, err = tx.ExecContext(ctx,
+				d.Rebind(`INSERT INTO order_items (order_id, product_id, quantity, price, created_at)
+				 VALUES ($1, $2, $3, $4, $5)`),
+				orderID, item.ProductID, item.Quantity, item.Price, d.Time(time.Now()),
+			)
+			if err != nil {
+				return fmt.Errorf("добавление элемента заказа: %w", err)
+			}
+			created.Items = append(created.Items, Outbox.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity, Price: item.Price})
+		}
+
+		// Вложенный WithTx — это SAVEPOINT: если бонусного товара нет, откатится только его вставка, а заказ останется
+		bonus := runner.WithTx(ctx, Transactions.Options{}, func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx,
+				d.Rebind(`INSERT INTO order_items (order_id, product_id, quantity, price, created_at) VALUES ($1, $2, 1, 0, $3)`),
+				orderID, 999, d.Time(time.Now()),
+			)
+			return err
+		})
+		if bonus != nil {
+			log.Println("Бонус не добавлен:", bonus)
+		} else {
+			created.Items = append(created.Items, Outbox.OrderItem{ProductID: 999, Quantity: 1})
+		}
+
+		// Обновляем total_amount в заказе. Сумму считает база по вставленным позициям, в DECIMAL, — а не Go во float64
+		// отдельно от них: так она не разойдётся с order_items. Уже разошедшиеся суммы находит dbctl reconcile
+		err = tx.QueryRowContext(ctx,
+			d.Rebind(`UPDATE orders SET total_amount = (SELECT COALESCE(SUM(quantity * price), 0) FROM order_items WHERE order_id = $1),
+			 updated_at = $2 WHERE id = $1 RETURNING total_amount`),
+			orderID, d.Time(time.Now()),
+		).Scan(&total)
+		if err != nil {
+			return fmt.Errorf("обновление суммы заказа: %w", err)
+		}
+
+		// Событие пишется в той же транзакции: откатится заказ — откатится и событие, закоммитится — relay его отправит.
+		// Отправлять в брокер прямо отсюда нельзя: commit может не случиться, а WithTx ещё и повторяет транзакцию при 40001
+		created.TotalAmount = total
+		if _, err := events.Enqueue(ctx, tx, "order.created", strconv.Itoa(orderID), created); err != nil {
+			return fmt.Errorf("событие о заказе: %w", err)
+		}
+
+		return nil
+	})
 
-	// Обновляем total_amount в заказе
-	_, err = tx.Exec(
-		`UPDATE orders SET total_amount=$1, updated_at=$2 WHERE id=$3`,
-		total, time.Now(), orderID,
-	)
 	if err != nil {
-		log.Println("Ошибка при обновлении суммы заказа:", err)
+		fmt.Println("Транзакция откатена:", err)
 		return
 	}
 
-	// Если мы дошли сюда, транзакция выполнена успешно
+	fmt.Println("Транзакция успешно выполнена")
 }
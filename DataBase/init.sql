@@ -1,4 +1,6 @@
 -- Создание таблиц для изучения работы с PostgreSQL в Go
+-- Схема ведётся миграциями (Migrations/sql, go run ./tools/dbctl up); таблицы здесь создаются с IF NOT EXISTS
+-- и совпадают с миграциями 0001-0005, поэтому dbctl up на базе из docker-compose просто отметит их применёнными
 
 -- Таблица пользователей
 CREATE TABLE IF NOT EXISTS users (
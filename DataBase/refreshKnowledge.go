@@ -27,6 +27,7 @@ package main
 //Крткий справочник, шпаргалка SQL:
 //	Тут я опускаю но во всех sql командах на конце должен быть ;
 //	Ключевые слова можно писать в нижнем регистре, но мне привычно в верхнем
+//	Те же конструкции в коде собираются пакетом Query, без склейки строк: Query.ExampleCheatSheet повторяет примеры ниже
 //
 //	CRUD операции:
 //		Выборка данных (R):
@@ -84,6 +85,21 @@ package main
 //			Примеры:
 //				(1) DELETE * FROM tablename - Удаляет все записи из таблицы tablename РАВНОСИЛЬНО DELETE FROM tablename
 //
+//	Группировка:
+//		Конструкции:
+//			GROUP BY - собирает строки с одинаковыми значениями полей в одну группу, на каждую группу - одна строка результата (Пример 1)
+//			SUM, AVG, MAX, MIN, COUNT - агрегатные функции, считаются по строкам группы; без GROUP BY вся выборка - одна группа (Пример 2)
+//			HAVING - условие на группу, проверяется после группировки; WHERE - до, поэтому условие на SUM(...) в WHERE не написать (Пример 3)
+//			В SELECT рядом с агрегатами можно писать только поля из GROUP BY
+//			COUNT(*) считает строки, COUNT(field) - строки, где field не NULL, COUNT(DISTINCT field) - разные значения
+//			SUM по пустой выборке - NULL, а не 0: COALESCE(SUM(field), 0)
+//
+//		Примеры:
+//			(1) SELECT status, COUNT(*) FROM orders GROUP BY status - сколько заказов в каждом статусе
+//			(2) SELECT COUNT(*), SUM(total_amount), AVG(total_amount) FROM orders WHERE status = 'paid' - число оплаченных заказов, выручка и средний чек
+//			(3) SELECT product_id, SUM(quantity) FROM order_items GROUP BY product_id HAVING SUM(quantity) >= 10 - товары, проданные от 10 штук
+//		На настоящих таблицах - пакет Reports: выручка по дням/неделям/месяцам, топ товаров, средний чек, заказы по статусам
+//
 //
 //	Индексы:
 //		В упрощённом виде индексы можно рассматривать как дополнительную таблицу, у которой каждая запись состоит из двух значений: значение из таблицы, по которому происходит сортировка, и сам индекс — специальный идентификатор, определяющий соответствующую этому значению строку. Находим значение в индексе — получаем строку в таблице. Чтобы поиск и выборки с использованием индекса работали корректно, СУБД следит за тем, чтобы всегда были проиндексированы все записи.
@@ -131,3 +147,15 @@ package main
 //
 //			-- Если возникла ошибка — откатываем
 //			ROLLBACK;
+//
+//	Резервное копирование:
+//		pg_dump - выгрузка базы Postgres: схема и данные, в SQL-скрипт или в свой формат (-Fc) для pg_restore (Пример 1)
+//		pg_restore - загрузка дампа -Fc; --clean удаляет объекты перед созданием, -t - только одна таблица (Пример 2)
+//		Дамп снимается в одной транзакции REPEATABLE READ: все таблицы - снимок одного момента, работу базы он не блокирует
+//		После вставки строк с явными id счётчик SERIAL не двигается - нужен setval, иначе следующий INSERT получит занятый id (Пример 3)
+//
+//		Примеры:
+//			(1) pg_dump -Fc -d learning -f learning.dump
+//			(2) pg_restore --clean -d learning learning.dump
+//			(3) SELECT setval(pg_get_serial_sequence('orders', 'id'), (SELECT MAX(id) FROM orders))
+//		Без Postgres - пакет Backup и dbctl export / import: архив tar.gz с таблицами в JSONL или CSV, который загружается и в SQLite
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"learning/DataBase/Backup"
+)
+
+// флаги export и import
+var backupFlags struct {
+	format string
+	tables string
+	since  string
+	clean  bool
+}
+
+func init() {
+	commands["export"] = command{
+		usage: "[файл.tar.gz] [-format jsonl|csv] [-tables a,b] [-since 2025-03-01] — выгрузить таблицы в архив",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&backupFlags.format, "format", string(Backup.JSONL), "формат файлов таблиц: jsonl или csv")
+			tableFlags(fs)
+		},
+		run: exportArchive,
+	}
+	commands["import"] = command{
+		usage: "файл.tar.gz [-tables a,b] [-since 2025-03-01] [-clean] — загрузить архив export",
+		flags: func(fs *flag.FlagSet) {
+			tableFlags(fs)
+			fs.BoolVar(&backupFlags.clean, "clean", false, "удалить строки выбранных таблиц перед загрузкой")
+		},
+		run: importArchive,
+	}
+}
+
+func tableFlags(fs *flag.FlagSet) {
+	fs.StringVar(&backupFlags.tables, "tables", "", "таблицы через запятую; по умолчанию все")
+	fs.StringVar(&backupFlags.since, "since", "", "только строки, изменённые с даты (2006-01-02 или RFC 3339, UTC)")
+}
+
+// exportArchive пишет архив в файл; без имени — orders-<время>.tar.gz в текущем каталоге
+func exportArchive(ctx context.Context, e *env, args []string) error {
+	format, err := Backup.ParseFormat(backupFlags.format)
+	if err != nil {
+		return err
+	}
+	tables, since, err := backupFilter()
+	if err != nil {
+		return err
+	}
+
+	name := "orders-" + time.Now().Format("20060102-150405") + ".tar.gz"
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	db, err := e.open(ctx)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := Backup.Export(ctx, db, f, Backup.ExportOptions{Driver: e.driver, Format: format, Tables: tables, Since: since})
+	if err = errors.Join(err, f.Close()); err != nil {
+		os.Remove(name) // недописанный архив хуже, чем никакого
+		return err
+	}
+
+	for _, t := range manifest.Tables {
+		fmt.Printf("%-22s %8d  %s\n", t.Name, t.Rows, t.SHA256[:12])
+	}
+	fmt.Printf("%s — схема версии %d\n", name, manifest.SchemaVersion)
+
+	return nil
+}
+
+func importArchive(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("import: укажите файл архива")
+	}
+	tables, since, err := backupFilter()
+	if err != nil {
+		return err
+	}
+
+	db, err := e.open(ctx)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	result, err := Backup.Import(ctx, db, f, Backup.ImportOptions{Driver: e.driver, Tables: tables, Since: since, Clean: backupFlags.clean})
+	if err != nil {
+		return err
+	}
+
+	for _, l := range result.Loaded {
+		line := fmt.Sprintf("%-22s %8d", l.Table, l.Rows)
+		if l.Skipped > 0 {
+			line += fmt.Sprintf("  (пропущено по since: %d)", l.Skipped)
+		}
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+// backupFilter — -tables и -since
+func backupFilter() (tables []string, since time.Time, err error) {
+	if backupFlags.tables != "" {
+		for _, t := range strings.Split(backupFlags.tables, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tables = append(tables, t)
+			}
+		}
+	}
+
+	if s := backupFlags.since; s != "" {
+		if since, err = time.Parse(time.DateOnly, s); err != nil {
+			if since, err = time.Parse(time.RFC3339, s); err != nil {
+				return nil, time.Time{}, fmt.Errorf("-since %q: ожидается 2006-01-02 или RFC 3339", s)
+			}
+		}
+	}
+
+	return tables, since, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	_ "github.com/lib/pq"
+
+	"learning/DataBase/Connection"
+)
+
+/*
+Утилита обслуживания базы заказов:
+
+	go run ./tools/dbctl up                       — применить все новые миграции
+	go run ./tools/dbctl down 1                   — откатить последнюю миграцию
+	go run ./tools/dbctl status                   — какие миграции применены, есть ли дрейф
+	go run ./tools/dbctl create add_orders_note   — создать пару файлов следующей версии в Migrations/sql
+	go run ./tools/dbctl unlock                   — снять блокировку, оставшуюся от упавшего процесса (SQLite)
+	go run ./tools/dbctl seed                     — очистить таблицы и загрузить фикстуры магазина (Fixtures/data/shop.yaml)
+	go run ./tools/dbctl seed fixtures/*.json     — свои фикстуры
+	go run ./tools/dbctl reconcile                — сверить суммы заказов с позициями
+	go run ./tools/dbctl reconcile -fix           — и исправить расхождения (-batch 500, -top 10)
+	go run ./tools/dbctl export backup.tar.gz     — выгрузить все таблицы в архив JSONL (-format csv, -tables, -since)
+	go run ./tools/dbctl import backup.tar.gz     — загрузить архив одной транзакцией (-tables, -since, -clean)
+
+Подключение: -driver postgres|sqlite и -dsn; по умолчанию postgres и DSN из DB_HOST, DB_USER, ... (см. Connection).
+Драйвер SQLite в бинарник по умолчанию не входит: go run -tags sqlite ./tools/dbctl up -driver sqlite -dsn orders.db
+*/
+
+type command struct {
+	usage string
+	flags func(fs *flag.FlagSet) // собственные флаги команды, кроме общих -driver, -dsn, -dir
+	run   func(ctx context.Context, env *env, args []string) error
+}
+
+// env — общие флаги и открытая база
+type env struct {
+	driver string
+	dsn    string
+	dir    string
+
+	db *sql.DB
+}
+
+var commands = map[string]command{}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	name := os.Args[1]
+	cmd, ok := commands[name]
+	if !ok {
+		usage()
+	}
+
+	e := &env{}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&e.driver, "driver", "postgres", "драйвер database/sql: postgres или sqlite")
+	fs.StringVar(&e.dsn, "dsn", "", "строка подключения; по умолчанию из переменных DB_* для postgres")
+	fs.StringVar(&e.dir, "dir", "Migrations/sql", "каталог с файлами миграций (для create)")
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	args := parseInterspersed(fs, os.Args[2:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.run(ctx, e, args); err != nil {
+		fmt.Fprintln(os.Stderr, "dbctl:", err)
+		os.Exit(1)
+	}
+}
+
+// open подключается к базе по флагам; команды, которым база не нужна (create), его не вызывают
+func (e *env) open(ctx context.Context) (*sql.DB, error) {
+	if e.db != nil {
+		return e.db, nil
+	}
+
+	dsn := e.dsn
+	if dsn == "" {
+		if e.driver != "postgres" {
+			return nil, fmt.Errorf("для драйвера %s нужен -dsn", e.driver)
+		}
+		dsn = Connection.DSN()
+	}
+
+	db, err := sql.Open(e.driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	e.db = db
+
+	return db, nil
+}
+
+// parseInterspersed позволяет писать флаги и после позиционных аргументов: dbctl down 2 -driver sqlite
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dbctl <команда> [-driver postgres|sqlite] [-dsn dsn] [аргументы]")
+	for _, name := range sortedCommands() {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+	os.Exit(2)
+}
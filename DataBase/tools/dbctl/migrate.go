@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Migrations"
+)
+
+func init() {
+	commands["up"] = command{usage: "применить новые миграции", run: migrateUp}
+	commands["down"] = command{usage: "N — откатить N последних миграций (по умолчанию 1)", run: migrateDown}
+	commands["status"] = command{usage: "список миграций и их состояние", run: migrateStatus}
+	commands["create"] = command{usage: "name — создать файлы новой миграции в -dir", run: migrateCreate}
+	commands["unlock"] = command{usage: "снять блокировку миграций, оставшуюся от упавшего процесса", run: migrateUnlock}
+}
+
+func (e *env) migrator(ctx context.Context) (*Migrations.Migrator, error) {
+	db, err := e.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := Dialect.For(e.driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return Migrations.New(db, d)
+}
+
+func migrateUp(ctx context.Context, e *env, _ []string) error {
+	m, err := e.migrator(ctx)
+	if err != nil {
+		return err
+	}
+
+	done, err := m.Up(ctx)
+	if err != nil {
+		return err
+	}
+	if len(done) == 0 {
+		fmt.Println("схема актуальна")
+	}
+
+	return nil
+}
+
+func migrateDown(ctx context.Context, e *env, args []string) error {
+	n := 1
+	if len(args) > 0 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("down: %q — не число", args[0])
+		}
+	}
+
+	m, err := e.migrator(ctx)
+	if err != nil {
+		return err
+	}
+
+	done, err := m.Down(ctx, n)
+	if err != nil {
+		return err
+	}
+	if len(done) == 0 {
+		fmt.Println("нечего откатывать")
+	}
+
+	return nil
+}
+
+func migrateStatus(ctx context.Context, e *env, _ []string) error {
+	m, err := e.migrator(ctx)
+	if err != nil {
+		return err
+	}
+
+	states, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range states {
+		state := "pending"
+		switch {
+		case st.Missing:
+			state = "MISSING FILE"
+		case st.Drift:
+			state = "DRIFT"
+		case st.Applied:
+			state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%-32s %s\n", st.Migration, state)
+	}
+
+	return nil
+}
+
+func migrateCreate(_ context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("create: нужно имя миграции")
+	}
+
+	files, err := Migrations.Create(e.dir, args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		fmt.Println(f)
+	}
+
+	return nil
+}
+
+func migrateUnlock(ctx context.Context, e *env, _ []string) error {
+	m, err := e.migrator(ctx)
+	if err != nil {
+		return err
+	}
+
+	return m.ForceUnlock(ctx)
+}
+
+func sortedCommands() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
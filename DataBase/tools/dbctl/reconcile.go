@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"learning/DataBase/Reconcile"
+)
+
+var reconcileOpts Reconcile.Options
+
+func init() {
+	commands["reconcile"] = command{
+		usage: "[-fix] [-batch N] [-top N] — сверить orders.total_amount с суммой order_items",
+		flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&reconcileOpts.Fix, "fix", false, "исправить расхождения (пачка — одна транзакция)")
+			fs.IntVar(&reconcileOpts.BatchSize, "batch", Reconcile.DefaultBatchSize, "заказов за один запрос")
+			fs.IntVar(&reconcileOpts.Top, "top", Reconcile.DefaultTop, "сколько самых больших расхождений показать в итоге")
+		},
+		run: reconcile,
+	}
+}
+
+// reconcile печатает расхождения по мере обхода, в конце — итог; найденные и не исправленные расхождения — код выхода 1
+func reconcile(ctx context.Context, e *env, _ []string) error {
+	db, err := e.open(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts := reconcileOpts
+	opts.Driver = e.driver
+	opts.OnMismatch = func(m Reconcile.Mismatch) { fmt.Println(m) }
+
+	report, err := Reconcile.Run(ctx, db, opts)
+	if report != nil {
+		fmt.Printf("\nпроверено заказов: %d (пачек: %d, %s)\n", report.Checked, report.Batches, report.Duration.Round(time.Millisecond))
+		fmt.Printf("расхождений: %d, без позиций: %d, исправлено: %d, в сумме: %+.2f\n",
+			report.Mismatched, report.WithoutItems, report.Fixed, report.Delta)
+		if len(report.Biggest) > 0 {
+			fmt.Println("самые большие:")
+			for _, m := range report.Biggest {
+				fmt.Println("  ", m)
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if left := report.Mismatched - report.Fixed; left > 0 {
+		hint := ""
+		if !opts.Fix {
+			hint = " — исправить: dbctl reconcile -fix"
+		}
+		return fmt.Errorf("осталось расхождений: %d%s", left, hint)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"learning/DataBase/Fixtures"
+)
+
+func init() {
+	commands["seed"] = command{usage: "[файлы] — очистить таблицы и загрузить фикстуры (по умолчанию Fixtures/data)", run: seed}
+}
+
+// seed загружает фикстуры из файлов .json / .yaml или, без аргументов, встроенные данные магазина
+func seed(ctx context.Context, e *env, args []string) error {
+	set, err := seedSet(args)
+	if err != nil {
+		return err
+	}
+
+	db, err := e.open(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := Fixtures.Load(ctx, db, set, Fixtures.Options{Driver: e.driver}); err != nil {
+		return err
+	}
+
+	for _, t := range set.Tables {
+		fmt.Printf("%-12s %d\n", t.Name, len(t.Rows))
+	}
+
+	return nil
+}
+
+func seedSet(files []string) (*Fixtures.Set, error) {
+	if len(files) == 0 {
+		return Fixtures.Shop()
+	}
+
+	set := &Fixtures.Set{}
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		part, err := Fixtures.Parse(name, data)
+		if err != nil {
+			return nil, err
+		}
+		if err := set.Merge(part); err != nil {
+			return nil, err
+		}
+	}
+
+	return set, nil
+}
@@ -0,0 +1,291 @@
+package Admin
+
+import (
+	"crypto/rand"
+	"embed"
+	"encoding/base64"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	settings "learning/Config"
+	"learning/DataBase/Orders"
+	"learning/HTTP"
+	"learning/StandartLibrary"
+)
+
+/*
+Админка для поддержки: пользователи и заказы.
+
+Шаблоны html/template:
+	layout.html — общий каркас страницы, вызывает {{template "content" .}};
+	partials/*.html — повторяющиеся куски (навигация, сообщения об ошибках);
+	pages/*.html — страницы, каждая определяет блок "content".
+Для каждой страницы собирается свой набор layout + partials + страница, иначе блоки "content" разных страниц перетирали бы друг друга.
+
+html/template сам экранирует данные по контексту (HTML, атрибут, URL, JS) — в отличие от text/template из Generations.TemplateExample.
+
+Шаблоны и CSS встроены в бинарник через embed, отдельные файлы рядом с программой не нужны.
+
+Вход: логин + bcrypt-хеш пароля (StandartLibrary.ExampleHashPassword), сессия — случайный идентификатор в cookie "session".
+Все формы защищены HTTP.CSRF, токен привязан к этой же сессии.
+*/
+
+//go:embed templates static
+var assets embed.FS
+
+const (
+	sessionCookie = "session"
+	flashCookie   = "flash"
+)
+
+type Config struct {
+	Accounts   map[string]string      // логин -> bcrypt-хеш пароля
+	Orders     Orders.OrderRepository // nil — Orders.Memory
+	CSRFSecret []byte
+	SessionTTL time.Duration
+	Secure     bool
+}
+
+type Server struct {
+	cfg   Config
+	csrf  *HTTP.CSRF
+	pages map[string]*template.Template
+
+	mu       sync.Mutex
+	sessions map[string]session
+}
+
+type session struct {
+	user    string
+	expires time.Time
+}
+
+// pageData — общие поля для всех страниц
+type pageData struct {
+	Title  string
+	User   string
+	Flash  string
+	Errors map[string]string
+	Query  string
+	Data   any
+}
+
+func New(cfg Config) (*Server, error) {
+	if cfg.Orders == nil {
+		cfg.Orders = Orders.NewMemory()
+	}
+	if cfg.SessionTTL == 0 {
+		cfg.SessionTTL = 8 * time.Hour
+	}
+
+	s := &Server{
+		cfg:      cfg,
+		csrf:     &HTTP.CSRF{Secret: cfg.CSRFSecret, SessionCookie: sessionCookie, Secure: cfg.Secure},
+		pages:    make(map[string]*template.Template),
+		sessions: make(map[string]session),
+	}
+
+	if err := s.parseTemplates(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Server) parseTemplates() error {
+	base := template.New("layout").Funcs(template.FuncMap{
+		// заглушки, настоящие функции подставляются на каждый запрос в render
+		"csrfField": func() template.HTML { return "" },
+		"csrfToken": func() string { return "" },
+		"money":     func(v float64) string { return formatMoney(v) },
+		"date":      func(t time.Time) string { return t.Format("02.01.2006 15:04") },
+	})
+
+	base, err := base.ParseFS(assets, "templates/layout.html", "templates/partials/*.html")
+	if err != nil {
+		return err
+	}
+
+	pages, err := fs.Glob(assets, "templates/pages/*.html")
+	if err != nil {
+		return err
+	}
+
+	for _, page := range pages {
+		tmpl, err := template.Must(base.Clone()).ParseFS(assets, page)
+		if err != nil {
+			return err
+		}
+		s.pages[page[len("templates/pages/"):]] = tmpl
+	}
+
+	return nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	static, _ := fs.Sub(assets, "static")
+	mux.Handle("GET /admin/static/", http.StripPrefix("/admin/static/", http.FileServerFS(static)))
+
+	mux.HandleFunc("GET /admin/login", s.loginForm)
+	mux.HandleFunc("POST /admin/login", s.login)
+	mux.HandleFunc("POST /admin/logout", s.logout)
+
+	mux.Handle("GET /admin/{$}", s.requireLogin(http.RedirectHandler("/admin/users", http.StatusFound)))
+
+	mux.Handle("GET /admin/users", s.requireLogin(http.HandlerFunc(s.listUsers)))
+	mux.Handle("GET /admin/users/new", s.requireLogin(http.HandlerFunc(s.newUser)))
+	mux.Handle("POST /admin/users", s.requireLogin(http.HandlerFunc(s.createUser)))
+	mux.Handle("GET /admin/users/{id}/edit", s.requireLogin(http.HandlerFunc(s.editUser)))
+	mux.Handle("POST /admin/users/{id}", s.requireLogin(http.HandlerFunc(s.updateUser)))
+	mux.Handle("POST /admin/users/{id}/delete", s.requireLogin(http.HandlerFunc(s.deleteUser)))
+
+	mux.Handle("GET /admin/orders", s.requireLogin(http.HandlerFunc(s.listOrders)))
+	mux.Handle("GET /admin/orders/new", s.requireLogin(http.HandlerFunc(s.newOrder)))
+	mux.Handle("POST /admin/orders", s.requireLogin(http.HandlerFunc(s.createOrder)))
+	mux.Handle("GET /admin/orders/{id}/edit", s.requireLogin(http.HandlerFunc(s.editOrder)))
+	mux.Handle("POST /admin/orders/{id}", s.requireLogin(http.HandlerFunc(s.updateOrder)))
+	mux.Handle("POST /admin/orders/{id}/delete", s.requireLogin(http.HandlerFunc(s.deleteOrder)))
+
+	return s.csrf.Middleware(mux)
+}
+
+func (s *Server) render(w http.ResponseWriter, r *http.Request, page string, status int, data pageData) {
+	tmpl, ok := s.pages[page]
+	if !ok {
+		http.Error(w, "Template not found", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tmpl.Funcs(s.csrf.CSRFFuncs(r))
+
+	data.User = s.currentUser(r)
+	if flash := popFlash(w, r); data.Flash == "" {
+		data.Flash = flash
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Println("admin: render", page, err)
+	}
+}
+
+func (s *Server) loginForm(w http.ResponseWriter, r *http.Request) {
+	s.render(w, r, "login.html", http.StatusOK, pageData{Title: "Вход"})
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	username := r.PostFormValue("username")
+	hash, ok := s.cfg.Accounts[username]
+
+	if !ok || StandartLibrary.CheckHashOfPassword(hash, r.PostFormValue("password")) != nil {
+		s.render(w, r, "login.html", http.StatusUnauthorized, pageData{Title: "Вход", Flash: "Неверный логин или пароль"})
+		return
+	}
+
+	id := newSessionID()
+	s.mu.Lock()
+	s.sessions[id] = session{user: username, expires: time.Now().Add(s.cfg.SessionTTL)}
+	s.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    id,
+		Path:     "/admin",
+		HttpOnly: true,
+		Secure:   s.cfg.Secure,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(s.cfg.SessionTTL.Seconds()),
+	})
+
+	// Новая сессия — новый CSRF-токен, подписанный уже её идентификатором
+	withSession := r.Clone(r.Context())
+	withSession.Header.Del("Cookie")
+	withSession.AddCookie(&http.Cookie{Name: sessionCookie, Value: id})
+	s.csrf.Rotate(w, withSession)
+
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		s.mu.Lock()
+		delete(s.sessions, cookie.Value)
+		s.mu.Unlock()
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "", Path: "/admin", MaxAge: -1})
+	http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
+}
+
+func (s *Server) currentUser(r *http.Request) string {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return ""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[cookie.Value]
+	if !ok {
+		return ""
+	}
+	if time.Now().After(sess.expires) {
+		delete(s.sessions, cookie.Value)
+		return ""
+	}
+
+	return sess.user
+}
+
+func (s *Server) requireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.currentUser(r) == "" {
+			http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newSessionID() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ExampleAdminServer — админка рядом с /api/v1/users на адресе из Config (по умолчанию :8383), вход admin / admin
+func ExampleAdminServer() {
+	hash, err := StandartLibrary.ExampleHashPassword("admin")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	admin, err := New(Config{
+		Accounts:   map[string]string{"admin": hash},
+		CSRFSecret: HTTP.ExampleCSRFSecret(),
+		Secure:     settings.Current().HTTP.Secure,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mux := HTTP.UsersMux()
+	mux.Handle("/admin/", admin.Handler())
+
+	log.Fatal(http.ListenAndServe(settings.Current().HTTP.Addr, mux))
+}
@@ -0,0 +1,57 @@
+package Admin
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"learning/DataBase/Dialect"
+	"learning/DataBase/Orders"
+	"learning/DataBase/Outbox"
+)
+
+/*
+Заказы для админки — репозиторий Orders из модуля DataBase (подключён в go.mod через replace => ./DataBase):
+Config.Orders — Orders.SQL на Postgres или SQLite (NewSQLOrders) или Orders.Memory, чтобы админку можно было запустить без базы.
+
+Автомат статусов, история order_status_history и событие order.status_changed в outbox — те же, что у остального кода
+(Orders.Machine, Orders.SQL, Outbox.OrderHook), здесь они не повторяются, а запросы переводит в диалект базы сам Orders.SQL.
+Форма заказа сохраняется одним Update: поля и смена статуса — в одной транзакции, отвергнутый переход не сохраняет ничего.
+*/
+
+type Order = Orders.Order
+
+// machine — только для списка статусов в форме; проверяет переход автомат репозитория
+var machine = Orders.NewMachine()
+
+// NewSQLOrders — Orders.SQL для базы db, смена статуса в той же транзакции пишет событие в outbox
+func NewSQLOrders(ctx context.Context, db *sql.DB, d Dialect.Dialect) (*Orders.SQL, error) {
+	ob, err := Outbox.New(d.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := Orders.NewSQL(ctx, db, d)
+	if err != nil {
+		return nil, err
+	}
+	repo.Machine.OnTransition(ob.OrderHook())
+
+	return repo, nil
+}
+
+// orderFilter — строка поиска в Orders.Filter: число — user_id, иначе статус; пустая — все заказы
+func orderFilter(query string) Orders.Filter {
+	f := Orders.Filter{Limit: 200}
+
+	if query = strings.TrimSpace(query); query != "" {
+		if userID, err := strconv.Atoi(query); err == nil {
+			f.UserID = userID
+		} else {
+			f.Status = query
+		}
+	}
+
+	return f
+}
@@ -0,0 +1,290 @@
+package Admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"learning/DataBase/Orders"
+	"learning/HTTP"
+)
+
+// Страницы пользователей работают с теми же данными, что и /api/v1/users
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	var found []HTTP.User
+	for _, u := range HTTP.AllUsers() {
+		if query == "" || containsFold(u.Name, query) || containsFold(u.Email, query) || u.ID == query {
+			found = append(found, u)
+		}
+	}
+
+	s.render(w, r, "users.html", http.StatusOK, pageData{Title: "Пользователи", Query: query, Data: found})
+}
+
+func (s *Server) newUser(w http.ResponseWriter, r *http.Request) {
+	s.render(w, r, "user_form.html", http.StatusOK, pageData{Title: "Новый пользователь", Data: HTTP.User{}})
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	u := HTTP.User{Name: strings.TrimSpace(r.PostFormValue("name")), Email: strings.TrimSpace(r.PostFormValue("email"))}
+
+	if errs := validateUser(u); len(errs) > 0 {
+		s.render(w, r, "user_form.html", http.StatusUnprocessableEntity, pageData{Title: "Новый пользователь", Errors: errs, Data: u})
+		return
+	}
+
+	u = HTTP.AddUser(u)
+	redirectWithFlash(w, r, "/admin/users", "Пользователь "+u.ID+" создан")
+}
+
+func (s *Server) editUser(w http.ResponseWriter, r *http.Request) {
+	u, ok := HTTP.UserByID(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.render(w, r, "user_form.html", http.StatusOK, pageData{Title: "Пользователь " + u.ID, Data: u})
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request) {
+	u := HTTP.User{
+		ID:    r.PathValue("id"),
+		Name:  strings.TrimSpace(r.PostFormValue("name")),
+		Email: strings.TrimSpace(r.PostFormValue("email")),
+	}
+
+	if errs := validateUser(u); len(errs) > 0 {
+		s.render(w, r, "user_form.html", http.StatusUnprocessableEntity, pageData{Title: "Пользователь " + u.ID, Errors: errs, Data: u})
+		return
+	}
+
+	if !HTTP.ReplaceUser(u) {
+		http.NotFound(w, r)
+		return
+	}
+
+	redirectWithFlash(w, r, "/admin/users", "Пользователь "+u.ID+" сохранён")
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !HTTP.RemoveUser(id) {
+		http.NotFound(w, r)
+		return
+	}
+
+	redirectWithFlash(w, r, "/admin/users", "Пользователь "+id+" удалён")
+}
+
+func validateUser(u HTTP.User) map[string]string {
+	errs := map[string]string{}
+
+	if u.Name == "" {
+		errs["name"] = "Укажите имя"
+	}
+	if _, err := mail.ParseAddress(u.Email); err != nil {
+		errs["email"] = "Некорректный email"
+	}
+
+	return errs
+}
+
+// Страницы заказов
+
+type orderForm struct {
+	Order    Order
+	Statuses []string
+}
+
+// statusChoices — текущий статус и те, в которые из него можно перейти; новый заказ — только начальный
+func statusChoices(current string) []string {
+	if current == "" {
+		return []string{machine.Initial()}
+	}
+
+	return append([]string{current}, machine.Next(current)...)
+}
+
+func (s *Server) listOrders(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	orders, err := s.cfg.Orders.List(r.Context(), orderFilter(query))
+	if err != nil {
+		http.Error(w, "Не удалось загрузить заказы", http.StatusInternalServerError)
+		return
+	}
+
+	s.render(w, r, "orders.html", http.StatusOK, pageData{Title: "Заказы", Query: query, Data: orders})
+}
+
+func (s *Server) newOrder(w http.ResponseWriter, r *http.Request) {
+	s.render(w, r, "order_form.html", http.StatusOK, pageData{
+		Title: "Новый заказ",
+		Data:  orderForm{Order: Order{Status: machine.Initial()}, Statuses: statusChoices("")},
+	})
+}
+
+func (s *Server) createOrder(w http.ResponseWriter, r *http.Request) {
+	o, errs := parseOrderForm(r)
+	if o.Status != machine.Initial() && errs["status"] == "" {
+		errs["status"] = "Новый заказ создаётся в статусе " + machine.Initial()
+	}
+	if len(errs) > 0 {
+		s.render(w, r, "order_form.html", http.StatusUnprocessableEntity, pageData{Title: "Новый заказ", Errors: errs, Data: orderForm{o, statusChoices("")}})
+		return
+	}
+
+	o, err := s.cfg.Orders.Create(r.Context(), o)
+	if err != nil {
+		http.Error(w, "Не удалось создать заказ", http.StatusInternalServerError)
+		return
+	}
+
+	redirectWithFlash(w, r, "/admin/orders", fmt.Sprintf("Заказ %d создан", o.ID))
+}
+
+func (s *Server) editOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	o, err := s.cfg.Orders.Get(r.Context(), id)
+	if errors.Is(err, Orders.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Не удалось загрузить заказ", http.StatusInternalServerError)
+		return
+	}
+
+	s.render(w, r, "order_form.html", http.StatusOK, pageData{Title: fmt.Sprintf("Заказ %d", o.ID), Data: orderForm{o, statusChoices(o.Status)}})
+}
+
+// updateOrder сохраняет поля заказа и новый статус одним Update: переход проверяется в той же транзакции,
+// и если он отвергнут, поля тоже не сохраняются
+func (s *Server) updateOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	cur, err := s.cfg.Orders.Get(r.Context(), id)
+	if errors.Is(err, Orders.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Не удалось загрузить заказ", http.StatusInternalServerError)
+		return
+	}
+
+	o, errs := parseOrderForm(r)
+	o.ID = id
+	if len(errs) > 0 {
+		s.render(w, r, "order_form.html", http.StatusUnprocessableEntity, pageData{Title: fmt.Sprintf("Заказ %d", id), Errors: errs, Data: orderForm{o, statusChoices(cur.Status)}})
+		return
+	}
+
+	ctx := Orders.WithMeta(r.Context(), Orders.Meta{Actor: s.currentUser(r), Reason: "admin"})
+	_, err = s.cfg.Orders.Update(ctx, o)
+
+	var transitionErr *Orders.TransitionError
+	if errors.As(err, &transitionErr) {
+		errs["status"] = err.Error()
+		s.render(w, r, "order_form.html", http.StatusUnprocessableEntity, pageData{Title: fmt.Sprintf("Заказ %d", id), Errors: errs, Data: orderForm{o, statusChoices(cur.Status)}})
+		return
+	}
+	if errors.Is(err, Orders.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Не удалось сохранить заказ", http.StatusInternalServerError)
+		return
+	}
+
+	redirectWithFlash(w, r, "/admin/orders", fmt.Sprintf("Заказ %d сохранён", id))
+}
+
+func (s *Server) deleteOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	err = s.cfg.Orders.Delete(r.Context(), id)
+	if errors.Is(err, Orders.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Не удалось удалить заказ", http.StatusInternalServerError)
+		return
+	}
+
+	redirectWithFlash(w, r, "/admin/orders", fmt.Sprintf("Заказ %d удалён", id))
+}
+
+func parseOrderForm(r *http.Request) (Order, map[string]string) {
+	errs := map[string]string{}
+	var o Order
+
+	userID, err := strconv.Atoi(r.PostFormValue("user_id"))
+	if err != nil || userID <= 0 {
+		errs["user_id"] = "Укажите id пользователя"
+	}
+	o.UserID = userID
+
+	total, err := strconv.ParseFloat(strings.Replace(r.PostFormValue("total_amount"), ",", ".", 1), 64)
+	if err != nil || total < 0 {
+		errs["total_amount"] = "Сумма должна быть неотрицательным числом"
+	}
+	o.TotalAmount = total
+
+	o.Status = r.PostFormValue("status")
+	if !slices.Contains(Orders.Statuses, o.Status) {
+		errs["status"] = "Неизвестный статус"
+	}
+
+	return o, errs
+}
+
+// redirectWithFlash передаёт сообщение следующей странице через короткоживущую cookie
+func redirectWithFlash(w http.ResponseWriter, r *http.Request, path, flash string) {
+	http.SetCookie(w, &http.Cookie{Name: flashCookie, Value: url.QueryEscape(flash), Path: "/admin", MaxAge: 60, HttpOnly: true})
+	http.Redirect(w, r, path, http.StatusSeeOther)
+}
+
+func popFlash(w http.ResponseWriter, r *http.Request) string {
+	cookie, err := r.Cookie(flashCookie)
+	if err != nil {
+		return ""
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: flashCookie, Path: "/admin", MaxAge: -1})
+	flash, _ := url.QueryUnescape(cookie.Value)
+
+	return flash
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func formatMoney(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
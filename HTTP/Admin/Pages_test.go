@@ -0,0 +1,72 @@
+package Admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"learning/DataBase/Orders"
+)
+
+// postOrderForm отправляет форму заказа id от имени вошедшего пользователя admin, минуя CSRF
+func postOrderForm(t *testing.T, s *Server, id string, form url.Values) int {
+	t.Helper()
+
+	s.sessions["test-session"] = session{user: "admin", expires: time.Now().Add(time.Hour)}
+
+	r := httptest.NewRequest(http.MethodPost, "/admin/orders/"+id, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.AddCookie(&http.Cookie{Name: sessionCookie, Value: "test-session"})
+	r.SetPathValue("id", id)
+
+	w := httptest.NewRecorder()
+	s.updateOrder(w, r)
+
+	return w.Code
+}
+
+// Поля и статус сохраняются вместе: отвергнутый переход не сохраняет и поля, принятый попадает в историю с автором
+func TestUpdateOrderIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	repo := Orders.NewMemory()
+
+	s, err := New(Config{Orders: repo, CSRFSecret: make([]byte, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := repo.Create(ctx, Orders.Order{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := strconv.Itoa(o.ID)
+
+	// Оплатить заказ с нулевой суммой guard не даёт — user_id тоже остаётся прежним
+	if code := postOrderForm(t, s, id, url.Values{"user_id": {"2"}, "total_amount": {"0"}, "status": {"paid"}}); code != http.StatusUnprocessableEntity {
+		t.Fatalf("оплата пустого заказа: %d, ожидался 422", code)
+	}
+	if got, _ := repo.Get(ctx, o.ID); got.UserID != 1 || got.Status != Orders.StatusPending {
+		t.Fatalf("после отвергнутого перехода заказ изменился: %+v", got)
+	}
+
+	if code := postOrderForm(t, s, id, url.Values{"user_id": {"2"}, "total_amount": {"10,50"}, "status": {"paid"}}); code != http.StatusSeeOther {
+		t.Fatalf("сохранение: %d, ожидался 303", code)
+	}
+	got, _ := repo.Get(ctx, o.ID)
+	if got.UserID != 2 || got.TotalAmount != 10.5 || got.Status != Orders.StatusPaid {
+		t.Fatalf("после сохранения: %+v", got)
+	}
+
+	history, err := repo.History(ctx, o.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := history[len(history)-1]; last.Actor != "admin" || last.From != Orders.StatusPending || last.To != Orders.StatusPaid {
+		t.Fatalf("в истории %+v", last)
+	}
+}
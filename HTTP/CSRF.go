@@ -0,0 +1,297 @@
+package HTTP
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"learning/Config"
+)
+
+/*
+Защита от CSRF (Cross-Site Request Forgery)
+
+Браузер сам прикладывает cookie к любому запросу на наш домен, в том числе к форме, отправленной с чужого сайта.
+Поэтому для небезопасных методов (POST, PUT, PATCH, DELETE) одной сессионной cookie недостаточно — нужно доказательство,
+что форма была отрисована нашим сервером.
+
+Используется схема double-submit cookie с подписью:
+	сервер кладёт случайный токен в cookie csrf_token и тот же токен в скрытое поле формы (или заголовок X-CSRF-Token);
+	чужой сайт не может прочитать нашу cookie, значит не может подставить правильное значение в форму;
+	cookie подписана HMAC вместе с идентификатором сессии — токен, выданный одной сессии, не подойдёт другой,
+		а при смене сессии (логин/логаут) токен автоматически перевыпускается.
+
+Дополнительно для небезопасных методов проверяется Origin (или Referer, если Origin нет): запрос должен прийти с нашего хоста.
+Запросы с Authorization: Bearer ... пропускаются — браузер не подставляет такой заголовок сам, значит CSRF для API с токенами невозможен.
+
+В шаблонах: {{ csrfField }} вставляет <input type="hidden" ...>. Значение в форме маскируется случайной маской на каждый рендер,
+чтобы токен не повторялся в сжатых ответах (атака BREACH).
+*/
+
+type CSRF struct {
+	Secret []byte // ключ HMAC, минимум 32 байта
+
+	CookieName    string // по умолчанию "csrf_token"
+	FieldName     string // имя поля формы, по умолчанию "csrf_token"
+	HeaderName    string // по умолчанию "X-CSRF-Token"
+	SessionCookie string // cookie с идентификатором сессии, по умолчанию "session"
+
+	TrustedOrigins []string                 // дополнительные разрешённые хосты, например "admin.example.com"
+	Exempt         func(*http.Request) bool // свои исключения
+	Secure         bool                     // Secure-флаг cookie (для HTTPS)
+}
+
+type csrfContextKey struct{}
+
+const csrfTokenLen = 32
+
+func (c *CSRF) defaults() {
+	if c.CookieName == "" {
+		c.CookieName = "csrf_token"
+	}
+	if c.FieldName == "" {
+		c.FieldName = "csrf_token"
+	}
+	if c.HeaderName == "" {
+		c.HeaderName = "X-CSRF-Token"
+	}
+	if c.SessionCookie == "" {
+		c.SessionCookie = "session"
+	}
+}
+
+func (c *CSRF) Middleware(next http.Handler) http.Handler {
+	c.defaults()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isBearer(r) || (c.Exempt != nil && c.Exempt(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := c.tokenFromCookie(r)
+		if !ok {
+			token = c.issue(w, r)
+		}
+
+		w.Header().Add("Vary", "Cookie")
+		r = r.WithContext(context.WithValue(r.Context(), csrfContextKey{}, token))
+
+		if !isSafeMethod(r.Method) {
+			if !c.sameOrigin(r) {
+				http.Error(w, "CSRF: origin check failed", http.StatusForbidden)
+				return
+			}
+
+			// Токен из cookie, которой раньше не было, не может совпасть с присланным — значит запрос отклоняется
+			submitted := unmaskToken(c.submitted(r))
+			if !ok || submitted == nil || subtle.ConstantTimeCompare(submitted, token) != 1 {
+				http.Error(w, "CSRF: invalid token", http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Rotate выдаёт новый токен — вызывается после логина, когда меняется сессия
+func (c *CSRF) Rotate(w http.ResponseWriter, r *http.Request) *http.Request {
+	c.defaults()
+	token := c.issue(w, r)
+
+	return r.WithContext(context.WithValue(r.Context(), csrfContextKey{}, token))
+}
+
+// CSRFToken возвращает маскированный токен для текущего запроса (для заголовка X-CSRF-Token в JS)
+func CSRFToken(r *http.Request) string {
+	token, _ := r.Context().Value(csrfContextKey{}).([]byte)
+	if token == nil {
+		return ""
+	}
+
+	return maskToken(token)
+}
+
+// CSRFFuncs — функции для html/template: {{ csrfField }} и {{ csrfToken }}
+func (c *CSRF) CSRFFuncs(r *http.Request) template.FuncMap {
+	c.defaults()
+
+	return template.FuncMap{
+		"csrfToken": func() string { return CSRFToken(r) },
+		"csrfField": func() template.HTML {
+			return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(c.FieldName) +
+				`" value="` + template.HTMLEscapeString(CSRFToken(r)) + `">`)
+		},
+	}
+}
+
+func (c *CSRF) issue(w http.ResponseWriter, r *http.Request) []byte {
+	token := make([]byte, csrfTokenLen)
+	rand.Read(token)
+
+	value := base64.RawURLEncoding.EncodeToString(token) + "." + base64.RawURLEncoding.EncodeToString(c.sign(r, token))
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.CookieName,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   c.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return token
+}
+
+func (c *CSRF) tokenFromCookie(r *http.Request) ([]byte, bool) {
+	cookie, err := r.Cookie(c.CookieName)
+	if err != nil {
+		return nil, false
+	}
+
+	rawToken, rawSig, found := strings.Cut(cookie.Value, ".")
+	if !found {
+		return nil, false
+	}
+
+	token, err := base64.RawURLEncoding.DecodeString(rawToken)
+	if err != nil || len(token) != csrfTokenLen {
+		return nil, false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(rawSig)
+	if err != nil {
+		return nil, false
+	}
+
+	return token, hmac.Equal(sig, c.sign(r, token))
+}
+
+// sign привязывает токен к текущей сессии
+func (c *CSRF) sign(r *http.Request, token []byte) []byte {
+	session := ""
+	if cookie, err := r.Cookie(c.SessionCookie); err == nil {
+		session = cookie.Value
+	}
+
+	mac := hmac.New(sha256.New, c.Secret)
+	mac.Write([]byte(session))
+	mac.Write([]byte{0})
+	mac.Write(token)
+
+	return mac.Sum(nil)
+}
+
+func (c *CSRF) submitted(r *http.Request) string {
+	if v := r.Header.Get(c.HeaderName); v != "" {
+		return v
+	}
+
+	return r.PostFormValue(c.FieldName)
+}
+
+func (c *CSRF) sameOrigin(r *http.Request) bool {
+	source := r.Header.Get("Origin")
+	if source == "" {
+		source = r.Header.Get("Referer")
+	}
+
+	if source == "" {
+		// Без Origin и Referer по HTTP ничего проверить нельзя, полагаемся на токен; по HTTPS браузер Referer присылает
+		return r.TLS == nil
+	}
+
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, trusted := range c.TrustedOrigins {
+		if strings.EqualFold(u.Host, trusted) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+
+	return false
+}
+
+func isBearer(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	return len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ")
+}
+
+// maskToken: base64(маска || маска XOR токен)
+func maskToken(token []byte) string {
+	out := make([]byte, 2*len(token))
+	rand.Read(out[:len(token)])
+
+	for i := range token {
+		out[len(token)+i] = out[i] ^ token[i]
+	}
+
+	return base64.RawURLEncoding.EncodeToString(out)
+}
+
+func unmaskToken(masked string) []byte {
+	data, err := base64.RawURLEncoding.DecodeString(masked)
+	if err != nil || len(data) != 2*csrfTokenLen {
+		return nil
+	}
+
+	token := make([]byte, csrfTokenLen)
+	for i := range token {
+		token[i] = data[i] ^ data[csrfTokenLen+i]
+	}
+
+	return token
+}
+
+var csrfFormTemplate = `<form method="POST" action="/profile">
+	{{ csrfField }}
+	<input name="email">
+	<button>Сохранить</button>
+</form>`
+
+// ExampleCSRFSecret — ключ из CSRF_KEY (Config.HTTPConfig.CSRFKey), а без него учебный, годный только для локального запуска
+func ExampleCSRFSecret() []byte {
+	if key := Config.Current().HTTP.CSRFKey; key != "" {
+		return []byte(key)
+	}
+
+	return []byte("change-me-change-me-change-me-32b")
+}
+
+// ExampleCSRFForm — форма, защищённая CSRF; API под /api/ с Bearer-токеном проверку не проходит
+func ExampleCSRFForm() {
+	csrf := &CSRF{Secret: ExampleCSRFSecret(), Secure: Config.Current().HTTP.Secure}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /profile", func(w http.ResponseWriter, r *http.Request) {
+		tmpl := template.Must(template.New("form").Funcs(csrf.CSRFFuncs(r)).Parse(csrfFormTemplate))
+		tmpl.Execute(w, nil)
+	})
+	mux.HandleFunc("POST /profile", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Сохранено: " + r.PostFormValue("email")))
+	})
+
+	http.ListenAndServe(Config.Current().HTTP.Addr, csrf.Middleware(mux))
+}
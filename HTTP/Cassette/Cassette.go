@@ -0,0 +1,143 @@
+package Cassette
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+/*
+Кассета — JSON-файл с записанными HTTP-взаимодействиями (запрос + ответ), как в библиотеках VCR.
+
+Идея: один раз выполнить запросы к настоящему сайту в режиме записи, сохранить их в файл,
+а дальше в тестах и примерах воспроизводить ответы из файла — без сети, быстро и всегда одинаково.
+
+Тело хранится строкой, если это валидный UTF-8, иначе — в base64 (поле body_base64).
+Секреты в заголовках (Authorization, Cookie, ...) перед записью заменяются на "[REDACTED]".
+*/
+
+const Redacted = "[REDACTED]"
+
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 string      `json:"body_base64,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status     string      `json:"status"`
+	StatusCode int         `json:"status_code"`
+	Proto      string      `json:"proto,omitempty"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 string      `json:"body_base64,omitempty"`
+}
+
+func (r RecordedRequest) BodyBytes() []byte {
+	return decodeBody(r.Body, r.BodyBase64)
+}
+
+func (r RecordedResponse) BodyBytes() []byte {
+	return decodeBody(r.Body, r.BodyBase64)
+}
+
+func encodeBody(b []byte) (text, b64 string) {
+	if utf8.Valid(b) {
+		return string(b), ""
+	}
+
+	return "", base64.StdEncoding.EncodeToString(b)
+}
+
+func decodeBody(text, b64 string) []byte {
+	if b64 != "" {
+		b, _ := base64.StdEncoding.DecodeString(b64)
+		return b
+	}
+
+	return []byte(text)
+}
+
+// Load читает кассету; отсутствующий файл — os.ErrNotExist
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+
+	return &c, nil
+}
+
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// redactHeaders возвращает копию заголовков, где значения из списка заменены заглушкой
+func redactHeaders(h http.Header, names []string) http.Header {
+	out := h.Clone()
+	if out == nil {
+		return nil
+	}
+
+	for _, name := range names {
+		key := http.CanonicalHeaderKey(name)
+		if values, ok := out[key]; ok {
+			for i := range values {
+				values[i] = Redacted
+			}
+		}
+	}
+
+	// Hop-by-hop заголовки при воспроизведении только мешают
+	out.Del("Connection")
+	out.Del("Keep-Alive")
+
+	return out
+}
+
+// DefaultRedact — заголовки, которые никогда не должны попадать в файл
+var DefaultRedact = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+func isRedacted(values []string) bool {
+	return len(values) > 0 && allEqual(values, Redacted)
+}
+
+func allEqual(values []string, v string) bool {
+	for _, s := range values {
+		if s != v {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,75 @@
+package Cassette
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"learning/StandartLibrary"
+)
+
+// ExampleOfflineSiteTitle — StandartLibrary.GetSiteTitleWith без сети: ответ берётся из cassettes/site_title.json
+func ExampleOfflineSiteTitle() {
+	rec, err := New("HTTP/Cassette/cassettes/site_title.json", Options{Mode: ModeReplay})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	title, err := StandartLibrary.GetSiteTitleWith(rec.Client(), "https://chr.rbc.ru")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("H1:", title)
+}
+
+// ExampleOfflineHTTPBin повторяет GetExample, PostExample и DoExample из packages/net_http поверх кассеты.
+// Сопоставление по методу, URL, телу и User-Agent: два GET на один URL различаются заголовком
+func ExampleOfflineHTTPBin() {
+	rec, err := New("HTTP/Cassette/cassettes/httpbin.json", Options{
+		Mode:    ModeAuto, // если удалить файл, кассета будет записана заново с настоящего httpbin.org
+		Matcher: &Matcher{Method: true, URL: true, Body: true, Headers: []string{"User-Agent"}},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rec.Stop()
+
+	restore := rec.Install() // http.Get и http.DefaultClient теперь ходят через кассету
+	defer restore()
+
+	resp, err := http.Get("https://httpbin.org/get")
+	if err != nil {
+		log.Fatal(err)
+	}
+	printBody(resp)
+
+	resp, err = http.Post("https://httpbin.org/post", "application/json", strings.NewReader(`{"name": "John", "age": 30}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	printBody(resp)
+
+	req, _ := http.NewRequest("GET", "https://httpbin.org/get", nil)
+	req.Header.Set("User-Agent", "MyApp/1.0")
+	req.Header.Set("Accept", "application/json")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printBody(resp)
+
+	// Запроса нет в кассете — понятная ошибка вместо похода в сеть
+	if _, err := http.Get("https://httpbin.org/status/418"); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func printBody(resp *http.Response) {
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Printf("Status: %s\nBody: %s\n", resp.Status, body)
+}
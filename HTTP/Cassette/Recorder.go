@@ -0,0 +1,340 @@
+package Cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+/*
+Recorder — http.RoundTripper, который записывает или воспроизводит взаимодействия.
+
+Режимы:
+	ModeReplay — только воспроизведение; запрос, которого нет в кассете, завершается *NoMatchError;
+	ModeRecord — всё идёт в сеть и записывается заново (старая кассета перезаписывается в Stop);
+	ModeAuto   — если кассета есть, воспроизводим, иначе записываем.
+
+Сопоставление запроса с записью задаётся Matcher: метод, URL, тело, выбранные заголовки.
+Каждая запись воспроизводится один раз и по порядку — два одинаковых GET получат два разных записанных ответа.
+*/
+
+type Mode int
+
+const (
+	ModeAuto Mode = iota
+	ModeReplay
+	ModeRecord
+)
+
+type Matcher struct {
+	Method  bool
+	URL     bool
+	Body    bool
+	Headers []string // заголовки, которые должны совпасть
+}
+
+// DefaultMatcher сравнивает метод и полный URL
+var DefaultMatcher = Matcher{Method: true, URL: true}
+
+type Options struct {
+	Mode      Mode
+	Matcher   *Matcher
+	Redact    []string          // дополнительные заголовки для скрытия, к DefaultRedact
+	Transport http.RoundTripper // реальный транспорт для записи, по умолчанию http.DefaultTransport
+}
+
+type Recorder struct {
+	path      string
+	mode      Mode
+	matcher   Matcher
+	redact    []string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+func New(path string, opts Options) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		mode:      opts.Mode,
+		matcher:   DefaultMatcher,
+		redact:    append(append([]string(nil), DefaultRedact...), opts.Redact...),
+		transport: opts.Transport,
+	}
+	if opts.Matcher != nil {
+		r.matcher = *opts.Matcher
+	}
+	if r.transport == nil {
+		r.transport = http.DefaultTransport
+	}
+
+	c, err := Load(path)
+	switch {
+	case err == nil:
+	case errors.Is(err, os.ErrNotExist):
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("кассета %s не найдена (режим replay)", path)
+		}
+		c = nil
+	default:
+		return nil, err
+	}
+
+	if r.mode == ModeAuto {
+		if c != nil {
+			r.mode = ModeReplay
+		} else {
+			r.mode = ModeRecord
+		}
+	}
+
+	if r.mode == ModeRecord || c == nil {
+		c = &Cassette{Version: 1}
+	}
+
+	r.cassette = c
+	r.used = make([]bool, len(c.Interactions))
+
+	return r, nil
+}
+
+// Client — http.Client поверх рекордера
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Recording сообщает, идёт ли запись (полезно, чтобы в тестах пропускать проверки, зависящие от живых данных)
+func (r *Recorder) Recording() bool {
+	return r.mode == ModeRecord
+}
+
+// Stop сохраняет кассету, если шла запись
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cassette.Save(r.path)
+}
+
+// Unused возвращает записи, которые так и не были воспроизведены
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []Interaction
+	for i, used := range r.used {
+		if !used {
+			out = append(out, r.cassette.Interactions[i])
+		}
+	}
+
+	return out
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+
+	return r.record(req, body)
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || !r.matches(in.Request, req, body) {
+			continue
+		}
+
+		r.used[i] = true
+
+		return buildResponse(in.Response, req), nil
+	}
+
+	return nil, &NoMatchError{Cassette: r.path, Method: req.Method, URL: req.URL.String(), Matcher: r.matcher, Recorded: r.cassette.Interactions}
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := r.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	in := Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: redactHeaders(req.Header, r.redact),
+		},
+		Response: RecordedResponse{
+			Status:     resp.Status,
+			StatusCode: resp.StatusCode,
+			Proto:      resp.Proto,
+			Headers:    redactHeaders(resp.Header, r.redact),
+		},
+	}
+	in.Request.Body, in.Request.BodyBase64 = encodeBody(body)
+	in.Response.Body, in.Response.BodyBase64 = encodeBody(respBody)
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.used = append(r.used, true)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+func (r *Recorder) matches(rec RecordedRequest, req *http.Request, body []byte) bool {
+	m := r.matcher
+
+	if m.Method && !strings.EqualFold(rec.Method, req.Method) {
+		return false
+	}
+	if m.URL && rec.URL != req.URL.String() {
+		return false
+	}
+	if m.Body && !bytes.Equal(rec.BodyBytes(), body) {
+		return false
+	}
+
+	for _, name := range m.Headers {
+		recorded := rec.Headers.Values(name)
+		// Скрытое значение сравнить нельзя — достаточно, что заголовок был
+		if isRedacted(recorded) {
+			if len(req.Header.Values(name)) == 0 {
+				return false
+			}
+			continue
+		}
+		if strings.Join(recorded, ",") != strings.Join(req.Header.Values(name), ",") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+
+	return body, err
+}
+
+func buildResponse(rec RecordedResponse, req *http.Request) *http.Response {
+	body := rec.BodyBytes()
+	proto := rec.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+
+	major, minor, ok := http.ParseHTTPVersion(proto)
+	if !ok {
+		major, minor = 1, 1
+	}
+
+	return &http.Response{
+		Status:        rec.Status,
+		StatusCode:    rec.StatusCode,
+		Proto:         proto,
+		ProtoMajor:    major,
+		ProtoMinor:    minor,
+		Header:        rec.Headers.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// NoMatchError — в кассете нет подходящей (ещё не использованной) записи
+type NoMatchError struct {
+	Cassette string
+	Method   string
+	URL      string
+	Matcher  Matcher
+	Recorded []Interaction
+}
+
+func (e *NoMatchError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "cassette %s: нет записи для %s %s (сравниваются: %s)", e.Cassette, e.Method, e.URL, e.Matcher)
+
+	if len(e.Recorded) == 0 {
+		sb.WriteString("; кассета пуста")
+		return sb.String()
+	}
+
+	sb.WriteString("; записаны:")
+	for i, in := range e.Recorded {
+		if i == 10 {
+			fmt.Fprintf(&sb, "\n  ... и ещё %d", len(e.Recorded)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "\n  %s %s", in.Request.Method, in.Request.URL)
+	}
+
+	return sb.String()
+}
+
+func (m Matcher) String() string {
+	var parts []string
+
+	if m.Method {
+		parts = append(parts, "method")
+	}
+	if m.URL {
+		parts = append(parts, "url")
+	}
+	if m.Body {
+		parts = append(parts, "body")
+	}
+	for _, h := range m.Headers {
+		parts = append(parts, "header "+h)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Install подменяет транспорт http.DefaultClient, чтобы через кассету пошли и http.Get/http.Post из готовых примеров.
+// Возвращает функцию, которая возвращает всё как было
+func (r *Recorder) Install() (restore func()) {
+	prev := http.DefaultClient.Transport
+	http.DefaultClient.Transport = r
+
+	return func() { http.DefaultClient.Transport = prev }
+}
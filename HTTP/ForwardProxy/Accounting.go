@@ -0,0 +1,100 @@
+package ForwardProxy
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+Учёт трафика по соединениям.
+
+Чтобы посчитать байты, оборачиваем net.Listener: каждое принятое соединение — countingConn, который считает Read/Write.
+http.Server отдаёт при Hijack ровно то соединение, которое вернул Listener, поэтому CONNECT-туннели тоже попадают в учёт.
+Когда соединение закрывается, вызывается Config.OnConnClose со статистикой.
+*/
+
+type ConnStats struct {
+	RemoteAddr string
+	BytesIn    int64 // от клиента к прокси
+	BytesOut   int64 // от прокси к клиенту
+	Opened     time.Time
+	Duration   time.Duration
+}
+
+type countingConn struct {
+	net.Conn
+
+	in, out   atomic.Int64
+	opened    time.Time
+	closeOnce sync.Once
+	onClose   func(ConnStats)
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.in.Add(int64(n))
+
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.out.Add(int64(n))
+
+	return n, err
+}
+
+func (c *countingConn) Close() error {
+	err := c.Conn.Close()
+
+	c.closeOnce.Do(func() {
+		if c.onClose != nil {
+			c.onClose(ConnStats{
+				RemoteAddr: c.RemoteAddr().String(),
+				BytesIn:    c.in.Load(),
+				BytesOut:   c.out.Load(),
+				Opened:     c.opened,
+				Duration:   time.Since(c.opened),
+			})
+		}
+	})
+
+	return err
+}
+
+type countingListener struct {
+	net.Listener
+	onClose func(ConnStats)
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &countingConn{Conn: conn, opened: time.Now(), onClose: l.onClose}, nil
+}
+
+// Serve обслуживает прокси на готовом слушателе с подсчётом трафика
+func (p *Proxy) Serve(l net.Listener) error {
+	server := &http.Server{
+		Handler:           p,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          p.cfg.ErrorLog,
+	}
+
+	return server.Serve(&countingListener{Listener: l, onClose: p.cfg.OnConnClose})
+}
+
+func (p *Proxy) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return p.Serve(l)
+}
@@ -0,0 +1,44 @@
+package ForwardProxy
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// ExampleLocalProxy поднимает прокси с авторизацией и ходит через него тем же способом, что и ProxyURLExample,
+// только без внешнего прокси — всё работает локально
+func ExampleLocalProxy() {
+	proxy := New(Config{
+		Users: map[string]string{"tester": "secret"},
+		Allow: []string{"httpbin.org", "*.example.com", "127.0.0.1"},
+		Deny:  []string{"internal.example.com"},
+		OnConnClose: func(s ConnStats) {
+			log.Printf("%s: in=%d out=%d за %s", s.RemoteAddr, s.BytesIn, s.BytesOut, s.Duration)
+		},
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	go proxy.Serve(l)
+
+	proxyURL := &url.URL{Scheme: "http", Host: l.Addr().String(), User: url.UserPassword("tester", "secret")}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyURL(proxyURL), // для HTTPS клиент сам отправит CONNECT
+		},
+	}
+
+	resp, err := client.Get("https://httpbin.org/get")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	fmt.Printf("Status: %s\n", resp.Status)
+}
@@ -0,0 +1,274 @@
+package ForwardProxy
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Прямой (forward) прокси — клиент сам знает, что ходит через прокси (http.ProxyURL, http.ProxyFromEnvironment, HTTP_PROXY/HTTPS_PROXY).
+
+Клиент отправляет прокси два вида запросов:
+	обычный HTTP — в строке запроса абсолютный URL: GET http://example.com/path HTTP/1.1
+		прокси сам выполняет запрос и возвращает ответ, убрав hop-by-hop заголовки (Proxy-Authorization, Connection и т.д.);
+	CONNECT host:443 — для HTTPS. Прокси открывает TCP-соединение до host:443, отвечает 200 и дальше просто
+		перекачивает байты в обе стороны. Содержимое TLS прокси не видит.
+
+Авторизация: заголовок Proxy-Authorization: Basic base64(user:password), при отказе — 407 и Proxy-Authenticate.
+Доступ: Deny проверяется первым, затем, если Allow не пуст, хост обязан попасть в Allow.
+	Шаблон "example.com" — точное совпадение, "*.example.com" — любой поддомен.
+*/
+
+type Config struct {
+	Users map[string]string // логин -> пароль; пустая мапа отключает авторизацию
+	Realm string
+
+	Allow []string
+	Deny  []string
+
+	DialTimeout time.Duration
+	Transport   http.RoundTripper // транспорт для обычных HTTP-запросов, по умолчанию без прокси
+
+	OnConnClose func(ConnStats) // вызывается при закрытии каждого клиентского соединения
+	ErrorLog    *log.Logger
+}
+
+type Proxy struct {
+	cfg Config
+}
+
+func New(cfg Config) *Proxy {
+	if cfg.Realm == "" {
+		cfg.Realm = "proxy"
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = 10 * time.Second
+	}
+	if cfg.Transport == nil {
+		cfg.Transport = &http.Transport{
+			Proxy:               nil, // сами не ходим через другой прокси
+			DialContext:         (&net.Dialer{Timeout: cfg.DialTimeout}).DialContext,
+			MaxIdleConnsPerHost: 10,
+		}
+	}
+
+	return &Proxy{cfg: cfg}
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !p.authorized(r) {
+		w.Header().Set("Proxy-Authenticate", `Basic realm="`+p.cfg.Realm+`"`)
+		http.Error(w, "Proxy authentication required", http.StatusProxyAuthRequired)
+		return
+	}
+
+	host := r.URL.Hostname()
+	if r.Method == http.MethodConnect {
+		host, _, _ = net.SplitHostPort(r.Host)
+	}
+
+	if host == "" {
+		http.Error(w, "Absolute URL or CONNECT expected", http.StatusBadRequest)
+		return
+	}
+
+	if !p.allowed(host) {
+		http.Error(w, "Host is not allowed", http.StatusForbidden)
+		return
+	}
+
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+
+	p.forward(w, r)
+}
+
+func (p *Proxy) authorized(r *http.Request) bool {
+	if len(p.cfg.Users) == 0 {
+		return true
+	}
+
+	user, pass, ok := parseProxyAuth(r.Header.Get("Proxy-Authorization"))
+	if !ok {
+		return false
+	}
+
+	expected, found := p.cfg.Users[user]
+	if !found {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(pass)) == 1
+}
+
+func parseProxyAuth(header string) (user, pass string, ok bool) {
+	const prefix = "Basic "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(header[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+
+	user, pass, ok = strings.Cut(string(decoded), ":")
+
+	return user, pass, ok
+}
+
+func (p *Proxy) allowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	for _, pattern := range p.cfg.Deny {
+		if matchHost(pattern, host) {
+			return false
+		}
+	}
+
+	if len(p.cfg.Allow) == 0 {
+		return true
+	}
+
+	for _, pattern := range p.cfg.Allow {
+		if matchHost(pattern, host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchHost(pattern, host string) bool {
+	pattern = strings.ToLower(pattern)
+
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+
+	return host == pattern
+}
+
+// hopHeaders — заголовки, которые относятся к одному соединению и не должны уходить дальше прокси
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, value := range h.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			h.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, name := range hopHeaders {
+		h.Del(name)
+	}
+}
+
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) {
+	out := r.Clone(r.Context())
+	out.RequestURI = "" // у клиентского запроса RequestURI должен быть пустым
+	removeHopHeaders(out.Header)
+
+	resp, err := p.cfg.Transport.RoundTrip(out)
+	if err != nil {
+		p.logf("forward %s: %v", r.URL, err)
+		http.Error(w, "Bad gateway", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	removeHopHeaders(resp.Header)
+	for name, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(name, v)
+		}
+	}
+
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	upstream, err := net.DialTimeout("tcp", r.Host, p.cfg.DialTimeout)
+	if err != nil {
+		p.logf("connect %s: %v", r.Host, err)
+		http.Error(w, "Bad gateway", http.StatusBadGateway)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "Hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		p.logf("hijack: %v", err)
+		return
+	}
+
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		client.Close()
+		upstream.Close()
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		// В буфере могли остаться байты, которые клиент успел прислать сразу после CONNECT
+		io.Copy(upstream, io.MultiReader(buffered.Reader, client))
+		closeWrite(upstream)
+	}()
+
+	go func() {
+		defer wg.Done()
+		io.Copy(client, upstream)
+		closeWrite(client)
+	}()
+
+	wg.Wait()
+	client.Close()
+	upstream.Close()
+}
+
+func closeWrite(c net.Conn) {
+	type closeWriter interface{ CloseWrite() error }
+
+	if cw, ok := c.(closeWriter); ok {
+		cw.CloseWrite()
+		return
+	}
+	if cc, ok := c.(*countingConn); ok {
+		closeWrite(cc.Conn)
+	}
+}
+
+func (p *Proxy) logf(format string, args ...any) {
+	if p.cfg.ErrorLog != nil {
+		p.cfg.ErrorLog.Printf(format, args...)
+	}
+}
@@ -0,0 +1,183 @@
+package ForwardProxy
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startProxy поднимает прокси на свободном порту и возвращает его адрес
+func startProxy(t *testing.T, cfg Config) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go New(cfg).Serve(l)
+	t.Cleanup(func() { l.Close() })
+
+	return l.Addr().String()
+}
+
+// client ходит через прокси addr; user — логин и пароль для Proxy-Authorization, пустой — без авторизации
+func client(addr string, user *url.Userinfo, tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(&url.URL{Scheme: "http", Host: addr, User: user}),
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: 5 * time.Second,
+	}
+}
+
+func get(t *testing.T, c *http.Client, target string) (*http.Response, string) {
+	t.Helper()
+
+	resp, err := c.Get(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	return resp, string(body)
+}
+
+func TestForwardHTTP(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// До апстрима не должны доходить hop-by-hop заголовки прокси
+		if r.Header.Get("Proxy-Authorization") != "" {
+			http.Error(w, "Proxy-Authorization leaked", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("X-Origin", "yes")
+		fmt.Fprint(w, "hello ", r.URL.Path)
+	}))
+	defer origin.Close()
+
+	addr := startProxy(t, Config{Users: map[string]string{"tester": "secret"}})
+
+	resp, body := get(t, client(addr, url.UserPassword("tester", "secret"), nil), origin.URL+"/path")
+	if resp.StatusCode != http.StatusOK || body != "hello /path" {
+		t.Fatalf("%d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("X-Origin") != "yes" {
+		t.Error("заголовок ответа апстрима потерян")
+	}
+}
+
+func TestConnectTunnel(t *testing.T) {
+	origin := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "secure")
+	}))
+	defer origin.Close()
+
+	stats := make(chan ConnStats, 4)
+	addr := startProxy(t, Config{
+		Users:       map[string]string{"tester": "secret"},
+		OnConnClose: func(s ConnStats) { stats <- s },
+	})
+
+	c := client(addr, url.UserPassword("tester", "secret"), origin.Client().Transport.(*http.Transport).TLSClientConfig)
+	resp, body := get(t, c, origin.URL)
+	if resp.StatusCode != http.StatusOK || body != "secure" || resp.TLS == nil {
+		t.Fatalf("%d %q, TLS: %v", resp.StatusCode, body, resp.TLS != nil)
+	}
+
+	// Туннель закрывается вместе с клиентским соединением — тогда и приходит статистика
+	c.CloseIdleConnections()
+	select {
+	case s := <-stats:
+		if s.BytesIn == 0 || s.BytesOut == 0 {
+			t.Errorf("статистика туннеля без трафика: %+v", s)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnConnClose не вызван")
+	}
+}
+
+func TestProxyAuth(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer origin.Close()
+
+	addr := startProxy(t, Config{Users: map[string]string{"tester": "secret"}, Realm: "test"})
+
+	for _, user := range []*url.Userinfo{nil, url.UserPassword("tester", "wrong"), url.UserPassword("nobody", "secret")} {
+		resp, _ := get(t, client(addr, user, nil), origin.URL)
+		if resp.StatusCode != http.StatusProxyAuthRequired {
+			t.Errorf("%v: статус %d, ожидался 407", user, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Proxy-Authenticate"); got != `Basic realm="test"` {
+			t.Errorf("%v: Proxy-Authenticate %q", user, got)
+		}
+	}
+
+	// CONNECT без авторизации: клиент получает 407 вместо туннеля
+	tlsOrigin := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsOrigin.Close()
+	if _, err := client(addr, nil, nil).Get(tlsOrigin.URL); err == nil || !strings.Contains(err.Error(), "Proxy Authentication Required") {
+		t.Errorf("CONNECT без авторизации: %v", err)
+	}
+}
+
+// roundTripFunc — транспорт-заглушка: разрешённые хосты не резолвятся и не требуют сети
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestAllowDeny(t *testing.T) {
+	var forwarded []string
+	addr := startProxy(t, Config{
+		Allow: []string{"api.example.com", "*.shop.example.com"},
+		Deny:  []string{"admin.shop.example.com"},
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			forwarded = append(forwarded, r.URL.Host)
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: http.Header{}, Request: r}, nil
+		}),
+	})
+	c := client(addr, nil, nil)
+
+	for target, want := range map[string]int{
+		"http://api.example.com/":         http.StatusOK,
+		"http://API.example.com./":        http.StatusOK,
+		"http://www.shop.example.com/":    http.StatusOK,
+		"http://admin.shop.example.com/":  http.StatusForbidden, // Deny сильнее Allow
+		"http://shop.example.com/":        http.StatusForbidden, // *. — только поддомены
+		"http://other.org/":               http.StatusForbidden,
+		"http://evil-api.example.com.io/": http.StatusForbidden,
+	} {
+		if resp, _ := get(t, c, target); resp.StatusCode != want {
+			t.Errorf("%s: %d, ожидался %d", target, resp.StatusCode, want)
+		}
+	}
+	if len(forwarded) != 3 {
+		t.Errorf("до транспорта дошли %v, ожидалось 3 разрешённых запроса", forwarded)
+	}
+
+	// CONNECT к запрещённому хосту отвергается до попытки соединения
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprint(conn, "CONNECT admin.shop.example.com:443 HTTP/1.1\r\nHost: admin.shop.example.com:443\r\n\r\n")
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("CONNECT к запрещённому хосту: %d, ожидался 403", resp.StatusCode)
+	}
+}
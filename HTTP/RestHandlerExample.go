@@ -2,8 +2,11 @@ package HTTP
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
+
+	"learning/Config"
 )
 
 type User struct {
@@ -12,14 +15,99 @@ type User struct {
 	Email string `json:"email"`
 }
 
+// users и lastUserID читают и меняют одновременно хэндлеры /api/v1/users и админка (ExampleAdminServer вешает их на один mux),
+// поэтому доступ к ним — только под usersMu
+var usersMu sync.RWMutex
+
 var users = []User{
 	{ID: "1", Name: "John Doe", Email: "john@example.com"},
 	{ID: "2", Name: "Alice Johnson", Email: "alice@example.com"},
 }
 
+// lastUserID — последний выданный id. Он только растёт: после удаления пользователя (в админке или DELETE)
+// len(users)+1 выдал бы id, который уже занят, и новый пользователь затёр бы существующего
+var lastUserID = maxUserID(users)
+
+func maxUserID(list []User) int {
+	last := 0
+	for _, u := range list {
+		if id, err := strconv.Atoi(u.ID); err == nil && id > last {
+			last = id
+		}
+	}
+
+	return last
+}
+
+// newUserID вызывается под usersMu.Lock
+func newUserID() string {
+	lastUserID++
+	return strconv.Itoa(lastUserID)
+}
+
+// Доступ к тем же данным, что и у хэндлеров /api/v1/users, из других пакетов (например, из админки)
+
+func AllUsers() []User {
+	usersMu.RLock()
+	defer usersMu.RUnlock()
+
+	return append([]User(nil), users...)
+}
+
+func UserByID(id string) (User, bool) {
+	usersMu.RLock()
+	defer usersMu.RUnlock()
+
+	for _, u := range users {
+		if u.ID == id {
+			return u, true
+		}
+	}
+
+	return User{}, false
+}
+
+func AddUser(u User) User {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+
+	u.ID = newUserID()
+	users = append(users, u)
+
+	return u
+}
+
+func ReplaceUser(u User) bool {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+
+	for i := range users {
+		if users[i].ID == u.ID {
+			users[i] = u
+			return true
+		}
+	}
+
+	return false
+}
+
+func RemoveUser(id string) bool {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+
+	for i, u := range users {
+		if u.ID == id {
+			users = append(users[:i], users[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
 func GetUsers(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(users)
+	json.NewEncoder(w).Encode(AllUsers())
 }
 
 func CreateUser(w http.ResponseWriter, r *http.Request) {
@@ -31,11 +119,8 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Генерация ID для нового пользователя (просто увеличение на 1)
-	newUser.ID = fmt.Sprint(len(users) + 1)
-
-	// Добавление нового пользователя в список
-	users = append(users, newUser)
+	// Добавление нового пользователя в список с ID, следующим за последним выданным
+	newUser = AddUser(newUser)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -45,26 +130,27 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 func UpdateUser(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Path[len("/api/v1/users/"):] // например, "2"
 
-	for i, u := range users {
-		if u.ID == id {
-			var updatedUser User
-			err := json.NewDecoder(r.Body).Decode(&updatedUser)
-			if err != nil {
-				http.Error(w, "Invalid user data", http.StatusBadRequest)
-				return
-			}
-
-			// сохраняем прежний ID, чтобы не потерять его
-			updatedUser.ID = id
-			users[i] = updatedUser
-
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(updatedUser)
-			return
-		}
+	if _, ok := UserByID(id); !ok {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	var updatedUser User
+	err := json.NewDecoder(r.Body).Decode(&updatedUser)
+	if err != nil {
+		http.Error(w, "Invalid user data", http.StatusBadRequest)
+		return
+	}
+
+	// сохраняем прежний ID, чтобы не потерять его; пока читали тело, пользователя могли удалить
+	updatedUser.ID = id
+	if !ReplaceUser(updatedUser) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
 	}
 
-	http.Error(w, "User not found", http.StatusNotFound)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updatedUser)
 }
 
 func DeleteUser(w http.ResponseWriter, r *http.Request) {
@@ -72,21 +158,25 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Path[len("/api/v1/users/"):]
 
 	// Удаление пользователя по ID
-	for i, u := range users {
-		if u.ID == id {
-			// Удаление элемента из среза
-			users = append(users[:i], users[i+1:]...)
-
-			w.WriteHeader(http.StatusNoContent)
-
-			return
-		}
+	if !RemoveUser(id) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
 	}
 
-	http.Error(w, "User not found", http.StatusNotFound)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func ProductionServer() {
+	http.ListenAndServe(Config.Current().HTTP.Addr, UsersMux())
+}
+
+// ProductionServerOn запускает тот же API на произвольном адресе, чтобы можно было поднять несколько экземпляров рядом (например, за балансировщиком)
+func ProductionServerOn(addr string) error {
+	return http.ListenAndServe(addr, UsersMux())
+}
+
+// UsersMux собирает маршрутизатор /api/v1/users, общий для всех вариантов запуска сервера
+func UsersMux() *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// Создаем эндпоинт для получения и создания пользователей.
@@ -113,128 +203,8 @@ func ProductionServer() {
 		}
 	})
 
-	http.ListenAndServe(":8383", mux)
-}
-
-/*
-func TestCreateUser(t *testing.T) {
-	// Создание тестового сервера
-	server := httptest.NewServer(http.HandlerFunc(CreateUser))
-	defer server.Close()
-
-	// Тестовый JSON для создания пользователя
-	newUser := User{Name: "New User", Email: "new@example.com"}
-	newUserJSON, _ := json.Marshal(newUser)
-
-	// Создание POST-запроса
-	req, err := http.NewRequest("POST", server.URL, bytes.NewBuffer(newUserJSON))
-	if err != nil {
-		t.Fatal(err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// Отправка POST-запроса
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer resp.Body.Close()
-
-	// Проверка статус кода
-	if resp.StatusCode != http.StatusCreated {
-		t.Errorf("Expected status 201, got %d", resp.StatusCode)
-	}
-
-	// Проверка, что новый пользователь был создан
-	var createdUser User
-	err = json.NewDecoder(resp.Body).Decode(&createdUser)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	if createdUser.ID == "" {
-		t.Error("Expected a valid ID for the created user")
-	}
-
-	// Дополнительные проверки, например, можно проверить, что созданный пользователь соответствует ожиданиям
-}
-
-func TestUpdateUser(t *testing.T) {
-	// Создание тестового сервера
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPut {
-			UpdateUser(w, r)
-		}
-	}))
-	defer server.Close()
-
-	// Тестовый JSON для обновления пользователя
-	updatedUser := User{ID: "1", Name: "Updated User", Email: "updated@example.com"}
-	updatedUserJSON, _ := json.Marshal(updatedUser)
-
-	// Создание PUT-запроса
-	req, err := http.NewRequest(http.MethodPut, server.URL+"/api/users/1", bytes.NewBuffer(updatedUserJSON))
-	if err != nil {
-		t.Fatal(err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// Отправка PUT-запроса
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer resp.Body.Close()
-
-	// Проверка статус кода
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("Expected status 200, got %d", resp.StatusCode)
-	}
-
-	// Проверка, что пользователь был обновлен
-	var updatedUserResponse User
-	err = json.NewDecoder(resp.Body).Decode(&updatedUserResponse)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	if updatedUserResponse.ID != "1" || updatedUserResponse.Name != "Updated User" ||
-		updatedUserResponse.Email != "updated@example.com" {
-		t.Error("User update response does not match expectations")
-	}
+	return mux
 }
 
-func TestDeleteUser(t *testing.T) {
-	// Создание тестового сервера
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodDelete {
-			DeleteUser(w, r)
-		}
-	}))
-	defer server.Close()
-
-	// Создание DELETE-запроса
-	req, err := http.NewRequest("DELETE", server.URL+"/api/users/1", nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	// Отправка DELETE-запроса
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer resp.Body.Close()
-
-	// Проверка статус кода
-	if resp.StatusCode != http.StatusNoContent {
-		t.Errorf("Expected status 204, got %d", resp.StatusCode)
-	}
-
-	// Проверка, что пользователь с ID "1" был удален
-	// Для более сложных проверок, можно предварительно создать пользователя с ID "1" и потом попытаться получить его, чтобы убедиться, что его больше нет
-}
-*/
+// Проверки CRUD этого API — RestHandlerExample_test.go и HTTP/Testing: UsersContract (построитель запросов)
+// и testdata/users_crud.json (сценарий), их запускает users_contract_test.go
@@ -0,0 +1,180 @@
+package HTTP
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// withUsers возвращает список users к исходному после теста: хэндлеры работают с общим срезом
+func withUsers(t *testing.T) {
+	usersMu.RLock()
+	saved, last := append([]User(nil), users...), lastUserID
+	usersMu.RUnlock()
+
+	t.Cleanup(func() {
+		usersMu.Lock()
+		users, lastUserID = saved, last
+		usersMu.Unlock()
+	})
+}
+
+func TestCreateUser(t *testing.T) {
+	withUsers(t)
+
+	// Создание тестового сервера
+	server := httptest.NewServer(UsersMux())
+	defer server.Close()
+
+	// Тестовый JSON для создания пользователя
+	newUser := User{Name: "New User", Email: "new@example.com"}
+	newUserJSON, _ := json.Marshal(newUser)
+
+	// Отправка POST-запроса
+	resp, err := http.Post(server.URL+"/api/v1/users", "application/json", bytes.NewBuffer(newUserJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// Проверка статус кода
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", resp.StatusCode)
+	}
+
+	// Проверка, что новый пользователь был создан
+	var createdUser User
+	if err := json.NewDecoder(resp.Body).Decode(&createdUser); err != nil {
+		t.Fatal(err)
+	}
+
+	if createdUser.ID == "" {
+		t.Error("Expected a valid ID for the created user")
+	}
+	if createdUser.Name != newUser.Name || createdUser.Email != newUser.Email {
+		t.Errorf("Created user %+v does not match %+v", createdUser, newUser)
+	}
+	if _, ok := UserByID(createdUser.ID); !ok {
+		t.Errorf("User %s is not in the list after create", createdUser.ID)
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	withUsers(t)
+
+	server := httptest.NewServer(UsersMux())
+	defer server.Close()
+
+	// ID в теле игнорируется — берётся из пути
+	updatedUser := User{ID: "999", Name: "Updated User", Email: "updated@example.com"}
+	updatedUserJSON, _ := json.Marshal(updatedUser)
+
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/api/v1/users/1", bytes.NewBuffer(updatedUserJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	// Проверка, что пользователь был обновлен
+	var updatedUserResponse User
+	if err := json.NewDecoder(resp.Body).Decode(&updatedUserResponse); err != nil {
+		t.Fatal(err)
+	}
+
+	if updatedUserResponse.ID != "1" || updatedUserResponse.Name != "Updated User" ||
+		updatedUserResponse.Email != "updated@example.com" {
+		t.Errorf("User update response does not match expectations: %+v", updatedUserResponse)
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	withUsers(t)
+
+	server := httptest.NewServer(UsersMux())
+	defer server.Close()
+
+	// Предварительно создаём пользователя, чтобы удалить именно его
+	created := AddUser(User{Name: "To Delete", Email: "delete@example.com"})
+
+	del := func() int {
+		req, err := http.NewRequest(http.MethodDelete, server.URL+"/api/v1/users/"+created.ID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := del(); code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", code)
+	}
+	if _, ok := UserByID(created.ID); ok {
+		t.Errorf("User %s is still in the list after delete", created.ID)
+	}
+	if code := del(); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 on second delete, got %d", code)
+	}
+
+	// id удалённого пользователя новому не достаётся
+	if next := AddUser(User{Name: "Next"}); next.ID == created.ID {
+		t.Errorf("Deleted ID %s was handed out again", created.ID)
+	}
+}
+
+// API и админка меняют users одновременно: под -race гонок быть не должно, а id — не повторяться
+func TestConcurrentUsers(t *testing.T) {
+	withUsers(t)
+
+	server := httptest.NewServer(UsersMux())
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	ids := make(chan string, 40)
+	for range 20 {
+		wg.Go(func() {
+			resp, err := http.Post(server.URL+"/api/v1/users", "application/json", bytes.NewBufferString(`{"name": "API"}`))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+
+			var u User
+			json.NewDecoder(resp.Body).Decode(&u)
+			ids <- u.ID
+		})
+		wg.Go(func() {
+			u := AddUser(User{Name: "Admin"})
+			ReplaceUser(User{ID: u.ID, Name: "Admin edited"})
+			AllUsers()
+			ids <- u.ID
+		})
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("id %s выдан дважды", id)
+		}
+		seen[id] = true
+	}
+}
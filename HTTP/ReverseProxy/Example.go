@@ -0,0 +1,45 @@
+package ReverseProxy
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	settings "learning/Config"
+	"learning/HTTP"
+)
+
+// ExampleLocalCluster поднимает три экземпляра ProductionServer и балансировщик перед ними на адресе из Config (по умолчанию :8080)
+func ExampleLocalCluster() {
+	addrs := []string{":9001", ":9002", ":9003"}
+
+	for _, addr := range addrs {
+		go func(addr string) {
+			log.Println(HTTP.ProductionServerOn(addr))
+		}(addr)
+	}
+
+	lb, err := New(Config{
+		Upstreams: []string{"http://127.0.0.1:9001", "http://127.0.0.1:9002", "http://127.0.0.1:9003"},
+		Strategy:  "least-conn",
+		HealthCheck: HealthCheck{
+			Path:     "/api/v1/users",
+			Interval: 5 * time.Second,
+		},
+		MaxFails:      3,
+		EjectDuration: 15 * time.Second,
+		Retries:       2,
+		Headers: HeaderRules{
+			SetRequest:     map[string]string{"X-Proxy": "learning-lb"},
+			RemoveResponse: []string{"Server"},
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lb.StartHealthChecks(context.Background())
+
+	log.Fatal(http.ListenAndServe(settings.Current().HTTP.ProxyAddr, lb))
+}
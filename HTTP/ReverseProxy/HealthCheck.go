@@ -0,0 +1,69 @@
+package ReverseProxy
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+/*
+Активная проверка здоровья: раз в Interval балансировщик делает GET на Path каждого апстрима.
+Ответ 2xx/3xx — апстрим здоров, любая ошибка или другой статус — апстрим выводится из ротации до следующей успешной проверки.
+*/
+
+type HealthCheck struct {
+	Path     string // например, "/api/v1/users"; пустой путь отключает активные проверки
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// StartHealthChecks запускает проверки в отдельной горутине, остановить их можно отменой контекста
+func (lb *LoadBalancer) StartHealthChecks(ctx context.Context) {
+	hc := lb.cfg.HealthCheck
+	if hc.Path == "" {
+		return
+	}
+	if hc.Interval == 0 {
+		hc.Interval = 10 * time.Second
+	}
+	if hc.Timeout == 0 {
+		hc.Timeout = 2 * time.Second
+	}
+
+	client := &http.Client{Transport: lb.cfg.Transport, Timeout: hc.Timeout}
+
+	go func() {
+		ticker := time.NewTicker(hc.Interval)
+		defer ticker.Stop()
+
+		for {
+			for _, up := range lb.upstreams {
+				up.setHealthy(probe(ctx, client, up, hc.Path))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func probe(ctx context.Context, client *http.Client, up *Upstream, path string) bool {
+	target := *up.URL
+	target.Path = singleJoiningSlash(up.URL.Path, path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode >= 200 && resp.StatusCode < 400
+}
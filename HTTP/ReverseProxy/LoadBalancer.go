@@ -0,0 +1,308 @@
+package ReverseProxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/*
+L7-балансировщик на базе httputil.ReverseProxy.
+
+httputil.ReverseProxy сам умеет копировать запрос, убирать hop-by-hop заголовки, стримить тело ответа и т.д.
+От нас требуется:
+	Rewrite — как переписать исходящий запрос (здесь добавляем X-Forwarded-* и правила заголовков);
+	Transport — кто реально выполняет запрос. Балансировщик сам является http.RoundTripper:
+		выбирает апстрим стратегией, отправляет запрос и при ошибке идемпотентного запроса повторяет его на другом апстриме;
+	ModifyResponse — правка заголовков ответа;
+	ErrorHandler — что отдать клиенту, если не удалось ни одной попытки (502).
+
+Пассивное исключение: транспортные ошибки и ответы 502/503/504 считаются неудачами апстрима.
+*/
+
+var ErrNoUpstream = errors.New("нет доступных апстримов")
+
+// HeaderRules — переписывание заголовков запроса к апстриму и ответа клиенту
+type HeaderRules struct {
+	SetRequest     map[string]string
+	RemoveRequest  []string
+	SetResponse    map[string]string
+	RemoveResponse []string
+}
+
+type Config struct {
+	Upstreams []string
+	Strategy  string // "round-robin" (по умолчанию), "least-conn", "consistent-hash"
+
+	HashHeader string // ключ для consistent-hash, по умолчанию IP клиента
+
+	HealthCheck HealthCheck
+
+	MaxFails      int           // сколько ошибок подряд до исключения апстрима, 0 — не исключать
+	EjectDuration time.Duration // на сколько исключаем апстрим
+
+	Retries      int   // сколько раз повторять идемпотентный запрос на другом апстриме
+	MaxRetryBody int64 // тело больше этого размера не буферизуется и запрос не повторяется
+
+	Headers HeaderRules
+
+	Transport http.RoundTripper // транспорт до апстримов, по умолчанию http.DefaultTransport
+	ErrorLog  *log.Logger
+}
+
+type LoadBalancer struct {
+	cfg       Config
+	upstreams []*Upstream
+	strategy  Strategy
+	proxy     *httputil.ReverseProxy
+}
+
+func New(cfg Config) (*LoadBalancer, error) {
+	if len(cfg.Upstreams) == 0 {
+		return nil, errors.New("пустой список апстримов")
+	}
+
+	if cfg.Transport == nil {
+		cfg.Transport = http.DefaultTransport
+	}
+	if cfg.EjectDuration == 0 {
+		cfg.EjectDuration = 30 * time.Second
+	}
+	if cfg.MaxRetryBody == 0 {
+		cfg.MaxRetryBody = 1 << 20
+	}
+
+	lb := &LoadBalancer{cfg: cfg}
+
+	for _, raw := range cfg.Upstreams {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("апстрим %q: %w", raw, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("апстрим %q: нужен абсолютный URL", raw)
+		}
+		lb.upstreams = append(lb.upstreams, newUpstream(u))
+	}
+
+	switch cfg.Strategy {
+	case "", "round-robin":
+		lb.strategy = &RoundRobin{}
+	case "least-conn":
+		lb.strategy = LeastConnections{}
+	case "consistent-hash":
+		lb.strategy = NewConsistentHash(lb.upstreams, 0, cfg.HashHeader)
+	default:
+		return nil, fmt.Errorf("неизвестная стратегия %q", cfg.Strategy)
+	}
+
+	lb.proxy = &httputil.ReverseProxy{
+		Rewrite:        lb.rewrite,
+		Transport:      lb,
+		ModifyResponse: lb.modifyResponse,
+		ErrorHandler:   lb.errorHandler,
+		ErrorLog:       cfg.ErrorLog,
+	}
+
+	return lb, nil
+}
+
+// Upstreams возвращает пул, например для вывода состояния
+func (lb *LoadBalancer) Upstreams() []*Upstream {
+	return lb.upstreams
+}
+
+func (lb *LoadBalancer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Для повтора тело запроса нужно прочитать ещё раз, поэтому небольшие тела идемпотентных запросов буферизуем
+	if lb.cfg.Retries > 0 && isIdempotent(r.Method) && r.Body != nil && r.Body != http.NoBody && r.GetBody == nil {
+		if r.ContentLength >= 0 && r.ContentLength <= lb.cfg.MaxRetryBody {
+			body, err := io.ReadAll(io.LimitReader(r.Body, lb.cfg.MaxRetryBody+1))
+			r.Body.Close()
+			if err != nil {
+				http.Error(w, "Bad request body", http.StatusBadRequest)
+				return
+			}
+			if int64(len(body)) <= lb.cfg.MaxRetryBody {
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+			} else {
+				r.Body = io.NopCloser(bytes.NewReader(body)) // слишком большое тело отправим один раз
+			}
+		}
+	}
+
+	lb.proxy.ServeHTTP(w, r)
+}
+
+func (lb *LoadBalancer) rewrite(pr *httputil.ProxyRequest) {
+	pr.SetXForwarded()
+
+	for _, name := range lb.cfg.Headers.RemoveRequest {
+		pr.Out.Header.Del(name)
+	}
+	for name, value := range lb.cfg.Headers.SetRequest {
+		pr.Out.Header.Set(name, value)
+	}
+}
+
+func (lb *LoadBalancer) modifyResponse(resp *http.Response) error {
+	for _, name := range lb.cfg.Headers.RemoveResponse {
+		resp.Header.Del(name)
+	}
+	for name, value := range lb.cfg.Headers.SetResponse {
+		resp.Header.Set(name, value)
+	}
+
+	return nil
+}
+
+func (lb *LoadBalancer) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	if lb.cfg.ErrorLog != nil {
+		lb.cfg.ErrorLog.Printf("proxy %s %s: %v", r.Method, r.URL.Path, err)
+	}
+
+	status := http.StatusBadGateway
+	if errors.Is(err, ErrNoUpstream) {
+		status = http.StatusServiceUnavailable
+	}
+
+	http.Error(w, http.StatusText(status), status)
+}
+
+// RoundTrip выбирает апстрим и выполняет запрос, повторяя идемпотентные запросы на других апстримах
+func (lb *LoadBalancer) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if isIdempotent(req.Method) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil) {
+		attempts += lb.cfg.Retries
+	}
+
+	tried := make(map[*Upstream]bool)
+	var lastErr error = ErrNoUpstream
+
+	for i := 0; i < attempts; i++ {
+		up := lb.strategy.Next(lb.upstreams, req, tried)
+		if up == nil {
+			break
+		}
+		tried[up] = true
+
+		out := req.Clone(req.Context())
+		if i > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			out.Body = body
+		}
+		out.URL.Scheme = up.URL.Scheme
+		out.URL.Host = up.URL.Host
+		out.URL.Path, out.URL.RawPath = joinURLPath(up.URL, req.URL)
+
+		up.acquire()
+		resp, err := lb.cfg.Transport.RoundTrip(out)
+		if err != nil {
+			up.release()
+			up.markFailure(lb.cfg.MaxFails, lb.cfg.EjectDuration)
+			lastErr = fmt.Errorf("%s: %w", up.URL.Host, err)
+			continue
+		}
+
+		if isUpstreamFailure(resp.StatusCode) {
+			up.markFailure(lb.cfg.MaxFails, lb.cfg.EjectDuration)
+			if i < attempts-1 {
+				resp.Body.Close()
+				up.release()
+				lastErr = fmt.Errorf("%s: %s", up.URL.Host, resp.Status)
+				continue
+			}
+		} else {
+			up.markSuccess()
+		}
+
+		// Соединение считается активным, пока клиент не дочитал тело ответа
+		body := &releaseOnClose{ReadCloser: resp.Body, up: up}
+		resp.Body = body
+		if rw, ok := body.ReadCloser.(io.ReadWriteCloser); ok && resp.StatusCode == http.StatusSwitchingProtocols {
+			// После 101 тело — само соединение: ReverseProxy ищет у него Write, без него websocket не поднимется
+			resp.Body = &upgradedBody{releaseOnClose: body, Writer: rw}
+		}
+
+		return resp, nil
+	}
+
+	return nil, lastErr
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	up   *Upstream
+	done bool
+}
+
+func (rc *releaseOnClose) Close() error {
+	if !rc.done {
+		rc.done = true
+		rc.up.release()
+	}
+
+	return rc.ReadCloser.Close()
+}
+
+// upgradedBody — releaseOnClose, который сохраняет Write у тела ответа 101 Switching Protocols
+type upgradedBody struct {
+	*releaseOnClose
+	io.Writer
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+func isUpstreamFailure(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+// joinURLPath склеивает путь апстрима (например, /backend) и путь запроса, как это делает NewSingleHostReverseProxy
+func joinURLPath(base, req *url.URL) (path, rawpath string) {
+	if base.RawPath == "" && req.RawPath == "" {
+		return singleJoiningSlash(base.Path, req.Path), ""
+	}
+
+	apath := base.EscapedPath()
+	bpath := req.EscapedPath()
+
+	switch aslash, bslash := strings.HasSuffix(apath, "/"), strings.HasPrefix(bpath, "/"); {
+	case aslash && bslash:
+		return base.Path + req.Path[1:], apath + bpath[1:]
+	case !aslash && !bslash:
+		return base.Path + "/" + req.Path, apath + "/" + bpath
+	}
+
+	return base.Path + req.Path, apath + bpath
+}
+
+func singleJoiningSlash(a, b string) string {
+	aslash := strings.HasSuffix(a, "/")
+	bslash := strings.HasPrefix(b, "/")
+
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+
+	return a + b
+}
@@ -0,0 +1,227 @@
+package ReverseProxy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// backend — апстрим, который отвечает своим именем и считает запросы; status != 0 — отвечать этим статусом
+type backend struct {
+	name   string
+	status int
+	hits   atomic.Int64
+	server *httptest.Server
+}
+
+func newBackend(t *testing.T, name string, status int) *backend {
+	b := &backend{name: name, status: status}
+	b.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.hits.Add(1)
+		if b.status != 0 {
+			w.WriteHeader(b.status)
+		}
+		io.WriteString(w, b.name)
+	}))
+	t.Cleanup(b.server.Close)
+
+	return b
+}
+
+func newBalancer(t *testing.T, cfg Config, backends ...*backend) *httptest.Server {
+	for _, b := range backends {
+		cfg.Upstreams = append(cfg.Upstreams, b.server.URL)
+	}
+
+	lb, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(lb)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func send(t *testing.T, method, url string, header http.Header) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	return resp.StatusCode, string(body)
+}
+
+func TestRetryIdempotentOnAnotherUpstream(t *testing.T) {
+	bad := newBackend(t, "bad", http.StatusServiceUnavailable)
+	good := newBackend(t, "good", 0)
+	server := newBalancer(t, Config{Retries: 1}, bad, good)
+
+	// Round-robin начинает с первого апстрима: GET уходит на bad, получает 503 и повторяется на good
+	if status, body := send(t, http.MethodGet, server.URL, nil); status != http.StatusOK || body != "good" {
+		t.Fatalf("GET: %d %q, ожидался 200 good", status, body)
+	}
+	if bad.hits.Load() != 1 || good.hits.Load() != 1 {
+		t.Fatalf("попаданий: bad %d, good %d", bad.hits.Load(), good.hits.Load())
+	}
+
+	// POST не идемпотентен и не повторяется: попавшие на bad получают его 503
+	bad.hits.Store(0)
+	good.hits.Store(0)
+	failed := 0
+	for range 4 {
+		if status, _ := send(t, http.MethodPost, server.URL, nil); status == http.StatusServiceUnavailable {
+			failed++
+		}
+	}
+	if failed != 2 || bad.hits.Load() != 2 || good.hits.Load() != 2 {
+		t.Fatalf("POST: %d ответов 503, bad %d, good %d — ожидалось по 2", failed, bad.hits.Load(), good.hits.Load())
+	}
+}
+
+func TestEjectAfterMaxFails(t *testing.T) {
+	bad := newBackend(t, "bad", http.StatusBadGateway)
+	good := newBackend(t, "good", 0)
+	server := newBalancer(t, Config{MaxFails: 2, EjectDuration: time.Minute}, bad, good)
+
+	for range 10 {
+		send(t, http.MethodGet, server.URL, nil)
+	}
+
+	// bad получил два запроса подряд по кругу, после второй ошибки исключён, остальные ушли на good
+	if bad.hits.Load() != 2 {
+		t.Fatalf("bad получил %d запросов, ожидалось 2", bad.hits.Load())
+	}
+	if good.hits.Load() != 8 {
+		t.Fatalf("good получил %d запросов, ожидалось 8", good.hits.Load())
+	}
+}
+
+func TestConsistentHash(t *testing.T) {
+	backends := []*backend{newBackend(t, "a", 0), newBackend(t, "b", 0), newBackend(t, "c", 0)}
+	server := newBalancer(t, Config{Strategy: "consistent-hash", HashHeader: "X-User", MaxFails: 1, EjectDuration: time.Minute, Retries: 1}, backends...)
+
+	route := func(key string) string {
+		status, body := send(t, http.MethodGet, server.URL, http.Header{"X-User": {key}})
+		if status != http.StatusOK {
+			t.Fatalf("%s: статус %d", key, status)
+		}
+		return body
+	}
+
+	before := make(map[string]string)
+	used := make(map[string]bool)
+	for i := range 50 {
+		key := fmt.Sprintf("user-%d", i)
+		before[key] = route(key)
+		used[before[key]] = true
+
+		if again := route(key); again != before[key] {
+			t.Fatalf("%s: %s, потом %s", key, before[key], again)
+		}
+	}
+	if len(used) < 2 {
+		t.Fatalf("все ключи попали на %v", used)
+	}
+
+	// Выключаем b: его ключи переезжают, остальные остаются на месте
+	backends[1].server.Close()
+	for key, was := range before {
+		now := route(key)
+		if was != "b" && now != was {
+			t.Errorf("%s переехал с %s на %s, хотя его апстрим жив", key, was, now)
+		}
+		if now == "b" {
+			t.Errorf("%s всё ещё на выключенном b", key)
+		}
+	}
+}
+
+// Ответ 101 проходит через балансировщик: после него клиент и апстрим обмениваются байтами напрямую
+func TestUpgrade(t *testing.T) {
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "echo" {
+			http.Error(w, "upgrade required", http.StatusUpgradeRequired)
+			return
+		}
+
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		rw.Flush()
+		io.Copy(conn, rw)
+	}))
+	defer echo.Close()
+
+	lb, err := New(Config{Upstreams: []string{echo.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(lb)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: example\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("статус %d, ожидался 101", resp.StatusCode)
+	}
+
+	fmt.Fprint(conn, "ping")
+	got := make([]byte, 4)
+	if _, err := io.ReadFull(br, got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "ping" {
+		t.Fatalf("эхо %q", got)
+	}
+
+	// Пока соединение открыто, апстрим занят; после закрытия — освобождается
+	if n := lb.Upstreams()[0].ActiveConns(); n != 1 {
+		t.Fatalf("активных соединений %d, ожидалось 1", n)
+	}
+	conn.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for lb.Upstreams()[0].ActiveConns() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("апстрим не освобождён после закрытия соединения")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,134 @@
+package ReverseProxy
+
+import (
+	"hash/crc32"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync/atomic"
+)
+
+/*
+Стратегии выбора апстрима.
+
+	RoundRobin — по кругу, каждый следующий запрос уходит на следующий доступный апстрим;
+	LeastConnections — на апстрим с наименьшим числом активных запросов;
+	ConsistentHash — по хешу ключа запроса (IP клиента или значение заголовка) на кольце с виртуальными узлами.
+		Один и тот же ключ всегда попадает на один и тот же апстрим, а при выпадении узла перераспределяется только его доля ключей.
+
+Стратегия получает список уже опробованных апстримов (skip), чтобы при повторе запрос ушёл на другой бэкенд.
+*/
+
+type Strategy interface {
+	Next(upstreams []*Upstream, r *http.Request, skip map[*Upstream]bool) *Upstream
+}
+
+func usable(up *Upstream, skip map[*Upstream]bool) bool {
+	return up.Available() && !skip[up]
+}
+
+type RoundRobin struct {
+	counter atomic.Uint64
+}
+
+func (rr *RoundRobin) Next(upstreams []*Upstream, _ *http.Request, skip map[*Upstream]bool) *Upstream {
+	n := len(upstreams)
+	if n == 0 {
+		return nil
+	}
+
+	start := rr.counter.Add(1) - 1
+	for i := 0; i < n; i++ {
+		up := upstreams[(start+uint64(i))%uint64(n)]
+		if usable(up, skip) {
+			return up
+		}
+	}
+
+	return nil
+}
+
+type LeastConnections struct{}
+
+func (LeastConnections) Next(upstreams []*Upstream, _ *http.Request, skip map[*Upstream]bool) *Upstream {
+	var best *Upstream
+
+	for _, up := range upstreams {
+		if !usable(up, skip) {
+			continue
+		}
+		if best == nil || up.ActiveConns() < best.ActiveConns() {
+			best = up
+		}
+	}
+
+	return best
+}
+
+// ConsistentHash — кольцо хешей. Replicas — число виртуальных узлов на апстрим, HashHeader — заголовок с ключом (по умолчанию IP клиента)
+type ConsistentHash struct {
+	Replicas   int
+	HashHeader string
+
+	ring  []uint32
+	nodes map[uint32]*Upstream
+}
+
+func NewConsistentHash(upstreams []*Upstream, replicas int, hashHeader string) *ConsistentHash {
+	if replicas <= 0 {
+		replicas = 100
+	}
+
+	ch := &ConsistentHash{
+		Replicas:   replicas,
+		HashHeader: hashHeader,
+		nodes:      make(map[uint32]*Upstream),
+	}
+
+	for _, up := range upstreams {
+		for i := 0; i < replicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(up.URL.String() + "#" + strconv.Itoa(i)))
+			ch.ring = append(ch.ring, h)
+			ch.nodes[h] = up
+		}
+	}
+
+	sort.Slice(ch.ring, func(i, j int) bool { return ch.ring[i] < ch.ring[j] })
+
+	return ch
+}
+
+func (ch *ConsistentHash) key(r *http.Request) string {
+	if ch.HashHeader != "" {
+		if v := r.Header.Get(ch.HashHeader); v != "" {
+			return v
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func (ch *ConsistentHash) Next(_ []*Upstream, r *http.Request, skip map[*Upstream]bool) *Upstream {
+	if len(ch.ring) == 0 {
+		return nil
+	}
+
+	h := crc32.ChecksumIEEE([]byte(ch.key(r)))
+	start := sort.Search(len(ch.ring), func(i int) bool { return ch.ring[i] >= h })
+
+	// Идём по кольцу по часовой стрелке, пока не найдём доступный узел
+	for i := 0; i < len(ch.ring); i++ {
+		up := ch.nodes[ch.ring[(start+i)%len(ch.ring)]]
+		if usable(up, skip) {
+			return up
+		}
+	}
+
+	return nil
+}
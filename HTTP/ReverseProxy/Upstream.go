@@ -0,0 +1,89 @@
+package ReverseProxy
+
+import (
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+Upstream — один бэкенд из пула балансировщика.
+
+Состояние апстрима складывается из двух независимых признаков:
+	healthy — результат активной проверки здоровья (периодический GET на HealthCheck.Path);
+	ejectedUntil — пассивное исключение: если подряд случилось MaxFails ошибок, апстрим убирается из ротации на EjectDuration.
+
+Апстрим доступен для новых запросов, только если он здоров и не исключён.
+*/
+
+type Upstream struct {
+	URL *url.URL
+
+	healthy     atomic.Bool
+	activeConns atomic.Int64
+
+	mu           sync.Mutex
+	fails        int
+	ejectedUntil time.Time
+}
+
+func newUpstream(u *url.URL) *Upstream {
+	up := &Upstream{URL: u}
+	up.healthy.Store(true) // до первой проверки считаем апстрим живым
+
+	return up
+}
+
+// Available сообщает, можно ли отправлять на апстрим новые запросы
+func (up *Upstream) Available() bool {
+	if !up.healthy.Load() {
+		return false
+	}
+
+	up.mu.Lock()
+	defer up.mu.Unlock()
+
+	return time.Now().After(up.ejectedUntil)
+}
+
+// Healthy возвращает результат последней активной проверки
+func (up *Upstream) Healthy() bool {
+	return up.healthy.Load()
+}
+
+// ActiveConns — количество запросов, которые прямо сейчас обслуживает апстрим
+func (up *Upstream) ActiveConns() int64 {
+	return up.activeConns.Load()
+}
+
+func (up *Upstream) acquire() {
+	up.activeConns.Add(1)
+}
+
+func (up *Upstream) release() {
+	up.activeConns.Add(-1)
+}
+
+// markFailure учитывает неудачный запрос и при достижении порога исключает апстрим
+func (up *Upstream) markFailure(maxFails int, ejectFor time.Duration) {
+	up.mu.Lock()
+	defer up.mu.Unlock()
+
+	up.fails++
+	if maxFails > 0 && up.fails >= maxFails {
+		up.ejectedUntil = time.Now().Add(ejectFor)
+		up.fails = 0
+	}
+}
+
+// markSuccess сбрасывает счётчик ошибок подряд
+func (up *Upstream) markSuccess() {
+	up.mu.Lock()
+	up.fails = 0
+	up.mu.Unlock()
+}
+
+func (up *Upstream) setHealthy(ok bool) {
+	up.healthy.Store(ok)
+}
@@ -0,0 +1,136 @@
+package Testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+/*
+Набор для контрактных тестов HTTP-обработчиков.
+
+Работает с любым http.Handler: запрос собирается построителем, выполняется через httptest.NewRecorder
+(без сети и без порта), а ответ проверяется цепочкой Expect*:
+
+	c := Testing.NewClient(t, HTTP.UsersMux())
+	c.Post("/api/v1/users").JSON(user).Do().
+		ExpectStatus(http.StatusCreated).
+		ExpectJSON("name", "New User")
+
+Первая неудачная проверка помечает тест упавшим (Errorf), остальные проверки продолжают выполняться,
+чтобы в выводе было видно все расхождения сразу. Fatalf — только когда дальше проверять нечего (тело не JSON и т.п.).
+*/
+
+// T — то, что нужно набору от *testing.T; подходит и testing.TB, и свой репортер для запуска вне go test
+type T interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+	Logf(format string, args ...any)
+}
+
+type Client struct {
+	t       T
+	handler http.Handler
+	headers http.Header // заголовки, которые добавляются к каждому запросу (например, Authorization)
+}
+
+func NewClient(t T, h http.Handler) *Client {
+	return &Client{t: t, handler: h, headers: make(http.Header)}
+}
+
+// WithHeader добавляет заголовок ко всем последующим запросам клиента
+func (c *Client) WithHeader(key, value string) *Client {
+	c.headers.Set(key, value)
+	return c
+}
+
+func (c *Client) Get(path string) *Request    { return c.Request(http.MethodGet, path) }
+func (c *Client) Post(path string) *Request   { return c.Request(http.MethodPost, path) }
+func (c *Client) Put(path string) *Request    { return c.Request(http.MethodPut, path) }
+func (c *Client) Patch(path string) *Request  { return c.Request(http.MethodPatch, path) }
+func (c *Client) Delete(path string) *Request { return c.Request(http.MethodDelete, path) }
+
+func (c *Client) Request(method, path string) *Request {
+	return &Request{client: c, method: method, path: path, header: c.headers.Clone(), query: url.Values{}}
+}
+
+// Request — построитель запроса
+type Request struct {
+	client *Client
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	body   []byte
+}
+
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// JSON сериализует v в тело и ставит Content-Type: application/json
+func (r *Request) JSON(v any) *Request {
+	r.client.t.Helper()
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		r.client.t.Fatalf("%s %s: не удалось сериализовать тело: %v", r.method, r.path, err)
+	}
+
+	r.body = body
+	r.header.Set("Content-Type", "application/json")
+
+	return r
+}
+
+// Body — тело как есть, например, заведомо битый JSON
+func (r *Request) Body(body string) *Request {
+	r.body = []byte(body)
+	return r
+}
+
+func (r *Request) Do() *Response {
+	r.client.t.Helper()
+
+	target := r.path
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+
+	req := httptest.NewRequest(r.method, target, body)
+	req.Header = r.header.Clone()
+
+	rec := httptest.NewRecorder()
+	r.client.handler.ServeHTTP(rec, req)
+
+	resp := rec.Result()
+	data, _ := io.ReadAll(resp.Body)
+
+	return &Response{
+		t:          r.client.t,
+		name:       r.method + " " + target,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       data,
+	}
+}
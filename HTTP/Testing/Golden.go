@@ -0,0 +1,77 @@
+package Testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+/*
+Golden-файлы — эталонные снимки ответа, которые хранятся рядом с тестами в testdata/golden.
+
+	UPDATE_GOLDEN=1 go test ./...   — перезаписать снимки текущими ответами (потом посмотреть git diff!)
+	go test ./...                   — сравнить ответы со снимками
+
+Перезапись включается переменной окружения, а не флагом -update: флаг из библиотечного пакета попал бы в flag.CommandLine
+каждой программы, которая импортирует Testing, а тест со своим -update упал бы с "flag redefined".
+
+JSON перед сравнением форматируется с отступами и сортировкой ключей, так что порядок полей и пробелы не важны,
+а diff в git получается читаемым.
+*/
+
+// GoldenDir — каталог снимков для MatchGolden
+var GoldenDir = filepath.Join("testdata", "golden")
+
+// UpdateGolden сообщает, включена ли перезапись: переменная окружения UPDATE_GOLDEN=1
+func UpdateGolden() bool {
+	return os.Getenv("UPDATE_GOLDEN") == "1"
+}
+
+// MatchGolden сравнивает тело с GoldenDir/name.golden
+func (r *Response) MatchGolden(name string) *Response {
+	r.t.Helper()
+
+	return r.matchGoldenFile(filepath.Join(GoldenDir, name+".golden"))
+}
+
+func (r *Response) matchGoldenFile(path string) *Response {
+	r.t.Helper()
+
+	got := canonical(r.Body)
+
+	if UpdateGolden() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatalf("golden %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			r.t.Fatalf("golden %s: %v", path, err)
+		}
+		r.t.Logf("golden %s обновлён", path)
+		return r
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		r.t.Errorf("%s: нет снимка %s (запустите с UPDATE_GOLDEN=1): %v", r.name, path, err)
+		return r
+	}
+
+	if !bytes.Equal(got, canonical(want)) {
+		r.t.Errorf("%s: ответ не совпадает со снимком %s\n--- ожидалось\n%s\n--- получено\n%s", r.name, path, want, got)
+	}
+
+	return r
+}
+
+// canonical: JSON — с отступами и отсортированными ключами (map при Marshal сортируется), остальное — как есть
+func canonical(body []byte) []byte {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return append(bytes.TrimRight(body, "\n"), '\n')
+	}
+
+	out, _ := json.MarshalIndent(v, "", "  ")
+
+	return append(out, '\n')
+}
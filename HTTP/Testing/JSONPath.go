@@ -0,0 +1,75 @@
+package Testing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Путь в JSON — упрощённый вариант JSONPath:
+
+	""  или "$"         — весь документ
+	"name"              — поле объекта
+	"address.city"      — вложенное поле
+	"0.name", "[0].name" — элемент массива по индексу
+	"items[1].id"       — поле, затем индекс
+	"items[-1]"         — последний элемент массива
+
+Ключи с точками или скобками не поддерживаются — для контрактных тестов API этого достаточно.
+*/
+
+func Lookup(doc any, path string) (any, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return doc, nil
+	}
+
+	cur := doc
+	walked := "$"
+
+	for _, seg := range splitPath(path) {
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[seg]
+			if !ok {
+				return nil, fmt.Errorf("%s: нет поля %q", walked, seg)
+			}
+			cur = next
+			walked += "." + seg
+
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil {
+				return nil, fmt.Errorf("%s: массив, а в пути %q", walked, seg)
+			}
+			if i < 0 {
+				i += len(v)
+			}
+			if i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("%s: индекс %s вне массива длины %d", walked, seg, len(v))
+			}
+			cur = v[i]
+			walked += "[" + seg + "]"
+
+		default:
+			return nil, fmt.Errorf("%s: значение %s не содержит %q", walked, compact(cur), seg)
+		}
+	}
+
+	return cur, nil
+}
+
+// splitPath: "items[1].id" -> ["items", "1", "id"]
+func splitPath(path string) []string {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+
+	var out []string
+	for _, seg := range strings.Split(path, ".") {
+		if seg != "" {
+			out = append(out, seg)
+		}
+	}
+
+	return out
+}
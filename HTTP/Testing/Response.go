@@ -0,0 +1,201 @@
+package Testing
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Response — ответ обработчика и цепочка проверок к нему
+type Response struct {
+	t    T
+	name string // "METHOD /path" — префикс для сообщений об ошибках
+
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	doc    any // разобранное тело, лениво
+	parsed bool
+}
+
+func (r *Response) ExpectStatus(code int) *Response {
+	r.t.Helper()
+
+	if r.StatusCode != code {
+		r.t.Errorf("%s: статус %d, ожидался %d; тело: %s", r.name, r.StatusCode, code, truncate(r.Body))
+	}
+
+	return r
+}
+
+// ExpectHeader проверяет заголовок; для Content-Type достаточно совпадения медиатипа без параметров
+func (r *Response) ExpectHeader(key, want string) *Response {
+	r.t.Helper()
+
+	got := r.Header.Get(key)
+	if http.CanonicalHeaderKey(key) == "Content-Type" && !strings.Contains(want, ";") {
+		got, _, _ = strings.Cut(got, ";")
+		got = strings.TrimSpace(got)
+	}
+
+	if got != want {
+		r.t.Errorf("%s: заголовок %s = %q, ожидался %q", r.name, key, got, want)
+	}
+
+	return r
+}
+
+func (r *Response) ExpectBody(want string) *Response {
+	r.t.Helper()
+
+	if got := string(r.Body); got != want {
+		r.t.Errorf("%s: тело %q, ожидалось %q", r.name, got, want)
+	}
+
+	return r
+}
+
+func (r *Response) ExpectBodyContains(substr string) *Response {
+	r.t.Helper()
+
+	if !strings.Contains(string(r.Body), substr) {
+		r.t.Errorf("%s: тело не содержит %q: %s", r.name, substr, truncate(r.Body))
+	}
+
+	return r
+}
+
+// ExpectJSON сравнивает значение по пути (см. Lookup) с want.
+// want приводится к тем же типам, что и после json.Unmarshal, поэтому можно писать ExpectJSON("age", 30)
+func (r *Response) ExpectJSON(path string, want any) *Response {
+	r.t.Helper()
+
+	got, err := Lookup(r.document(), path)
+	if err != nil {
+		r.t.Errorf("%s: %v", r.name, err)
+		return r
+	}
+
+	if normalized := normalize(want); !reflect.DeepEqual(got, normalized) {
+		r.t.Errorf("%s: %s = %s, ожидалось %s", r.name, path, compact(got), compact(normalized))
+	}
+
+	return r
+}
+
+func (r *Response) ExpectJSONExists(path string) *Response {
+	r.t.Helper()
+
+	if _, err := Lookup(r.document(), path); err != nil {
+		r.t.Errorf("%s: %v", r.name, err)
+	}
+
+	return r
+}
+
+func (r *Response) ExpectJSONAbsent(path string) *Response {
+	r.t.Helper()
+
+	if _, err := Lookup(r.document(), path); err == nil {
+		r.t.Errorf("%s: %s не должно быть в ответе", r.name, path)
+	}
+
+	return r
+}
+
+// ExpectJSONLen — длина массива или число ключей объекта по пути
+func (r *Response) ExpectJSONLen(path string, n int) *Response {
+	r.t.Helper()
+
+	got, err := Lookup(r.document(), path)
+	if err != nil {
+		r.t.Errorf("%s: %v", r.name, err)
+		return r
+	}
+
+	var length int
+	switch v := got.(type) {
+	case []any:
+		length = len(v)
+	case map[string]any:
+		length = len(v)
+	default:
+		r.t.Errorf("%s: %s — не массив и не объект: %s", r.name, path, compact(got))
+		return r
+	}
+
+	if length != n {
+		r.t.Errorf("%s: длина %s = %d, ожидалось %d", r.name, path, length, n)
+	}
+
+	return r
+}
+
+// JSON возвращает значение по пути, например, id только что созданной записи для следующих запросов
+func (r *Response) JSON(path string) any {
+	r.t.Helper()
+
+	v, err := Lookup(r.document(), path)
+	if err != nil {
+		r.t.Fatalf("%s: %v", r.name, err)
+	}
+
+	return v
+}
+
+// Decode разбирает тело в v
+func (r *Response) Decode(v any) *Response {
+	r.t.Helper()
+
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("%s: тело не разбирается в %T: %v; тело: %s", r.name, v, err, truncate(r.Body))
+	}
+
+	return r
+}
+
+func (r *Response) document() any {
+	r.t.Helper()
+
+	if !r.parsed {
+		if err := json.Unmarshal(r.Body, &r.doc); err != nil {
+			r.t.Fatalf("%s: тело не JSON: %v; тело: %s", r.name, err, truncate(r.Body))
+		}
+		r.parsed = true
+	}
+
+	return r.doc
+}
+
+// normalize прогоняет значение через JSON, чтобы int стал float64, структура — map[string]any и т.д.
+func normalize(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+
+	var out any
+	json.Unmarshal(data, &out)
+
+	return out
+}
+
+func compact(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "<?>"
+	}
+
+	return string(data)
+}
+
+func truncate(body []byte) string {
+	const limit = 512
+	if len(body) > limit {
+		return string(body[:limit]) + "..."
+	}
+
+	return string(body)
+}
@@ -0,0 +1,263 @@
+package Testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+Сценарий — JSON-файл с последовательностью шагов "запрос -> ожидания", который выполняется против любого http.Handler.
+Шаги идут по порядку и разделяют состояние обработчика, поэтому сценарий может описать весь CRUD: создать, изменить, удалить.
+
+	{
+	  "name": "users CRUD",
+	  "headers": {"Accept": "application/json"},
+	  "steps": [
+	    {
+	      "name": "create",
+	      "request": {"method": "POST", "path": "/api/v1/users", "body": {"name": "New User"}},
+	      "expect": {"status": 201, "json": {"name": "New User"}, "exists": ["id"]},
+	      "save": {"id": "id"}
+	    },
+	    {
+	      "name": "delete",
+	      "request": {"method": "DELETE", "path": "/api/v1/users/{{id}}"},
+	      "expect": {"status": 204}
+	    }
+	  ]
+	}
+
+save запоминает значение по JSON-пути под именем, дальше оно подставляется как {{имя}} в путь, заголовки, тело и ожидания.
+golden в expect — путь к снимку относительно файла сценария.
+
+Несколько сценариев удобно держать в одной папке и запускать RunScenarios(t, h, "testdata/*.json") — это и есть табличный тест.
+*/
+
+type Scenario struct {
+	Name    string            `json:"name"`
+	Headers map[string]string `json:"headers,omitempty"` // общие для всех шагов
+	Steps   []Step            `json:"steps"`
+
+	dir string // каталог файла — от него считаются пути golden
+}
+
+type Step struct {
+	Name    string            `json:"name"`
+	Request StepRequest       `json:"request"`
+	Expect  StepExpect        `json:"expect"`
+	Save    map[string]string `json:"save,omitempty"` // имя переменной -> JSON-путь в ответе
+}
+
+type StepRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`    // JSON как есть
+	RawBody *string           `json:"rawBody,omitempty"` // произвольный текст, например, битый JSON
+}
+
+type StepExpect struct {
+	Status   int               `json:"status"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	JSON     map[string]any    `json:"json,omitempty"`
+	Exists   []string          `json:"exists,omitempty"`
+	Absent   []string          `json:"absent,omitempty"`
+	Len      map[string]int    `json:"len,omitempty"`
+	Body     *string           `json:"body,omitempty"`
+	Contains string            `json:"contains,omitempty"`
+	Golden   string            `json:"golden,omitempty"`
+}
+
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Scenario
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	s.dir = filepath.Dir(path)
+
+	return &s, nil
+}
+
+// RunScenarios выполняет все сценарии, подходящие под шаблон, по алфавиту
+func RunScenarios(t T, h http.Handler, pattern string) {
+	t.Helper()
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatalf("scenarios %s: %v", pattern, err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("scenarios %s: нет файлов", pattern)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		RunScenarioFile(t, h, file)
+	}
+}
+
+func RunScenarioFile(t T, h http.Handler, path string) {
+	t.Helper()
+
+	s, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	s.Run(t, h)
+}
+
+func (s *Scenario) Run(t T, h http.Handler) {
+	t.Helper()
+
+	vars := map[string]string{}
+
+	for i, step := range s.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprint("#", i+1)
+		}
+
+		st := &stepT{T: t, prefix: s.Name + "/" + name + ": "}
+		s.runStep(st, h, step, vars)
+		t.Logf("%s/%s: ok=%v", s.Name, name, !st.failed)
+	}
+}
+
+func (s *Scenario) runStep(t *stepT, h http.Handler, step Step, vars map[string]string) {
+	t.Helper()
+
+	sub := func(v string) string { return substitute(v, vars) }
+
+	req := NewClient(t, h).Request(step.Request.Method, sub(step.Request.Path))
+	for k, v := range s.Headers {
+		req.Header(k, sub(v))
+	}
+
+	switch {
+	case step.Request.RawBody != nil:
+		req.Body(sub(*step.Request.RawBody))
+	case len(step.Request.Body) > 0:
+		req.Body(sub(string(step.Request.Body)))
+		req.Header("Content-Type", "application/json")
+	}
+
+	for k, v := range step.Request.Headers {
+		req.Header(k, sub(v))
+	}
+
+	resp := req.Do()
+	exp := step.Expect
+
+	if exp.Status != 0 {
+		resp.ExpectStatus(exp.Status)
+	}
+	for k, v := range exp.Headers {
+		resp.ExpectHeader(k, sub(v))
+	}
+	if exp.Body != nil {
+		resp.ExpectBody(sub(*exp.Body))
+	}
+	if exp.Contains != "" {
+		resp.ExpectBodyContains(sub(exp.Contains))
+	}
+	for _, path := range sortedKeys(exp.JSON) {
+		resp.ExpectJSON(path, substituteValue(exp.JSON[path], vars))
+	}
+	for _, path := range exp.Exists {
+		resp.ExpectJSONExists(path)
+	}
+	for _, path := range exp.Absent {
+		resp.ExpectJSONAbsent(path)
+	}
+	for _, path := range sortedKeys(exp.Len) {
+		resp.ExpectJSONLen(path, exp.Len[path])
+	}
+	if exp.Golden != "" {
+		resp.matchGoldenFile(filepath.Join(s.dir, exp.Golden))
+	}
+
+	for name, path := range step.Save {
+		v, err := Lookup(resp.document(), path)
+		if err != nil {
+			t.Fatalf("save %s: %v", name, err)
+		}
+		if str, ok := v.(string); ok {
+			vars[name] = str
+		} else {
+			vars[name] = compact(v)
+		}
+	}
+}
+
+// stepT добавляет к сообщениям имя сценария и шага
+type stepT struct {
+	T
+	prefix string
+	failed bool
+}
+
+func (s *stepT) Errorf(format string, args ...any) {
+	s.T.Helper()
+	s.failed = true
+	s.T.Errorf(s.prefix+format, args...)
+}
+
+func (s *stepT) Fatalf(format string, args ...any) {
+	s.T.Helper()
+	s.failed = true
+	s.T.Fatalf(s.prefix+format, args...)
+}
+
+func substitute(s string, vars map[string]string) string {
+	for name, v := range vars {
+		s = strings.ReplaceAll(s, "{{"+name+"}}", v)
+	}
+
+	return s
+}
+
+func substituteValue(v any, vars map[string]string) any {
+	switch x := v.(type) {
+	case string:
+		return substitute(x, vars)
+	case []any:
+		out := make([]any, len(x))
+		for i := range x {
+			out[i] = substituteValue(x[i], vars)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k := range x {
+			out[k] = substituteValue(x[k], vars)
+		}
+		return out
+	}
+
+	return v
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,102 @@
+package Testing
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"learning/HTTP"
+)
+
+// UsersContract — CRUD /api/v1/users из HTTP.RestHandlerExample на построителе запросов.
+// То же самое в виде файла — testdata/users_crud.json
+func UsersContract(t T, h http.Handler) {
+	t.Helper()
+
+	c := NewClient(t, h)
+
+	before := c.Get("/api/v1/users").Do().
+		ExpectStatus(http.StatusOK).
+		ExpectHeader("Content-Type", "application/json").
+		ExpectJSON("0.id", "1").
+		ExpectJSON("0.name", "John Doe")
+
+	created := c.Post("/api/v1/users").JSON(HTTP.User{Name: "New User", Email: "new@example.com"}).Do().
+		ExpectStatus(http.StatusCreated).
+		ExpectJSON("name", "New User").
+		ExpectJSON("email", "new@example.com").
+		ExpectJSONExists("id")
+	id := created.JSON("id").(string)
+
+	c.Get("/api/v1/users").Do().
+		ExpectStatus(http.StatusOK).
+		ExpectJSON("[-1].id", id)
+
+	c.Put("/api/v1/users/"+id).JSON(HTTP.User{Name: "Updated", Email: "updated@example.com"}).Do().
+		ExpectStatus(http.StatusOK).
+		ExpectJSON("id", id). // ID из тела игнорируется, берётся из пути
+		ExpectJSON("name", "Updated")
+
+	c.Put("/api/v1/users/" + id).Body("{not json").Do().
+		ExpectStatus(http.StatusBadRequest)
+
+	c.Delete("/api/v1/users/" + id).Do().
+		ExpectStatus(http.StatusNoContent).
+		ExpectBody("")
+
+	c.Delete("/api/v1/users/" + id).Do().
+		ExpectStatus(http.StatusNotFound)
+
+	c.Put("/api/v1/users/does-not-exist").JSON(HTTP.User{Name: "Ghost"}).Do().
+		ExpectStatus(http.StatusNotFound)
+
+	c.Request(http.MethodPatch, "/api/v1/users").Do().
+		ExpectStatus(http.StatusMethodNotAllowed)
+
+	// После удаления список такой же, как до создания
+	var list []HTTP.User
+	before.Decode(&list)
+	c.Get("/api/v1/users").Do().
+		ExpectStatus(http.StatusOK).
+		ExpectJSONLen("$", len(list))
+}
+
+// Reporter — T для запуска контрактов из обычной программы, без go test
+type Reporter struct {
+	Failed bool
+}
+
+func (r *Reporter) Helper() {}
+
+func (r *Reporter) Errorf(format string, args ...any) {
+	r.Failed = true
+	log.Printf("FAIL: "+format, args...)
+}
+
+func (r *Reporter) Fatalf(format string, args ...any) {
+	r.Failed = true
+	log.Printf("FATAL: "+format, args...)
+	os.Exit(1)
+}
+
+func (r *Reporter) Logf(format string, args ...any) {
+	log.Printf(format, args...)
+}
+
+// ExampleUsersContract прогоняет контракт пользователей обоими способами.
+// В go test то же самое — users_contract_test.go; там путь к testdata считается от каталога пакета, а здесь — от корня модуля
+func ExampleUsersContract() {
+	r := &Reporter{}
+	mux := HTTP.UsersMux()
+
+	UsersContract(r, mux)
+	RunScenarios(r, mux, "HTTP/Testing/testdata/*.json")
+
+	if r.Failed {
+		fmt.Println("контракт /api/v1/users нарушен")
+		return
+	}
+
+	fmt.Println("контракт /api/v1/users выполняется")
+}
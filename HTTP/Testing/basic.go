@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"testing"
 )
 
 /*
@@ -42,19 +41,3 @@ func ExampleServerTest() {
 
 	fmt.Println(resp)
 }
-
-func TestMyHandler(t *testing.T) {
-	// Создание тестового запроса
-	req := httptest.NewRequest("GET", "http://example.com/foo", nil)
-	req.Header.Set("Content-Type", "application/json")
-
-	// Вызов вашего обработчика
-	w := httptest.NewRecorder()
-	//MyHandler(w, req)
-
-	// Проверка ответа
-	resp := w.Result()
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("Expected status 200, got %d", resp.StatusCode)
-	}
-}
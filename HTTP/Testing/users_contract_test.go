@@ -0,0 +1,18 @@
+package Testing
+
+import (
+	"path/filepath"
+	"testing"
+
+	"learning/HTTP"
+)
+
+// Контракт и сценарии — против одного и того же UsersMux; оба возвращают список пользователей к исходному
+
+func TestUsersContract(t *testing.T) {
+	UsersContract(t, HTTP.UsersMux())
+}
+
+func TestUsersScenarios(t *testing.T) {
+	RunScenarios(t, HTTP.UsersMux(), filepath.Join("testdata", "*.json"))
+}
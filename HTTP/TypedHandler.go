@@ -0,0 +1,369 @@
+package HTTP
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+Обобщённый адаптер для хэндлеров
+
+Каждый хэндлер в RestHandlerExample.go повторяет одно и то же: декодировать тело, проверить ошибку, выставить Content-Type, закодировать ответ.
+Плюс json.NewDecoder(r.Body).Decode(...) без ограничений читает тело любого размера и молча пропускает неизвестные поля.
+
+Handle[Req, Resp] берёт это на себя, а бизнес-логика пишется как обычная функция:
+
+	func(ctx context.Context, req Req) (Resp, error)
+
+Привязка полей Req по тегам:
+	path:"id"          — r.PathValue("id") (шаблоны ServeMux вида "PUT /api/v1/users/{id}")
+	query:"limit"      — r.URL.Query(); для слайса берутся все значения параметра
+	header:"X-Trace"   — r.Header.Get(...)
+	json:"..."         — из тела запроса. Поля из path/query/header стоит помечать json:"-", иначе их можно прислать и в теле
+
+Тело ограничено MaxBodyBytes (http.MaxBytesReader), неизвестные поля — ошибка (DisallowUnknownFields).
+
+Валидация:
+	тег validate:"required,min=2,max=100" (как в OOP.UserSerialize) — для строк и слайсов min/max проверяют длину, для чисел — значение;
+	если Req реализует Validator — дополнительно вызывается Validate().
+
+Ответ: JSON со статусом 200, либо StatusCode() если Resp реализует StatusCoder; тип NoContent даёт 204 без тела.
+Ошибка: *APIError отдаётся со своим статусом, любая другая — 500 без подробностей.
+*/
+
+const MaxBodyBytes = 1 << 20
+
+type Validator interface {
+	Validate() error
+}
+
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// NoContent — ответ без тела (204)
+type NoContent struct{}
+
+// APIError — типизированная ошибка, которая уходит клиенту в виде {"error": {...}}
+type APIError struct {
+	Status  int               `json:"-"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+}
+
+func NewAPIError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+var (
+	ErrNotFound = NewAPIError(http.StatusNotFound, "not_found", "resource not found")
+	ErrConflict = NewAPIError(http.StatusConflict, "conflict", "resource already exists")
+)
+
+func Handle[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+
+		if err := Bind(r, &req); err != nil {
+			WriteError(w, err)
+			return
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+
+		if _, ok := any(resp).(NoContent); ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		status := http.StatusOK
+		if sc, ok := any(resp).(StatusCoder); ok {
+			status = sc.StatusCode()
+		}
+
+		WriteJSON(w, status, resp)
+	}
+}
+
+func WriteJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func WriteError(w http.ResponseWriter, err error) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = NewAPIError(http.StatusInternalServerError, "internal", "internal server error")
+	}
+
+	WriteJSON(w, apiErr.Status, map[string]*APIError{"error": apiErr})
+}
+
+// Bind заполняет dst (указатель на структуру) из тела, пути, query и заголовков и запускает валидацию
+func Bind(r *http.Request, dst any) error {
+	if r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0 {
+		if err := decodeBody(r, dst); err != nil {
+			return err
+		}
+	}
+
+	v := reflect.ValueOf(dst).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := map[string]string{}
+	bindFields(r, v, fields)
+	if len(fields) > 0 {
+		return &APIError{Status: http.StatusBadRequest, Code: "invalid_parameters", Message: "invalid request parameters", Fields: fields}
+	}
+
+	validateFields(v, fields)
+	if len(fields) > 0 {
+		return &APIError{Status: http.StatusUnprocessableEntity, Code: "validation_failed", Message: "validation failed", Fields: fields}
+	}
+
+	if vr, ok := dst.(Validator); ok {
+		if err := vr.Validate(); err != nil {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) {
+				return apiErr
+			}
+			return NewAPIError(http.StatusUnprocessableEntity, "validation_failed", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func decodeBody(r *http.Request, dst any) error {
+	if ct := r.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "application/json") {
+		return NewAPIError(http.StatusUnsupportedMediaType, "unsupported_media_type", "expected application/json")
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, MaxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return NewAPIError(http.StatusRequestEntityTooLarge, "body_too_large", fmt.Sprintf("body must not exceed %d bytes", maxErr.Limit))
+		}
+		return NewAPIError(http.StatusBadRequest, "invalid_json", err.Error())
+	}
+
+	// После объекта в теле ничего быть не должно
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return NewAPIError(http.StatusBadRequest, "invalid_json", "body must contain a single JSON object")
+	}
+
+	return nil
+}
+
+func bindFields(r *http.Request, v reflect.Value, errs map[string]string) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+		if sf.Anonymous && fv.Kind() == reflect.Struct {
+			bindFields(r, fv, errs)
+			continue
+		}
+
+		var name string
+		var values []string
+
+		if tag := sf.Tag.Get("path"); tag != "" {
+			name = tag
+			if pv := r.PathValue(tag); pv != "" {
+				values = []string{pv}
+			}
+		} else if tag := sf.Tag.Get("query"); tag != "" {
+			name = tag
+			values = r.URL.Query()[tag]
+		} else if tag := sf.Tag.Get("header"); tag != "" {
+			name = tag
+			values = r.Header.Values(tag)
+		} else {
+			continue
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setFromStrings(fv, values); err != nil {
+			errs[name] = err.Error()
+		}
+	}
+}
+
+func setFromStrings(fv reflect.Value, values []string) error {
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, s := range values {
+			if err := setFromString(slice.Index(i), s); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	return setFromString(fv, values[0])
+}
+
+func setFromString(fv reflect.Value, s string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return errors.New("must be a non-negative integer")
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		fv.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		fv.SetBool(b)
+	case reflect.Pointer:
+		ptr := reflect.New(fv.Type().Elem())
+		if err := setFromString(ptr.Elem(), s); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+
+	return nil
+}
+
+func validateFields(v reflect.Value, errs map[string]string) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+		if sf.Anonymous && fv.Kind() == reflect.Struct {
+			validateFields(fv, errs)
+			continue
+		}
+
+		rules := sf.Tag.Get("validate")
+		if rules == "" {
+			continue
+		}
+
+		if msg := checkRules(fv, rules); msg != "" {
+			errs[fieldName(sf)] = msg
+		}
+	}
+}
+
+func checkRules(fv reflect.Value, rules string) string {
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch name {
+		case "required":
+			if fv.IsZero() {
+				return "is required"
+			}
+		case "min", "max":
+			limit, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+
+			size, isLen := measure(fv)
+			if name == "min" && size < limit {
+				if isLen {
+					return fmt.Sprintf("length must be at least %s", arg)
+				}
+				return fmt.Sprintf("must be at least %s", arg)
+			}
+			if name == "max" && size > limit {
+				if isLen {
+					return fmt.Sprintf("length must be at most %s", arg)
+				}
+				return fmt.Sprintf("must be at most %s", arg)
+			}
+		}
+	}
+
+	return ""
+}
+
+// measure возвращает длину для строк/слайсов/мап и значение для чисел
+func measure(fv reflect.Value) (float64, bool) {
+	switch fv.Kind() {
+	case reflect.String:
+		return float64(len([]rune(fv.String()))), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(fv.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), false
+	}
+
+	return 0, false
+}
+
+// fieldName — имя поля так, как его видит клиент: из json/path/query/header тега
+func fieldName(sf reflect.StructField) string {
+	for _, key := range []string{"json", "path", "query", "header"} {
+		if tag := sf.Tag.Get(key); tag != "" && tag != "-" {
+			name, _, _ := strings.Cut(tag, ",")
+			if name != "" {
+				return name
+			}
+		}
+	}
+
+	return sf.Name
+}
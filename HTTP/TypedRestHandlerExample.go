@@ -0,0 +1,73 @@
+package HTTP
+
+import (
+	"context"
+	"net/http"
+)
+
+// Те же операции над users, что и в RestHandlerExample.go, но через Handle: без ручного декодирования и кодирования
+
+type UserPayload struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Email string `json:"email" validate:"required,max=254"`
+}
+
+type ListUsersRequest struct {
+	Limit int `query:"limit" json:"-" validate:"min=0,max=100"`
+}
+
+type UpdateUserRequest struct {
+	ID string `path:"id" json:"-" validate:"required"`
+	UserPayload
+}
+
+type DeleteUserRequest struct {
+	ID string `path:"id" json:"-" validate:"required"`
+}
+
+// CreatedUser — ответ со статусом 201
+type CreatedUser User
+
+func (CreatedUser) StatusCode() int { return http.StatusCreated }
+
+func ListUsersTyped(_ context.Context, req ListUsersRequest) ([]User, error) {
+	list := AllUsers()
+	if req.Limit > 0 && req.Limit < len(list) {
+		return list[:req.Limit], nil
+	}
+
+	return list, nil
+}
+
+func CreateUserTyped(_ context.Context, req UserPayload) (CreatedUser, error) {
+	return CreatedUser(AddUser(User{Name: req.Name, Email: req.Email})), nil
+}
+
+func UpdateUserTyped(_ context.Context, req UpdateUserRequest) (User, error) {
+	u := User{ID: req.ID, Name: req.Name, Email: req.Email}
+	if !ReplaceUser(u) {
+		return User{}, ErrNotFound
+	}
+
+	return u, nil
+}
+
+func DeleteUserTyped(_ context.Context, req DeleteUserRequest) (NoContent, error) {
+	if !RemoveUser(req.ID) {
+		return NoContent{}, ErrNotFound
+	}
+
+	return NoContent{}, nil
+}
+
+// TypedUsersMux — маршруты с методами и подстановкой {id} (ServeMux с Go 1.22)
+func TypedUsersMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.Handle("GET /api/v1/users", Handle(ListUsersTyped))
+	mux.Handle("POST /api/v1/users", Handle(CreateUserTyped))
+	mux.Handle("PUT /api/v1/users/{id}", Handle(UpdateUserTyped))
+	mux.Handle("DELETE /api/v1/users/{id}", Handle(DeleteUserTyped))
+
+	return mux
+}
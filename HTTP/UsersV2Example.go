@@ -0,0 +1,240 @@
+package HTTP
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"learning/Config"
+)
+
+// Схема пользователя v2: имя разделено на части, адрес вложен (как OOP.Citizien с встроенным Address)
+
+type AddressV2 struct {
+	City   string `json:"city"`
+	Street string `json:"street"`
+}
+
+type UserV2 struct {
+	ID        string     `json:"id"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	Email     string     `json:"email"`
+	Address   *AddressV2 `json:"address"`
+}
+
+type UserV2Payload struct {
+	FirstName string     `json:"first_name" validate:"required,max=100"`
+	LastName  string     `json:"last_name" validate:"max=100"`
+	Email     string     `json:"email" validate:"required,max=254"`
+	Address   *AddressV2 `json:"address"`
+}
+
+type UserV2ID struct {
+	ID string `path:"id" json:"-" validate:"required"`
+}
+
+type UpdateUserV2Request struct {
+	UserV2ID
+	UserV2Payload
+}
+
+type CreatedUserV2 UserV2
+
+func (CreatedUserV2) StatusCode() int { return http.StatusCreated }
+
+// userStoreV2 — хранилище для версионированного API, заполняется из тех же стартовых users
+type userStoreV2 struct {
+	mu     sync.Mutex
+	nextID int
+	items  []UserV2
+}
+
+var usersV2 = newUserStoreV2(users)
+
+func newUserStoreV2(seed []User) *userStoreV2 {
+	s := &userStoreV2{nextID: len(seed) + 1}
+	for _, u := range seed {
+		first, last := splitName(u.Name)
+		s.items = append(s.items, UserV2{ID: u.ID, FirstName: first, LastName: last, Email: u.Email})
+	}
+
+	return s
+}
+
+func (s *userStoreV2) list(context.Context, struct{}) ([]UserV2, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]UserV2(nil), s.items...), nil
+}
+
+func (s *userStoreV2) get(_ context.Context, req UserV2ID) (UserV2, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.items {
+		if u.ID == req.ID {
+			return u, nil
+		}
+	}
+
+	return UserV2{}, ErrNotFound
+}
+
+func (s *userStoreV2) create(_ context.Context, req UserV2Payload) (CreatedUserV2, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := UserV2{ID: fmt.Sprint(s.nextID), FirstName: req.FirstName, LastName: req.LastName, Email: req.Email, Address: req.Address}
+	s.nextID++
+	s.items = append(s.items, u)
+
+	return CreatedUserV2(u), nil
+}
+
+// update заменяет пользователя целиком; PUT от клиента v1 адрес не передаёт (в v1 его нет), поэтому адрес остаётся прежним
+func (s *userStoreV2) update(ctx context.Context, req UpdateUserV2Request) (UserV2, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, u := range s.items {
+		if u.ID == req.ID {
+			address := req.Address
+			if RequestVersion(ctx) == "v1" {
+				address = u.Address
+			}
+			s.items[i] = UserV2{ID: req.ID, FirstName: req.FirstName, LastName: req.LastName, Email: req.Email, Address: address}
+			return s.items[i], nil
+		}
+	}
+
+	return UserV2{}, ErrNotFound
+}
+
+func (s *userStoreV2) delete(_ context.Context, req UserV2ID) (NoContent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, u := range s.items {
+		if u.ID == req.ID {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return NoContent{}, nil
+		}
+	}
+
+	return NoContent{}, ErrNotFound
+}
+
+func splitName(name string) (first, last string) {
+	first, last, _ = strings.Cut(strings.TrimSpace(name), " ")
+	return first, strings.TrimSpace(last)
+}
+
+// userV1ToV2 — запрос v1 {"name", "email"} в v2 {"first_name", "last_name", "email", "address"}.
+// Остальные поля переносятся как есть: неизвестное поле дойдёт до DisallowUnknownFields и вернётся 400, а не пропадёт молча.
+// Поля, которые есть только в v2, в запросе v1 — тоже неизвестные: адрес клиент v1 передать не может
+func userV1ToV2(obj map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(obj)+1)
+	for key, value := range obj {
+		switch key {
+		case "name":
+		case "first_name", "last_name", "address":
+			return nil, fmt.Errorf("json: unknown field %q", key)
+		default:
+			out[key] = value
+		}
+	}
+
+	name, _ := obj["name"].(string)
+	out["first_name"], out["last_name"] = splitName(name)
+
+	return out, nil
+}
+
+// userV2ToV1 — ответ v2 в v1: адрес теряется, имя склеивается
+func userV2ToV1(obj map[string]any) (map[string]any, error) {
+	first, _ := obj["first_name"].(string)
+	last, _ := obj["last_name"].(string)
+
+	return map[string]any{
+		"id":    obj["id"],
+		"name":  strings.TrimSpace(first + " " + last),
+		"email": obj["email"],
+	}, nil
+}
+
+// v1FieldNames — поля v2, которые клиент v1 знает как name
+var v1FieldNames = strings.NewReplacer("first_name", "name", "last_name", "name")
+
+// userErrorV2ToV1 — ошибка v2 в v1: first_name и last_name в error.fields и в тексте ошибки становятся name.
+// Если ошибки есть у обоих полей, сообщения склеиваются через "; "
+func userErrorV2ToV1(obj map[string]any) (map[string]any, error) {
+	apiErr, ok := obj["error"].(map[string]any)
+	if !ok {
+		return obj, nil
+	}
+
+	if message, ok := apiErr["message"].(string); ok {
+		apiErr["message"] = v1FieldNames.Replace(message)
+	}
+
+	fields, ok := apiErr["fields"].(map[string]any)
+	if !ok {
+		return obj, nil
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]any, len(fields))
+	for _, key := range keys {
+		name := v1FieldNames.Replace(key)
+		message, _ := fields[key].(string)
+		if prev, ok := out[name].(string); ok && prev != message {
+			message = prev + "; " + message
+		}
+		out[name] = message
+	}
+	apiErr["fields"] = out
+
+	return obj, nil
+}
+
+// VersionedUsersHandler обслуживает /api/v1/users и /api/v2/users одним набором хэндлеров v2
+func VersionedUsersHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/users", Handle(usersV2.list))
+	mux.Handle("POST /api/users", Handle(usersV2.create))
+	mux.Handle("GET /api/users/{id}", Handle(usersV2.get))
+	mux.Handle("PUT /api/users/{id}", Handle(usersV2.update))
+	mux.Handle("DELETE /api/users/{id}", Handle(usersV2.delete))
+
+	return &VersionRouter{
+		Prefix: "/api",
+		Versions: []APIVersion{
+			{
+				Name:         "v1",
+				DeprecatedAt: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+				Sunset:       time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+				DocsLink:     "/docs/migration-v1-v2",
+				RequestUp:    EachObject(userV1ToV2),
+				ResponseDown: EachObject(userV2ToV1),
+				ErrorDown:    EachObject(userErrorV2ToV1),
+			},
+			{Name: "v2"},
+		},
+		Handler: mux,
+	}
+}
+
+func VersionedServer() {
+	http.ListenAndServe(Config.Current().HTTP.Addr, VersionedUsersHandler())
+}
@@ -0,0 +1,87 @@
+package HTTP
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func put(t *testing.T, url, body string) (int, map[string]any) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var out map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.StatusCode, out
+}
+
+// PUT от клиента v1 меняет имя и почту, но адрес, заданный через v2, не затирает
+func TestV1UpdateKeepsAddress(t *testing.T) {
+	server := httptest.NewServer(VersionedUsersHandler())
+	defer server.Close()
+
+	created, err := usersV2.create(t.Context(), UserV2Payload{FirstName: "Old", Email: "old@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "/users/" + created.ID
+
+	if status, _ := put(t, server.URL+"/api/v2"+url, `{"first_name": "Ivan", "email": "ivan@example.com", "address": {"city": "Moscow", "street": "Tverskaya"}}`); status != http.StatusOK {
+		t.Fatalf("PUT v2: %d", status)
+	}
+	if status, body := put(t, server.URL+"/api/v1"+url, `{"name": "Ivan Petrov", "email": "petrov@example.com"}`); status != http.StatusOK || body["name"] != "Ivan Petrov" {
+		t.Fatalf("PUT v1: %d %v", status, body)
+	}
+
+	u, err := usersV2.get(t.Context(), UserV2ID{ID: created.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.FirstName != "Ivan" || u.LastName != "Petrov" || u.Email != "petrov@example.com" {
+		t.Errorf("после PUT v1: %+v", u)
+	}
+	if u.Address == nil || u.Address.City != "Moscow" {
+		t.Errorf("PUT v1 затёр адрес: %+v", u.Address)
+	}
+}
+
+// Ошибки валидации клиент v1 видит в своих полях: name вместо first_name/last_name
+func TestV1ValidationErrorFields(t *testing.T) {
+	server := httptest.NewServer(VersionedUsersHandler())
+	defer server.Close()
+
+	status, body := put(t, server.URL+"/api/v1/users/1", `{"name": "", "email": "x@example.com"}`)
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("статус %d, ожидался 422", status)
+	}
+
+	apiErr, _ := body["error"].(map[string]any)
+	fields, _ := apiErr["fields"].(map[string]any)
+	if fields["name"] != "is required" || fields["first_name"] != nil {
+		t.Fatalf("поля ошибки v1: %v", fields)
+	}
+
+	// Клиент v2 получает те же ошибки в терминах v2
+	status, body = put(t, server.URL+"/api/v2/users/1", `{"first_name": "", "email": "x@example.com"}`)
+	apiErr, _ = body["error"].(map[string]any)
+	fields, _ = apiErr["fields"].(map[string]any)
+	if status != http.StatusUnprocessableEntity || fields["first_name"] != "is required" {
+		t.Fatalf("v2: %d %v", status, fields)
+	}
+}
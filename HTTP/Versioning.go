@@ -0,0 +1,318 @@
+package HTTP
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Версионирование API
+
+Когда схема ресурса меняется несовместимо (например, name разделили на first_name и last_name), старые клиенты ломаются.
+Вместо того чтобы держать два набора хэндлеров, хэндлер пишется один — под последнюю версию, а между версиями стоят трансформеры:
+	запрос клиента v1 поднимается по цепочке RequestUp: v1 -> v2 -> ... -> последняя;
+	ответ последней версии опускается по цепочке ResponseDown: последняя -> ... -> v1.
+Трансформер работает с уже разобранным JSON (map[string]any / []any), поэтому про конкретные структуры ничего не знает.
+Ошибки (ответы 4xx/5xx) опускаются по своей цепочке ErrorDown: в них схема другая ({"error": {...}}), но имена полей
+в error.fields — из последней версии, и клиенту старой версии их нужно вернуть в его терминах.
+Версию запроса хэндлер узнаёт из RequestVersion(ctx) — например, чтобы PUT от v1 не затирал поля, которых в v1 нет.
+
+Версия выбирается:
+	по префиксу пути: /api/v1/users, /api/v2/users; версия в пути, которой нет в Versions (/api/v3/users), — 406,
+	а не молча последняя: клиент просил конкретную версию, и ответ другой версии он разберёт неправильно;
+	по параметру media type в Accept: Accept: application/json; version=1 (если в пути версии нет);
+	иначе — последняя.
+
+Устаревшие версии помечаются заголовками:
+	Deprecation: @<unix time> (RFC 9745) — с какого момента версия устарела;
+	Sunset: <HTTP-date> (RFC 8594) — когда версию отключат;
+	Link: <...>; rel="deprecation" — где почитать про миграцию.
+*/
+
+type Transform func(v any) (any, error)
+
+type APIVersion struct {
+	Name string // "v1", "v2"
+
+	DeprecatedAt time.Time
+	Sunset       time.Time
+	DocsLink     string
+
+	RequestUp    Transform // из этой версии в следующую
+	ResponseDown Transform // из следующей версии в эту
+	ErrorDown    Transform // тело ошибки из следующей версии в эту
+}
+
+type versionKey struct{}
+
+// RequestVersion — имя версии API, в которой пришёл запрос ("v1"); пустая строка — запрос не проходил через VersionRouter
+func RequestVersion(ctx context.Context) string {
+	name, _ := ctx.Value(versionKey{}).(string)
+	return name
+}
+
+// VersionRouter раздаёт запросы /api/vN/... одному хэндлеру, который обслуживает пути без версии (/api/...)
+type VersionRouter struct {
+	Prefix   string       // "/api"
+	Versions []APIVersion // от старой к новой
+	Handler  http.Handler
+}
+
+func (vr *VersionRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	idx, rest, ok := vr.fromPath(r.URL.Path)
+	if !ok {
+		if vr.hasPathVersion(r.URL.Path) {
+			http.Error(w, "Unsupported API version", http.StatusNotAcceptable)
+			return
+		}
+		idx, ok = vr.fromAccept(r.Header.Get("Accept"))
+		if !ok {
+			http.Error(w, "Unsupported API version", http.StatusNotAcceptable)
+			return
+		}
+		rest = strings.TrimPrefix(r.URL.Path, vr.Prefix)
+	}
+
+	version := vr.Versions[idx]
+	latest := idx == len(vr.Versions)-1
+
+	w.Header().Set("API-Version", version.Name)
+	w.Header().Add("Vary", "Accept")
+	if !version.DeprecatedAt.IsZero() {
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(version.DeprecatedAt.Unix(), 10))
+	}
+	if !version.Sunset.IsZero() {
+		w.Header().Set("Sunset", version.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if version.DocsLink != "" {
+		w.Header().Add("Link", "<"+version.DocsLink+`>; rel="deprecation"`)
+	}
+
+	r2 := r.Clone(context.WithValue(r.Context(), versionKey{}, version.Name))
+	r2.URL.Path = vr.Prefix + rest
+	r2.URL.RawPath = ""
+
+	if latest {
+		vr.Handler.ServeHTTP(w, r2)
+		return
+	}
+
+	if err := vr.upgradeRequest(r2, idx); err != nil {
+		WriteError(w, NewAPIError(http.StatusBadRequest, "invalid_json", err.Error()))
+		return
+	}
+
+	rec := &bufferedResponse{header: http.Header{}}
+	vr.Handler.ServeHTTP(rec, r2)
+
+	body := rec.body.Bytes()
+	if isJSON(rec.header.Get("Content-Type")) && len(body) > 0 && (rec.status() >= 200 && rec.status() < 300 || rec.status() >= 400) {
+		downgraded, err := vr.downgrade(body, idx, rec.status() >= 400)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		body = downgraded
+	}
+
+	for name, values := range rec.header {
+		w.Header()[name] = values
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(rec.status())
+	w.Write(body)
+}
+
+// fromPath ищет /prefix/vN в начале пути
+func (vr *VersionRouter) fromPath(path string) (int, string, bool) {
+	rest, ok := strings.CutPrefix(path, vr.Prefix+"/")
+	if !ok {
+		return 0, "", false
+	}
+
+	name, tail, _ := strings.Cut(rest, "/")
+	for i, v := range vr.Versions {
+		if v.Name == name {
+			if tail != "" {
+				tail = "/" + tail
+			}
+			return i, tail, true
+		}
+	}
+
+	return 0, "", false
+}
+
+// hasPathVersion — путь начинается с /prefix/vN (v и цифры), зарегистрирована такая версия или нет
+func (vr *VersionRouter) hasPathVersion(path string) bool {
+	rest, ok := strings.CutPrefix(path, vr.Prefix+"/")
+	if !ok {
+		return false
+	}
+
+	name, _, _ := strings.Cut(rest, "/")
+	if len(name) < 2 || name[0] != 'v' {
+		return false
+	}
+	for _, c := range name[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fromAccept разбирает Accept: application/json; version=1, без версии — последняя
+func (vr *VersionRouter) fromAccept(accept string) (int, bool) {
+	last := len(vr.Versions) - 1
+	if accept == "" {
+		return last, true
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		requested, ok := params["version"]
+		if !ok {
+			continue
+		}
+		if !strings.HasPrefix(requested, "v") {
+			requested = "v" + requested
+		}
+
+		for i, v := range vr.Versions {
+			if v.Name == requested {
+				return i, true
+			}
+		}
+
+		return 0, false
+	}
+
+	return last, true
+}
+
+func (vr *VersionRouter) upgradeRequest(r *http.Request, idx int) error {
+	if r.Body == nil || r.Body == http.NoBody || !isJSON(r.Header.Get("Content-Type")) {
+		return nil
+	}
+
+	raw, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, MaxBodyBytes))
+	r.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return err
+	}
+
+	for i := idx; i < len(vr.Versions)-1; i++ {
+		if up := vr.Versions[i].RequestUp; up != nil {
+			if v, err = up(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(out))
+	r.ContentLength = int64(len(out))
+
+	return nil
+}
+
+func (vr *VersionRouter) downgrade(body []byte, idx int, isError bool) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, err
+	}
+
+	var err error
+	for i := len(vr.Versions) - 2; i >= idx; i-- {
+		down := vr.Versions[i].ResponseDown
+		if isError {
+			down = vr.Versions[i].ErrorDown
+		}
+		if down != nil {
+			if v, err = down(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return json.Marshal(v)
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// bufferedResponse накапливает ответ хэндлера, чтобы его можно было преобразовать перед отправкой
+type bufferedResponse struct {
+	header http.Header
+	body   bytes.Buffer
+	code   int
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.code == 0 {
+		b.code = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.code == 0 {
+		b.code = code
+	}
+}
+
+func (b *bufferedResponse) status() int {
+	if b.code == 0 {
+		return http.StatusOK
+	}
+	return b.code
+}
+
+// EachObject применяет fn к объекту или к каждому объекту массива — ответы бывают и списками, и одиночными ресурсами
+func EachObject(fn func(map[string]any) (map[string]any, error)) Transform {
+	return func(v any) (any, error) {
+		switch x := v.(type) {
+		case map[string]any:
+			return fn(x)
+		case []any:
+			for i, item := range x {
+				if obj, ok := item.(map[string]any); ok {
+					converted, err := fn(obj)
+					if err != nil {
+						return nil, err
+					}
+					x[i] = converted
+				}
+			}
+			return x, nil
+		}
+
+		return v, nil
+	}
+}
@@ -3,6 +3,8 @@ package HTTP
 import (
 	"fmt"
 	"net/http"
+
+	"learning/Config"
 )
 
 /*
@@ -49,9 +51,9 @@ func ExampleSimpleHttpServer() {
 	http.Handle("/test", hs)
 	//регистрация отбработчика через структуру
 
-	err := http.ListenAndServe(":8383", nil)
+	err := http.ListenAndServe(Config.Current().HTTP.Addr, nil)
 	//Запускает http-server,
-	//первый параметр - порт, который слушается
+	//первый параметр - порт, который слушается (":8383" по умолчанию, см. Config.HTTPConfig)
 	if err != nil {
 		panic(err)
 	}
@@ -35,6 +35,9 @@ import (
 //
 //}
 
+//autocert работает только с публичным доменом. Для локальной разработки сертификаты выпускаем сами от своего CA:
+//пакет learning/Certificates и утилита go run ./tools/certgen (CA, серверные и клиентские сертификаты, mTLS, горячая перезагрузка)
+
 //Пакет crypto/bcrypt
 
 //Адаптивность позволяет устанавливать нужную скорость работы хеш-функции через параметр cost (стоимость).
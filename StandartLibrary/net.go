@@ -59,15 +59,26 @@ func findH1(n *html.Node) *html.Node {
 }
 
 func GetSiteTitle() {
-	url := "https://chr.rbc.ru" // URL сайта
+	title, err := GetSiteTitleWith(http.DefaultClient, "https://chr.rbc.ru") // URL сайта
+	if err != nil {
+		panic(err)
+	}
+
+	if title != "" {
+		fmt.Println("H1:", title)
+	} else {
+		fmt.Println("H1 тег не найден")
+	}
+}
 
+// GetSiteTitleWith — то же самое, но с переданным клиентом: в тестах клиент можно собрать на кассете (HTTP/Cassette) и работать без сети
+func GetSiteTitleWith(client *http.Client, url string) (string, error) {
 	// Отправляем GET-запрос к указанному URL
 	// net/http выполняет весь процесс TCP-соединения, TLS (если HTTPS),
 	// отправку HTTP-запроса и получение ответа
-	resp, err := http.Get(url)
-
+	resp, err := client.Get(url)
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 
 	// Обязательно закрываем тело ответа после использования, чтобы
@@ -79,14 +90,14 @@ func GetSiteTitle() {
 	// Каждый узел — это тег, текст или комментарий
 	doc, err := html.Parse(resp.Body)
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 
 	h1 := findH1(doc)
 	if h1 != nil && h1.FirstChild != nil {
 		// h1.FirstChild.Data содержит текст внутри тега <h1>
-		fmt.Println("H1:", h1.FirstChild.Data)
-	} else {
-		fmt.Println("H1 тег не найден")
+		return h1.FirstChild.Data, nil
 	}
+
+	return "", nil
 }
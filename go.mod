@@ -2,13 +2,17 @@ module learning
 
 go 1.25.0
 
+require learning/DataBase v0.0.0-00010101000000-000000000000
+
 require (
 	github.com/k0kubun/pp v3.0.1+incompatible // indirect
 	github.com/lib/pq v1.10.9 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/mattn/go-isatty v0.0.24 // indirect
 	golang.org/x/crypto v0.42.0 // indirect
 	golang.org/x/exp v0.0.0-20251002181428-27f1f14c8bb9 // indirect
 	golang.org/x/net v0.45.0 // indirect
-	golang.org/x/sys v0.36.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
 )
+
+replace learning/DataBase => ./DataBase
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"learning/Certificates"
+)
+
+/*
+Утилита для локальных сертификатов:
+
+	go run ./tools/certgen ca -dir certs -cn "Learning Local CA"
+	go run ./tools/certgen server -dir certs -name server -hosts localhost,127.0.0.1
+	go run ./tools/certgen client -dir certs -name alice
+
+Файлы: certs/ca.pem, certs/ca-key.pem, certs/<name>.pem, certs/<name>-key.pem
+*/
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	dir := fs.String("dir", "certs", "каталог с сертификатами")
+	cn := fs.String("cn", "Learning Local CA", "CommonName для CA")
+	name := fs.String("name", "", "имя сертификата (CommonName и имя файла)")
+	hosts := fs.String("hosts", "", "SAN через запятую: DNS-имена и IP")
+	validity := fs.Duration("validity", 0, "срок действия")
+	fs.Parse(os.Args[2:])
+
+	switch cmd {
+	case "ca":
+		if *validity == 0 {
+			*validity = 10 * 365 * 24 * time.Hour
+		}
+		ca, err := Certificates.CreateCA(*cn, *validity)
+		exitOnError(err)
+		exitOnError(ca.Save(*dir))
+		fmt.Println("CA сохранён в", *dir)
+
+	case "server", "client":
+		if *name == "" {
+			fmt.Fprintln(os.Stderr, "нужен -name")
+			os.Exit(2)
+		}
+
+		ca, err := Certificates.LoadCA(*dir)
+		exitOnError(err)
+
+		req := Certificates.LeafRequest{CommonName: *name, Validity: *validity, Usage: Certificates.ServerUsage}
+		if cmd == "client" {
+			req.Usage = Certificates.ClientUsage
+		}
+		if *hosts != "" {
+			req.Hosts = strings.Split(*hosts, ",")
+		}
+
+		certPEM, keyPEM, err := ca.Issue(req)
+		exitOnError(err)
+		exitOnError(Certificates.WritePair(*dir, *name, certPEM, keyPEM))
+		fmt.Printf("%s: %s/%s.pem, %s/%s-key.pem\n", cmd, *dir, *name, *dir, *name)
+
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: certgen ca|server|client [-dir certs] [-cn name] [-name name] [-hosts h1,h2] [-validity 2160h]")
+	os.Exit(2)
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}